@@ -0,0 +1,195 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/openacid/errors"
+)
+
+var (
+	// ErrInvalidCompositeKey indicates a key can not be decoded into the
+	// specified fields.
+	ErrInvalidCompositeKey = errors.New("invalid composite key")
+)
+
+// CompositeKey encodes a tuple of fields into an order preserving key, i.e.,
+// the byte order of two keys is the same as the order of the two tuples,
+// compared field by field.
+//
+// Supported field types are:
+//
+//	string, []byte: variable width. 0x00 is escaped as 0x00 0xff and the
+//	                field is terminated with 0x00 0x01.
+//	uint8, uint16, uint32, uint64: fixed width, big endian.
+//	int8, int16, int32, int64: fixed width, big endian with sign bit flipped.
+//
+// "int" and "uint" are not supported since their sizes are platform
+// dependent.
+// An unsupported type causes a panic.
+//
+// Since 0.5.12
+func CompositeKey(fields ...interface{}) string {
+
+	buf := make([]byte, 0, 16*len(fields))
+
+	for _, f := range fields {
+		switch v := f.(type) {
+		case string:
+			buf = appendEscaped(buf, v)
+		case []byte:
+			buf = appendEscaped(buf, string(v))
+		case uint8:
+			buf = append(buf, v)
+		case uint16:
+			buf = appendUint(buf, uint64(v), 2)
+		case uint32:
+			buf = appendUint(buf, uint64(v), 4)
+		case uint64:
+			buf = appendUint(buf, v, 8)
+		case int8:
+			buf = append(buf, uint8(v)^0x80)
+		case int16:
+			buf = appendUint(buf, uint64(uint16(v)^(1<<15)), 2)
+		case int32:
+			buf = appendUint(buf, uint64(uint32(v)^(1<<31)), 4)
+		case int64:
+			buf = appendUint(buf, uint64(v)^(1<<63), 8)
+		default:
+			panic(fmt.Sprintf("unsupported composite key field type: %T", f))
+		}
+	}
+
+	return string(buf)
+}
+
+// DecodeCompositeKey decodes a key built by CompositeKey() into fields.
+// Every element in dsts must be a pointer to the type of the corresponding
+// field, such as *string or *uint32.
+// A []byte field is decoded with a *[]byte.
+//
+// It returns ErrInvalidCompositeKey if key is malformed or does not match the
+// specified fields.
+//
+// Since 0.5.12
+func DecodeCompositeKey(key string, dsts ...interface{}) error {
+
+	b := []byte(key)
+
+	for i, d := range dsts {
+
+		var n int
+		var err error
+
+		switch p := d.(type) {
+		case *string:
+			var s []byte
+			s, n, err = readEscaped(b)
+			*p = string(s)
+		case *[]byte:
+			*p, n, err = readEscaped(b)
+		case *uint8:
+			n, err = checkLen(b, 1)
+			if err == nil {
+				*p = b[0]
+			}
+		case *uint16:
+			n, err = checkLen(b, 2)
+			if err == nil {
+				*p = binary.BigEndian.Uint16(b)
+			}
+		case *uint32:
+			n, err = checkLen(b, 4)
+			if err == nil {
+				*p = binary.BigEndian.Uint32(b)
+			}
+		case *uint64:
+			n, err = checkLen(b, 8)
+			if err == nil {
+				*p = binary.BigEndian.Uint64(b)
+			}
+		case *int8:
+			n, err = checkLen(b, 1)
+			if err == nil {
+				*p = int8(b[0] ^ 0x80)
+			}
+		case *int16:
+			n, err = checkLen(b, 2)
+			if err == nil {
+				*p = int16(binary.BigEndian.Uint16(b) ^ (1 << 15))
+			}
+		case *int32:
+			n, err = checkLen(b, 4)
+			if err == nil {
+				*p = int32(binary.BigEndian.Uint32(b) ^ (1 << 31))
+			}
+		case *int64:
+			n, err = checkLen(b, 8)
+			if err == nil {
+				*p = int64(binary.BigEndian.Uint64(b) ^ (1 << 63))
+			}
+		default:
+			panic(fmt.Sprintf("unsupported composite key field type: %T", d))
+		}
+
+		if err != nil {
+			return errors.Wrapf(ErrInvalidCompositeKey, "%d-th field: %v", i, err)
+		}
+		b = b[n:]
+	}
+
+	if len(b) != 0 {
+		return errors.Wrapf(ErrInvalidCompositeKey, "%d trailing bytes", len(b))
+	}
+
+	return nil
+}
+
+func appendUint(buf []byte, v uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		buf = append(buf, byte(v>>(uint(i)*8)))
+	}
+	return buf
+}
+
+func appendEscaped(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		buf = append(buf, s[i])
+		if s[i] == 0x00 {
+			buf = append(buf, 0xff)
+		}
+	}
+	return append(buf, 0x00, 0x01)
+}
+
+// readEscaped reads an escaped variable width field and returns the unescaped
+// content and the number of bytes consumed.
+func readEscaped(b []byte) ([]byte, int, error) {
+	rst := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != 0x00 {
+			rst = append(rst, b[i])
+			continue
+		}
+		if i+1 == len(b) {
+			return nil, 0, errors.New("incomplete escape")
+		}
+		i++
+		switch b[i] {
+		case 0x01:
+			return rst, i + 1, nil
+		case 0xff:
+			rst = append(rst, 0x00)
+		default:
+			return nil, 0, fmt.Errorf("invalid escape: 0x00 0x%02x", b[i])
+		}
+	}
+	return nil, 0, errors.New("no terminator")
+}
+
+func checkLen(b []byte, size int) (int, error) {
+	if len(b) < size {
+		return 0, fmt.Errorf("expect %d bytes but only %d", size, len(b))
+	}
+	return size, nil
+}
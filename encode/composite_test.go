@@ -0,0 +1,101 @@
+package encode_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeKey(t *testing.T) {
+
+	ta := require.New(t)
+
+	type tuple struct {
+		tenant string
+		user   uint32
+		ts     int64
+	}
+
+	// tuples in order
+	tuples := []tuple{
+		{"", 0, 0},
+		{"", 1, -1},
+		{"a", 0, -10},
+		{"a", 0, 0},
+		{"a", 0, 10},
+		{"a", 256, 0},
+		{"a\x00", 0, 0},
+		{"a\x00\x00", 0, 0},
+		{"a\x00b", 0, 0},
+		{"a\x01", 0, 0},
+		{"ab", 0, -1 << 63},
+		{"ab", 1<<32 - 1, 1<<63 - 1},
+		{"b", 0, 0},
+		{"b\xff", 0, 0},
+	}
+
+	keys := make([]string, len(tuples))
+	for i, tp := range tuples {
+		keys[i] = encode.CompositeKey(tp.tenant, tp.user, tp.ts)
+	}
+
+	ta.True(sort.StringsAreSorted(keys), "keys: %q", keys)
+
+	for i, k := range keys {
+		var tp tuple
+		err := encode.DecodeCompositeKey(k, &tp.tenant, &tp.user, &tp.ts)
+		ta.NoError(err)
+		ta.Equal(tuples[i], tp)
+	}
+
+	t.Run("allTypes", func(t *testing.T) {
+		k := encode.CompositeKey([]byte("x\x00"), uint8(1), uint16(2), uint64(3),
+			int8(-4), int16(-5), int32(-6))
+
+		var (
+			b   []byte
+			u8  uint8
+			u16 uint16
+			u64 uint64
+			i8  int8
+			i16 int16
+			i32 int32
+		)
+		err := encode.DecodeCompositeKey(k, &b, &u8, &u16, &u64, &i8, &i16, &i32)
+		ta.NoError(err)
+		ta.Equal([]byte("x\x00"), b)
+		ta.Equal([]interface{}{uint8(1), uint16(2), uint64(3), int8(-4), int16(-5), int32(-6)},
+			[]interface{}{u8, u16, u64, i8, i16, i32})
+
+		ta.True(encode.CompositeKey(int8(-1)) < encode.CompositeKey(int8(0)))
+		ta.True(encode.CompositeKey(int16(-1)) < encode.CompositeKey(int16(1)))
+		ta.True(encode.CompositeKey(int32(-1<<31)) < encode.CompositeKey(int32(-1)))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var s string
+		var u uint32
+
+		cases := []string{
+			"abc",
+			"abc\x00",
+			"abc\x00\x02",
+			"abc\x00\x01\x00",
+			"abc\x00\x01\x00\x00\x00\x01\x02",
+		}
+		for _, k := range cases {
+			err := encode.DecodeCompositeKey(k, &s, &u)
+			ta.Equal(encode.ErrInvalidCompositeKey, errors.Cause(err), "key: %q", k)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		ta.Panics(func() { encode.CompositeKey(1) })
+
+		var i int
+		ta.Panics(func() { _ = encode.DecodeCompositeKey("", &i) })
+	})
+}
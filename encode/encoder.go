@@ -39,6 +39,18 @@ type Encoder interface {
 	GetEncodedSize([]byte) int
 }
 
+// A DecoderInto is an optional interface an Encoder implements to decode
+// directly into a caller provided value, without allocating a new value and
+// boxing it into an interface{}.
+//
+// Since 0.5.12
+type DecoderInto interface {
+	// DecodeInto reads byte stream and stores the decoded value into dst,
+	// which must be a pointer to the type this encoder deals with.
+	// It returns number bytes consumed.
+	DecodeInto(b []byte, dst interface{}) int
+}
+
 // EncoderOf returns a `Encoder` implementation for type of `e`
 func EncoderOf(e interface{}) (Encoder, error) {
 	k := reflect.ValueOf(e).Kind()
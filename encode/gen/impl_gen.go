@@ -32,6 +32,18 @@ func (c {{.Name}}) Decode(b []byte) (int, interface{}) {
 	return size, d
 }
 
+// DecodeInto converts slice of {{.ValLen}} bytes to {{.ValType}} and stores it in dst,
+// which must be a *{{.ValType}}.
+// It returns number bytes consumed.
+func (c {{.Name}}) DecodeInto(b []byte, dst interface{}) int {
+
+	size := int({{.ValLen}})
+	s := b[:size]
+
+	*dst.(*{{.ValType}}) = {{.ValType}}(binary.LittleEndian.{{.Codec}}(s))
+	return size
+}
+
 // GetSize returns the size in byte after encoding v.
 func (c {{.Name}}) GetSize(d interface{}) int {
 	return {{.ValLen}}
@@ -101,6 +113,17 @@ func Test{{.Name}}(t *testing.T) {
 			t.Fatalf("%d-th: decoded size: input: %v; want: %v; actual: %v",
 				i+1, c.input, c.wantsize, n)
 		}
+
+		var d {{.ValType}}
+		n = m.DecodeInto(rst, &d)
+		if c.input != d {
+			t.Fatalf("%d-th: decode into: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, d)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: decoded into size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
 	}
 }
 `
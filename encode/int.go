@@ -26,6 +26,18 @@ func (c U16) Decode(b []byte) (int, interface{}) {
 	return size, d
 }
 
+// DecodeInto converts slice of 2 bytes to uint16 and stores it in dst,
+// which must be a *uint16.
+// It returns number bytes consumed.
+func (c U16) DecodeInto(b []byte, dst interface{}) int {
+
+	size := int(2)
+	s := b[:size]
+
+	*dst.(*uint16) = binary.LittleEndian.Uint16(s)
+	return size
+}
+
 // GetSize returns the size in byte after encoding v.
 func (c U16) GetSize(d interface{}) int {
 	return 2
@@ -58,6 +70,18 @@ func (c U32) Decode(b []byte) (int, interface{}) {
 	return size, d
 }
 
+// DecodeInto converts slice of 4 bytes to uint32 and stores it in dst,
+// which must be a *uint32.
+// It returns number bytes consumed.
+func (c U32) DecodeInto(b []byte, dst interface{}) int {
+
+	size := int(4)
+	s := b[:size]
+
+	*dst.(*uint32) = binary.LittleEndian.Uint32(s)
+	return size
+}
+
 // GetSize returns the size in byte after encoding v.
 func (c U32) GetSize(d interface{}) int {
 	return 4
@@ -90,6 +114,18 @@ func (c U64) Decode(b []byte) (int, interface{}) {
 	return size, d
 }
 
+// DecodeInto converts slice of 8 bytes to uint64 and stores it in dst,
+// which must be a *uint64.
+// It returns number bytes consumed.
+func (c U64) DecodeInto(b []byte, dst interface{}) int {
+
+	size := int(8)
+	s := b[:size]
+
+	*dst.(*uint64) = binary.LittleEndian.Uint64(s)
+	return size
+}
+
 // GetSize returns the size in byte after encoding v.
 func (c U64) GetSize(d interface{}) int {
 	return 8
@@ -122,6 +158,18 @@ func (c I16) Decode(b []byte) (int, interface{}) {
 	return size, d
 }
 
+// DecodeInto converts slice of 2 bytes to int16 and stores it in dst,
+// which must be a *int16.
+// It returns number bytes consumed.
+func (c I16) DecodeInto(b []byte, dst interface{}) int {
+
+	size := int(2)
+	s := b[:size]
+
+	*dst.(*int16) = int16(binary.LittleEndian.Uint16(s))
+	return size
+}
+
 // GetSize returns the size in byte after encoding v.
 func (c I16) GetSize(d interface{}) int {
 	return 2
@@ -154,6 +202,18 @@ func (c I32) Decode(b []byte) (int, interface{}) {
 	return size, d
 }
 
+// DecodeInto converts slice of 4 bytes to int32 and stores it in dst,
+// which must be a *int32.
+// It returns number bytes consumed.
+func (c I32) DecodeInto(b []byte, dst interface{}) int {
+
+	size := int(4)
+	s := b[:size]
+
+	*dst.(*int32) = int32(binary.LittleEndian.Uint32(s))
+	return size
+}
+
 // GetSize returns the size in byte after encoding v.
 func (c I32) GetSize(d interface{}) int {
 	return 4
@@ -186,6 +246,18 @@ func (c I64) Decode(b []byte) (int, interface{}) {
 	return size, d
 }
 
+// DecodeInto converts slice of 8 bytes to int64 and stores it in dst,
+// which must be a *int64.
+// It returns number bytes consumed.
+func (c I64) DecodeInto(b []byte, dst interface{}) int {
+
+	size := int(8)
+	s := b[:size]
+
+	*dst.(*int64) = int64(binary.LittleEndian.Uint64(s))
+	return size
+}
+
 // GetSize returns the size in byte after encoding v.
 func (c I64) GetSize(d interface{}) int {
 	return 8
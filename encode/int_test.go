@@ -56,6 +56,17 @@ func TestU16(t *testing.T) {
 			t.Fatalf("%d-th: decoded size: input: %v; want: %v; actual: %v",
 				i+1, c.input, c.wantsize, n)
 		}
+
+		var d uint16
+		n = m.DecodeInto(rst, &d)
+		if c.input != d {
+			t.Fatalf("%d-th: decode into: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, d)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: decoded into size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
 	}
 }
 
@@ -107,6 +118,17 @@ func TestU32(t *testing.T) {
 			t.Fatalf("%d-th: decoded size: input: %v; want: %v; actual: %v",
 				i+1, c.input, c.wantsize, n)
 		}
+
+		var d uint32
+		n = m.DecodeInto(rst, &d)
+		if c.input != d {
+			t.Fatalf("%d-th: decode into: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, d)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: decoded into size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
 	}
 }
 
@@ -158,6 +180,17 @@ func TestU64(t *testing.T) {
 			t.Fatalf("%d-th: decoded size: input: %v; want: %v; actual: %v",
 				i+1, c.input, c.wantsize, n)
 		}
+
+		var d uint64
+		n = m.DecodeInto(rst, &d)
+		if c.input != d {
+			t.Fatalf("%d-th: decode into: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, d)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: decoded into size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
 	}
 }
 
@@ -209,6 +242,17 @@ func TestI16(t *testing.T) {
 			t.Fatalf("%d-th: decoded size: input: %v; want: %v; actual: %v",
 				i+1, c.input, c.wantsize, n)
 		}
+
+		var d int16
+		n = m.DecodeInto(rst, &d)
+		if c.input != d {
+			t.Fatalf("%d-th: decode into: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, d)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: decoded into size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
 	}
 }
 
@@ -260,6 +304,17 @@ func TestI32(t *testing.T) {
 			t.Fatalf("%d-th: decoded size: input: %v; want: %v; actual: %v",
 				i+1, c.input, c.wantsize, n)
 		}
+
+		var d int32
+		n = m.DecodeInto(rst, &d)
+		if c.input != d {
+			t.Fatalf("%d-th: decode into: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, d)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: decoded into size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
 	}
 }
 
@@ -311,5 +366,16 @@ func TestI64(t *testing.T) {
 			t.Fatalf("%d-th: decoded size: input: %v; want: %v; actual: %v",
 				i+1, c.input, c.wantsize, n)
 		}
+
+		var d int64
+		n = m.DecodeInto(rst, &d)
+		if c.input != d {
+			t.Fatalf("%d-th: decode into: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, d)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: decoded into size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
 	}
 }
@@ -0,0 +1,44 @@
+package encode
+
+// Uint64Key encodes v into an 8-byte big endian key, so that the byte order
+// of two keys is the same as the numeric order of the two integers.
+// A SlimTrie compares keys byte by byte, thus integer keys must be encoded
+// this way to be correctly ordered, and RangeGet() or ScanRange() works on
+// numeric ranges.
+//
+// It is the same as CompositeKey(v).
+//
+// Since 0.5.12
+func Uint64Key(v uint64) string {
+	return string(appendUint(make([]byte, 0, 8), v, 8))
+}
+
+// Int64Key encodes v into an 8-byte big endian key with the sign bit
+// flipped, so that negative integers are ordered before non-negative ones.
+//
+// It is the same as CompositeKey(v).
+//
+// Since 0.5.12
+func Int64Key(v int64) string {
+	return Uint64Key(uint64(v) ^ (1 << 63))
+}
+
+// DecodeUint64Key decodes a key built by Uint64Key().
+// It returns ErrInvalidCompositeKey if key is not 8 bytes.
+//
+// Since 0.5.12
+func DecodeUint64Key(key string) (uint64, error) {
+	var v uint64
+	err := DecodeCompositeKey(key, &v)
+	return v, err
+}
+
+// DecodeInt64Key decodes a key built by Int64Key().
+// It returns ErrInvalidCompositeKey if key is not 8 bytes.
+//
+// Since 0.5.12
+func DecodeInt64Key(key string) (int64, error) {
+	var v int64
+	err := DecodeCompositeKey(key, &v)
+	return v, err
+}
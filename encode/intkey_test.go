@@ -0,0 +1,58 @@
+package encode_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUint64Key(t *testing.T) {
+
+	ta := require.New(t)
+
+	// in order
+	us := []uint64{0, 1, 255, 256, 1<<32 - 1, 1 << 32, 1<<63 - 1, 1 << 63, 1<<64 - 1}
+
+	keys := make([]string, len(us))
+	for i, u := range us {
+		keys[i] = encode.Uint64Key(u)
+		ta.Equal(8, len(keys[i]))
+		ta.Equal(encode.CompositeKey(u), keys[i])
+
+		got, err := encode.DecodeUint64Key(keys[i])
+		ta.NoError(err)
+		ta.Equal(u, got)
+	}
+	ta.True(sort.StringsAreSorted(keys), "keys: %q", keys)
+
+	_, err := encode.DecodeUint64Key("1234567")
+	ta.Equal(encode.ErrInvalidCompositeKey, errors.Cause(err))
+	_, err = encode.DecodeUint64Key("123456789")
+	ta.Equal(encode.ErrInvalidCompositeKey, errors.Cause(err))
+}
+
+func TestInt64Key(t *testing.T) {
+
+	ta := require.New(t)
+
+	// in order
+	is := []int64{-1 << 63, -1<<32 - 1, -256, -1, 0, 1, 256, 1 << 32, 1<<63 - 1}
+
+	keys := make([]string, len(is))
+	for i, v := range is {
+		keys[i] = encode.Int64Key(v)
+		ta.Equal(8, len(keys[i]))
+		ta.Equal(encode.CompositeKey(v), keys[i])
+
+		got, err := encode.DecodeInt64Key(keys[i])
+		ta.NoError(err)
+		ta.Equal(v, got)
+	}
+	ta.True(sort.StringsAreSorted(keys), "keys: %q", keys)
+
+	_, err := encode.DecodeInt64Key("")
+	ta.Equal(encode.ErrInvalidCompositeKey, errors.Cause(err))
+}
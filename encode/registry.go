@@ -0,0 +1,37 @@
+package encode
+
+import "sync"
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Encoder{}
+)
+
+// Register associates an Encoder with a type tag, such as
+// "github.com/me/pkg.Record".
+//
+// A data structure that records the type tag of its values, e.g. a SlimTrie
+// created with Opt{ValueType: "github.com/me/pkg.Record"}, picks up the
+// registered Encoder automatically when it is loaded.
+//
+// Registering the same tag twice replaces the previous Encoder.
+//
+// Since 0.5.12
+func Register(typeTag string, e Encoder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[typeTag] = e
+}
+
+// Lookup returns the Encoder registered with a type tag, and a bool indicating
+// if it is found.
+//
+// Since 0.5.12
+func Lookup(typeTag string) (Encoder, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	e, ok := registry[typeTag]
+	return e, ok
+}
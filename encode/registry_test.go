@@ -0,0 +1,30 @@
+package encode_test
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+
+	ta := require.New(t)
+
+	e, ok := encode.Lookup("test.registry.I32")
+	ta.False(ok)
+	ta.Nil(e)
+
+	encode.Register("test.registry.I32", encode.I32{})
+
+	e, ok = encode.Lookup("test.registry.I32")
+	ta.True(ok)
+	ta.Equal(encode.I32{}, e)
+
+	// replace
+	encode.Register("test.registry.I32", encode.I16{})
+
+	e, ok = encode.Lookup("test.registry.I32")
+	ta.True(ok)
+	ta.Equal(encode.I16{}, e)
+}
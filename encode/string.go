@@ -0,0 +1,64 @@
+package encode
+
+import "encoding/binary"
+
+// String converts a string of any length to a byte slice and back.
+// The encoded bytes are the length of the string in unsigned varint, followed
+// by the string.
+//
+// The encoded size varies with the string, thus a SlimTrie with String values
+// stores leaves as variable length elements.
+// Unlike String16, which is limited to strings shorter than 64KB, a string of
+// up to 127 bytes costs only 1 byte for its length.
+//
+// Since 0.5.12
+type String struct{}
+
+// Encode converts a string to a byte slice.
+func (s String) Encode(d interface{}) []byte {
+	ss := d.(string)
+	rst := make([]byte, binary.MaxVarintLen64+len(ss))
+	n := binary.PutUvarint(rst, uint64(len(ss)))
+	n += copy(rst[n:], ss)
+	return rst[:n]
+}
+
+// Decode converts a byte slice to a string.
+// It returns number bytes consumed and a string.
+func (s String) Decode(b []byte) (int, interface{}) {
+	l, n := binary.Uvarint(b)
+	end := n + int(l)
+	return end, string(b[n:end])
+}
+
+// DecodeInto converts a byte slice to a string and stores it in dst, which
+// must be a *string.
+// It returns number bytes consumed.
+func (s String) DecodeInto(b []byte, dst interface{}) int {
+	l, n := binary.Uvarint(b)
+	end := n + int(l)
+	*dst.(*string) = string(b[n:end])
+	return end
+}
+
+// GetSize returns number of byte required to encode a string.
+func (s String) GetSize(d interface{}) int {
+	ss := d.(string)
+	return uvarintLen(uint64(len(ss))) + len(ss)
+}
+
+// GetEncodedSize returns size of encoded data.
+func (s String) GetEncodedSize(b []byte) int {
+	l, n := binary.Uvarint(b)
+	return n + int(l)
+}
+
+// uvarintLen returns the number of bytes to encode v as an unsigned varint.
+func uvarintLen(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
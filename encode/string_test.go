@@ -0,0 +1,48 @@
+package encode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		input string
+		want  int
+	}{
+		{"", 1},
+		{"a", 2},
+		{"abc", 4},
+		{strings.Repeat("x", 127), 128},
+		{strings.Repeat("x", 128), 130},
+		{strings.Repeat("x", 5000), 5002},
+		{strings.Repeat("y", 70000), 70003},
+	}
+
+	m := encode.String{}
+
+	for i, c := range cases {
+		rst := m.Encode(c.input)
+		ta.Equal(c.want, len(rst), "%d-th: encoded len", i+1)
+		ta.Equal(c.want, m.GetSize(c.input), "%d-th: size", i+1)
+		ta.Equal(c.want, m.GetEncodedSize(rst), "%d-th: encoded size", i+1)
+
+		// trailing bytes are not consumed
+		buf := append(rst, 'z')
+
+		n, s := m.Decode(buf)
+		ta.Equal(c.want, n, "%d-th: decoded size", i+1)
+		ta.Equal(c.input, s, "%d-th: decoded", i+1)
+
+		var dst string
+		n = m.DecodeInto(buf, &dst)
+		ta.Equal(c.want, n, "%d-th: decoded into size", i+1)
+		ta.Equal(c.input, dst, "%d-th: decoded into", i+1)
+	}
+}
@@ -3,6 +3,7 @@ package encode
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
 	"reflect"
 
 	"github.com/openacid/errors"
@@ -97,6 +98,98 @@ func (m *TypeEncoder) Decode(b []byte) (int, interface{}) {
 	return m.Size, reflect.Indirect(v).Interface()
 }
 
+// DecodeInto converts byte slice to a Type value and stores it in dst, which
+// must be a pointer to a Type value.
+// It returns number bytes consumed.
+//
+// Since 0.5.12
+func (m *TypeEncoder) DecodeInto(b []byte, dst interface{}) int {
+	if reflect.TypeOf(dst) != reflect.PtrTo(m.Type) {
+		panic("dst is not a pointer to TypeEncoder.Type")
+	}
+
+	b = b[0:m.Size]
+	decodeValue(reflect.ValueOf(dst).Elem(), b, m.Endian)
+	return m.Size
+}
+
+// decodeValue decodes b into v in the layout binary.Read() uses, without
+// allocation.
+// Blank fields of a struct are skipped.
+// It returns the number of bytes consumed.
+//
+// Since 0.5.12
+func decodeValue(v reflect.Value, b []byte, endian binary.ByteOrder) int {
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(b[0] != 0)
+		return 1
+	case reflect.Int8:
+		v.SetInt(int64(int8(b[0])))
+		return 1
+	case reflect.Uint8:
+		v.SetUint(uint64(b[0]))
+		return 1
+	case reflect.Int16:
+		v.SetInt(int64(int16(endian.Uint16(b))))
+		return 2
+	case reflect.Uint16:
+		v.SetUint(uint64(endian.Uint16(b)))
+		return 2
+	case reflect.Int32:
+		v.SetInt(int64(int32(endian.Uint32(b))))
+		return 4
+	case reflect.Uint32:
+		v.SetUint(uint64(endian.Uint32(b)))
+		return 4
+	case reflect.Int64:
+		v.SetInt(int64(endian.Uint64(b)))
+		return 8
+	case reflect.Uint64:
+		v.SetUint(endian.Uint64(b))
+		return 8
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(endian.Uint32(b))))
+		return 4
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(endian.Uint64(b)))
+		return 8
+	case reflect.Complex64:
+		v.SetComplex(complex(
+			float64(math.Float32frombits(endian.Uint32(b))),
+			float64(math.Float32frombits(endian.Uint32(b[4:]))),
+		))
+		return 8
+	case reflect.Complex128:
+		v.SetComplex(complex(
+			math.Float64frombits(endian.Uint64(b)),
+			math.Float64frombits(endian.Uint64(b[8:])),
+		))
+		return 16
+	case reflect.Array:
+		n := 0
+		for i := 0; i < v.Len(); i++ {
+			n += decodeValue(v.Index(i), b[n:], endian)
+		}
+		return n
+	case reflect.Struct:
+		t := v.Type()
+		n := 0
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if t.Field(i).Name == "_" {
+				n += binary.Size(reflect.Zero(f.Type()).Interface())
+				continue
+			}
+			n += decodeValue(f, b[n:], endian)
+		}
+		return n
+	}
+
+	panic("not a fixed size type: " + v.Type().String())
+}
+
 // GetSize returns m.Size.
 func (m *TypeEncoder) GetSize(d interface{}) int {
 	return m.Size
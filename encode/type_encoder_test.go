@@ -224,3 +224,89 @@ func TestTypeEncoderDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestTypeEncoderDecodeInto(t *testing.T) {
+
+	m, err := encode.NewTypeEncoder(typeXY{})
+	if err != nil {
+		t.Fatalf("expected no error but: %#v", err)
+	}
+
+	bs := m.Encode(typeXY{1, 2})
+
+	var v typeXY
+	n := m.DecodeInto(bs, &v)
+
+	if n != m.Size {
+		t.Fatalf("expect n to b %d but %d", m.Size, n)
+	}
+
+	if v != (typeXY{1, 2}) {
+		t.Fatalf("want: %#v; actual: %#v", typeXY{1, 2}, v)
+	}
+
+	testPanic(t, func() { m.DecodeInto(bs, v) }, "not a pointer")
+	testPanic(t, func() { m.DecodeInto(bs, new(int32)) }, "different type")
+
+	allocs := testing.AllocsPerRun(100, func() { m.DecodeInto(bs, &v) })
+	if allocs != 0 {
+		t.Fatalf("expect no allocation but %v", allocs)
+	}
+}
+
+func TestTypeEncoderDecodeInto_kinds(t *testing.T) {
+
+	type allKinds struct {
+		B   bool
+		I8  int8
+		U8  uint8
+		I16 int16
+		U16 uint16
+		_   [3]byte
+		I32 int32
+		U32 uint32
+		I64 int64
+		U64 uint64
+		F32 float32
+		F64 float64
+		C64 complex64
+		C   complex128
+		A   [2]typeXY
+	}
+
+	cases := []interface{}{
+		int8(-3),
+		uint16(0xfffe),
+		float64(-1.5),
+		[3]int16{-1, 2, -3},
+		allKinds{
+			B: true, I8: -1, U8: 2, I16: -3, U16: 4, I32: -5, U32: 6,
+			I64: -7, U64: 8, F32: 9.5, F64: -10.5, C64: 11 + 12i, C: -13 - 14i,
+			A: [2]typeXY{{15, -16}, {-17, 18}},
+		},
+	}
+
+	for i, c := range cases {
+		for _, endian := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+
+			m, err := encode.NewTypeEncoderEndian(c, endian)
+			if err != nil {
+				t.Fatalf("%d-th: expected no error but: %#v", i+1, err)
+			}
+
+			bs := m.Encode(c)
+
+			dst := reflect.New(m.Type)
+			n := m.DecodeInto(bs, dst.Interface())
+			if n != m.Size {
+				t.Fatalf("%d-th: expect n to b %d but %d", i+1, m.Size, n)
+			}
+
+			_, want := m.Decode(bs)
+			if !reflect.DeepEqual(want, dst.Elem().Interface()) {
+				t.Fatalf("%d-th: %v: want: %#v; actual: %#v",
+					i+1, endian, want, dst.Elem().Interface())
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package slimbench provides benchmarks of SlimTrie on a user provided key set,
+// to help deciding whether SlimTrie fits a workload.
+//
+// BenchGet(), BenchHas(), BenchRangeGet() and BenchIter() are meant to be
+// called in a user defined benchmark function:
+//
+//     func BenchmarkMyKeys_Get(b *testing.B) {
+//         st, _ := slimbench.Build(myKeys, trie.Opt{})
+//         slimbench.BenchGet(b, st, myKeys)
+//     }
+//
+// Report() runs all of them with every Opt variant and returns a size-vs-speed
+// report, which could be printed with WriteReport().
+package slimbench
+
+import (
+	"io"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/slim/trie"
+	"github.com/openacid/tablewriter"
+)
+
+// Variant is a named Opt to build a SlimTrie with.
+type Variant struct {
+	Name string
+	Opt  trie.Opt
+}
+
+// Variants returns the Opt variations a report compares: the default one,
+// prefix modes and the one without short table.
+//
+// The word size of an inner node, 4-bit or 8-bit, is chosen automatically by
+// SlimTrie for every node, and there is no Opt for it.
+// NoShortTable is the closest knob: it keeps every 4-bit node in its full
+// size.
+func Variants() []Variant {
+	return []Variant{
+		{"default", trie.Opt{}},
+		{"inner-prefix", trie.Opt{InnerPrefix: trie.Bool(true)}},
+		{"leaf-prefix", trie.Opt{LeafPrefix: trie.Bool(true)}},
+		{"complete", trie.Opt{Complete: trie.Bool(true)}},
+		{"no-short-table", trie.Opt{NoShortTable: trie.Bool(true)}},
+	}
+}
+
+// Result is the result of benchmarking one operation on a SlimTrie built with
+// one Variant.
+type Result struct {
+	Variant     string  `tw-title:"variant"`
+	Op          string  `tw-title:"op"`
+	NsPerOp     int64   `tw-title:"ns/op"`
+	AllocsPerOp int64   `tw-title:"allocs/op"`
+	BytesPerKey float64 `tw-title:"bytes/key" tw-fmt:"%.2f"`
+}
+
+// Build creates a SlimTrie of keys with opt, in which the value of the i-th
+// key is int32(i).
+// keys must be sorted and unique.
+// Opt.DedupValue is disabled, thus every key has its own leaf.
+func Build(keys []string, opt trie.Opt) (*trie.SlimTrie, error) {
+
+	values := make([]int32, len(keys))
+	for i := range values {
+		values[i] = int32(i)
+	}
+
+	opt.DedupValue = trie.Bool(false)
+
+	return trie.NewSlimTrie(encode.I32{}, keys, values, opt)
+}
+
+// BytesPerKey returns the memory a SlimTrie costs for every key, including
+// 4-byte values.
+func BytesPerKey(st *trie.SlimTrie, keyCnt int) float64 {
+	if keyCnt == 0 {
+		return 0
+	}
+	return float64(st.MappedBytes()+st.HeapBytes()) / float64(keyCnt)
+}
+
+// BenchGet benchmarks st.Get() with keys in turn.
+func BenchGet(b *testing.B, st *trie.SlimTrie, keys []string) {
+	if len(keys) == 0 {
+		b.Skip("no key")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		st.Get(keys[i%len(keys)])
+	}
+}
+
+// BenchHas benchmarks st.Has() with keys in turn.
+func BenchHas(b *testing.B, st *trie.SlimTrie, keys []string) {
+	if len(keys) == 0 {
+		b.Skip("no key")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		st.Has(keys[i%len(keys)])
+	}
+}
+
+// BenchRangeGet benchmarks st.RangeGet() with keys in turn.
+func BenchRangeGet(b *testing.B, st *trie.SlimTrie, keys []string) {
+	if len(keys) == 0 {
+		b.Skip("no key")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		st.RangeGet(keys[i%len(keys)])
+	}
+}
+
+// BenchIter benchmarks iterating st, one op is one key.
+// It requires a SlimTrie built with Opt{Complete: Bool(true)}, otherwise it
+// is skipped.
+func BenchIter(b *testing.B, st *trie.SlimTrie) {
+
+	opt := st.BuildOptions()
+	if opt.Complete == nil || !*opt.Complete {
+		b.Skip("iterating requires Opt.Complete")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	nxt := st.NewIter("", true, true)
+	for i := 0; i < b.N; i++ {
+		k, _ := nxt()
+		if k == nil {
+			nxt = st.NewIter("", true, true)
+		}
+	}
+}
+
+// Bench builds a SlimTrie of keys with the Opt of v, and benchmarks Get, Has,
+// RangeGet and Iter on it.
+// Iter is benchmarked only if v has Opt.Complete.
+// Every operation runs for about the time specified by the -test.benchtime
+// flag, which is 1 second by default.
+func Bench(keys []string, v Variant) ([]Result, error) {
+
+	st, err := Build(keys, v.Opt)
+	if err != nil {
+		return nil, err
+	}
+
+	bpk := BytesPerKey(st, len(keys))
+
+	ops := []struct {
+		name string
+		fn   func(b *testing.B)
+	}{
+		{"Get", func(b *testing.B) { BenchGet(b, st, keys) }},
+		{"Has", func(b *testing.B) { BenchHas(b, st, keys) }},
+		{"RangeGet", func(b *testing.B) { BenchRangeGet(b, st, keys) }},
+	}
+
+	if v.Opt.Complete != nil && *v.Opt.Complete {
+		ops = append(ops, struct {
+			name string
+			fn   func(b *testing.B)
+		}{"Iter", func(b *testing.B) { BenchIter(b, st) }})
+	}
+
+	rst := make([]Result, 0, len(ops))
+	for _, op := range ops {
+		r := testing.Benchmark(op.fn)
+		rst = append(rst, Result{
+			Variant:     v.Name,
+			Op:          op.name,
+			NsPerOp:     r.NsPerOp(),
+			AllocsPerOp: r.AllocsPerOp(),
+			BytesPerKey: bpk,
+		})
+	}
+
+	return rst, nil
+}
+
+// Report runs Bench() with every variant and returns all results.
+// If no variant is specified, Variants() is used.
+func Report(keys []string, variants ...Variant) ([]Result, error) {
+
+	if len(variants) == 0 {
+		variants = Variants()
+	}
+
+	rst := make([]Result, 0)
+	for _, v := range variants {
+		rs, err := Bench(keys, v)
+		if err != nil {
+			return nil, err
+		}
+		rst = append(rst, rs...)
+	}
+
+	return rst, nil
+}
+
+// WriteReport writes results as a markdown table to w.
+func WriteReport(w io.Writer, results []Result) {
+
+	tb := tablewriter.NewWriter(w)
+	tb.SetAutoFormatHeaders(false)
+	tb.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	tb.SetCenterSeparator("|")
+	tb.SetContent(results)
+	tb.Render()
+}
@@ -0,0 +1,72 @@
+package slimbench
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openacid/slim/benchhelper"
+	"github.com/openacid/slim/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := benchhelper.RandSortedStrings(1000, 10, nil)
+
+	for _, v := range Variants() {
+		st, err := Build(keys, v.Opt)
+		ta.NoError(err, v.Name)
+
+		for i, k := range keys {
+			val, found := st.Get(k)
+			ta.True(found)
+			ta.Equal(int32(i), val)
+		}
+
+		ta.True(BytesPerKey(st, len(keys)) > 4, v.Name)
+	}
+
+	_, err := Build([]string{"b", "a"}, trie.Opt{})
+	ta.Error(err)
+}
+
+func TestReport(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("benchmarking takes several seconds")
+	}
+
+	ta := require.New(t)
+
+	keys := benchhelper.RandSortedStrings(1000, 10, nil)
+
+	rst, err := Report(keys, Variant{"complete", trie.Opt{Complete: trie.Bool(true)}})
+	ta.NoError(err)
+
+	ops := []string{}
+	for _, r := range rst {
+		ops = append(ops, r.Op)
+		ta.Equal("complete", r.Variant)
+		ta.True(r.NsPerOp > 0)
+	}
+	ta.Equal([]string{"Get", "Has", "RangeGet", "Iter"}, ops)
+
+	buf := &bytes.Buffer{}
+	WriteReport(buf, rst)
+	ta.Contains(buf.String(), "bytes/key")
+	ta.Contains(buf.String(), "RangeGet")
+}
+
+func BenchmarkGet(b *testing.B) {
+	keys := benchhelper.RandSortedStrings(10000, 16, nil)
+	st, _ := Build(keys, trie.Opt{})
+	BenchGet(b, st, keys)
+}
+
+func BenchmarkIter(b *testing.B) {
+	keys := benchhelper.RandSortedStrings(10000, 16, nil)
+	st, _ := Build(keys, trie.Opt{Complete: trie.Bool(true)})
+	BenchIter(b, st)
+}
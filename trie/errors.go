@@ -5,9 +5,59 @@ import "errors"
 var (
 
 	// ErrKeyOutOfOrder means keys to create Trie are not ascendingly ordered.
+	// The message of a returned error names the first pair of keys out of
+	// order and their indexes, such as `keys[2] >= keys[3] "c\xff" "c\x00"`.
 	ErrKeyOutOfOrder = errors.New("keys not ascending sorted")
 
 	// ErrIncompatible means it is trying to unmarshal data from an incompatible
 	// version.
 	ErrIncompatible = errors.New("incompatible with marshaled data")
+
+	// ErrSelfCheck means a stored key is not found in a newly created
+	// SlimTrie, with Opt.SelfCheck enabled.
+	ErrSelfCheck = errors.New("self check failed")
+
+	// ErrLengthMismatch means the number of keys and the number of values to
+	// create a SlimTrie differ.
+	ErrLengthMismatch = errors.New("number of keys and values differ")
+
+	// ErrIncomplete means an operation requires complete key information,
+	// which is stored only if SlimTrie is created with
+	// Opt{Complete: Bool(true)}.
+	ErrIncomplete = errors.New("complete keys are not stored")
+
+	// ErrInvalidOffsets means the offsets to locate keys in a buffer are not
+	// ascending or out of the buffer.
+	ErrInvalidOffsets = errors.New("invalid key offsets")
+
+	// ErrCollation means keys disagree with a Collation, or a SlimTrie is
+	// built with a Collation that is not registered.
+	ErrCollation = errors.New("collation mismatch")
+
+	// ErrDuplicateKey means a key to add already exists.
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrCorrupt means the structure of a SlimTrie is invalid, such as a node
+	// pointing to itself as a child, or marshaled data does not match its
+	// checksum.
+	// Loading such data returns an error of this cause, while a query method
+	// that does not return an error reports not found, instead of looping
+	// forever in a corrupted SlimTrie.
+	ErrCorrupt = errors.New("corrupt data")
+
+	// ErrTerminatorInKey means a key contains KeyTerminator, which is not
+	// allowed with Opt.WithTerminator.
+	ErrTerminatorInKey = errors.New("key contains terminator")
+
+	// ErrInvalidSection means a SectionID is not one of the sections of the
+	// split layout.
+	ErrInvalidSection = errors.New("invalid section")
+
+	// ErrInvalidValue means a value can not be encoded by the encoder to
+	// create a SlimTrie, or there is no encoder.
+	ErrInvalidValue = errors.New("invalid value")
+
+	// ErrKeyTooLong means a key to create a SlimTrie is longer than
+	// MaxKeyLen.
+	ErrKeyTooLong = errors.New("key too long")
 )
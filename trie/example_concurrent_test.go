@@ -0,0 +1,38 @@
+package trie
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/openacid/slim/encode"
+)
+
+func ExampleSlimTrie_concurrentGet() {
+
+	keys := []string{"abc", "abcd", "bc", "bcd"}
+	values := []int32{1, 2, 3, 4}
+
+	st, _ := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+
+	// A built SlimTrie is read only, any number of goroutines could query it
+	// without locking.
+	sums := make([]int32, 8)
+
+	var wg sync.WaitGroup
+	for i := range sums {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, k := range keys {
+				v, _ := st.Get(k)
+				sums[i] += v.(int32)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	fmt.Println(sums)
+
+	// Output:
+	// [10 10 10 10 10 10 10 10]
+}
@@ -111,7 +111,30 @@ type VLenArray struct {
 	// Bytes is the content in bytes
 	//
 	// Since 0.5.10
-	Bytes                []byte   `protobuf:"bytes,30,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	Bytes []byte `protobuf:"bytes,30,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	// BlockSize is the number of present elts packed in a block, if it is
+	// not 0.
+	// In this case PositionBM is nil and a block in Bytes is a header of
+	// sizes of elts followed by the content of elts.
+	//
+	// Since 0.5.12
+	BlockSize int32 `protobuf:"varint,40,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	// BlockOffsets is the starting position in Bytes of every block.
+	//
+	// Since 0.5.12
+	BlockOffsets []uint32 `protobuf:"varint,41,rep,packed,name=BlockOffsets,proto3" json:"BlockOffsets,omitempty"`
+	// ExceptionBM set 1 at the i-th bit if the i-th present elt is not of
+	// FixedSize, if it is not nil.
+	// In this case elts in Bytes are in order and only elts of other sizes,
+	// the exceptions, have their sizes recorded in ExceptionOffsets.
+	//
+	// Since 0.5.12
+	ExceptionBM *Bitmap `protobuf:"bytes,42,opt,name=ExceptionBM,proto3" json:"ExceptionBM,omitempty"`
+	// ExceptionOffsets is the total size of exceptions before every
+	// exception, and the total size of all exceptions as the last one.
+	//
+	// Since 0.5.12
+	ExceptionOffsets     []uint32 `protobuf:"varint,43,rep,packed,name=ExceptionOffsets,proto3" json:"ExceptionOffsets,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -183,6 +206,34 @@ func (m *VLenArray) GetBytes() []byte {
 	return nil
 }
 
+func (m *VLenArray) GetBlockSize() int32 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+func (m *VLenArray) GetBlockOffsets() []uint32 {
+	if m != nil {
+		return m.BlockOffsets
+	}
+	return nil
+}
+
+func (m *VLenArray) GetExceptionBM() *Bitmap {
+	if m != nil {
+		return m.ExceptionBM
+	}
+	return nil
+}
+
+func (m *VLenArray) GetExceptionOffsets() []uint32 {
+	if m != nil {
+		return m.ExceptionOffsets
+	}
+	return nil
+}
+
 // Slim is the internal structure of slim trie and other slim data structure.
 // It is NOT a public type and do not rely on it.
 // Since protobuf just makes all message public.
@@ -254,10 +305,113 @@ type Slim struct {
 	// Leaves stores serialized leaf values.
 	//
 	// Since 0.5.10
-	Leaves               *VLenArray `protobuf:"bytes,60,opt,name=Leaves,proto3" json:"Leaves,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	Leaves *VLenArray `protobuf:"bytes,60,opt,name=Leaves,proto3" json:"Leaves,omitempty"`
+	// ValueType is an optional user defined tag of the type of values, such
+	// as "github.com/me/pkg.Record".
+	// It is used to find out a registered encode.Encoder when loading.
+	//
+	// Since 0.5.12
+	ValueType string `protobuf:"bytes,70,opt,name=ValueType,proto3" json:"ValueType,omitempty"`
+	// HasBuildOpt indicates the Opt* fields below are the effective options
+	// the SlimTrie is built with.
+	//
+	// Since 0.5.12
+	HasBuildOpt bool `protobuf:"varint,80,opt,name=HasBuildOpt,proto3" json:"HasBuildOpt,omitempty"`
+	// OptDedupValue is Opt.DedupValue when building.
+	//
+	// Since 0.5.12
+	OptDedupValue bool `protobuf:"varint,81,opt,name=OptDedupValue,proto3" json:"OptDedupValue,omitempty"`
+	// OptInnerPrefix is Opt.InnerPrefix when building.
+	//
+	// Since 0.5.12
+	OptInnerPrefix bool `protobuf:"varint,82,opt,name=OptInnerPrefix,proto3" json:"OptInnerPrefix,omitempty"`
+	// OptLeafPrefix is Opt.LeafPrefix when building.
+	//
+	// Since 0.5.12
+	OptLeafPrefix bool `protobuf:"varint,83,opt,name=OptLeafPrefix,proto3" json:"OptLeafPrefix,omitempty"`
+	// OptComplete is Opt.Complete when building.
+	//
+	// Since 0.5.12
+	OptComplete bool `protobuf:"varint,84,opt,name=OptComplete,proto3" json:"OptComplete,omitempty"`
+	// OptSelfCheck is Opt.SelfCheck when building.
+	//
+	// Since 0.5.12
+	OptSelfCheck bool `protobuf:"varint,85,opt,name=OptSelfCheck,proto3" json:"OptSelfCheck,omitempty"`
+	// OptNoShortTable is Opt.NoShortTable when building.
+	//
+	// Since 0.5.12
+	OptNoShortTable bool `protobuf:"varint,86,opt,name=OptNoShortTable,proto3" json:"OptNoShortTable,omitempty"`
+	// OptLeafBlockSize is Opt.LeafBlockSize when building.
+	//
+	// Since 0.5.12
+	OptLeafBlockSize int32 `protobuf:"varint,87,opt,name=OptLeafBlockSize,proto3" json:"OptLeafBlockSize,omitempty"`
+	// OptWithFoldedIndex is Opt.WithFoldedIndex when building.
+	//
+	// Since 0.5.12
+	OptWithFoldedIndex bool `protobuf:"varint,88,opt,name=OptWithFoldedIndex,proto3" json:"OptWithFoldedIndex,omitempty"`
+	// Folded is a companion SlimTrie of ASCII case-folded keys, in which a leaf
+	// value is the index of the leaf of the original key, as an int32.
+	// It is built only with Opt.WithFoldedIndex.
+	//
+	// Since 0.5.12
+	Folded *Slim `protobuf:"bytes,90,opt,name=Folded,proto3" json:"Folded,omitempty"`
+	// Collation is the name of the Collation keys are ordered by.
+	// It is "" if keys are in byte order.
+	//
+	// Since 0.5.12
+	Collation string `protobuf:"bytes,91,opt,name=Collation,proto3" json:"Collation,omitempty"`
+	// BuiltAt is the time in unix nano second the SlimTrie is built, if
+	// Opt.WithBuildTime is set. Otherwise it is 0.
+	//
+	// Since 0.5.12
+	BuiltAt int64 `protobuf:"varint,92,opt,name=BuiltAt,proto3" json:"BuiltAt,omitempty"`
+	// OptWithTerminator is Opt.WithTerminator when building.
+	// Every stored key ends with a KeyTerminator if it is true.
+	//
+	// Since 0.5.12
+	OptWithTerminator bool `protobuf:"varint,93,opt,name=OptWithTerminator,proto3" json:"OptWithTerminator,omitempty"`
+	// OptLeafExceptions is Opt.LeafExceptions when building.
+	//
+	// Since 0.5.12
+	OptLeafExceptions bool `protobuf:"varint,94,opt,name=OptLeafExceptions,proto3" json:"OptLeafExceptions,omitempty"`
+	// HasKeyLen indicates all keys are of the same length KeyLen.
+	//
+	// Since 0.5.12
+	HasKeyLen bool `protobuf:"varint,95,opt,name=HasKeyLen,proto3" json:"HasKeyLen,omitempty"`
+	// KeyLen is the length of every key, if HasKeyLen is true.
+	//
+	// Since 0.5.12
+	KeyLen int32 `protobuf:"varint,96,opt,name=KeyLen,proto3" json:"KeyLen,omitempty"`
+	// OptCheckKeyLen is Opt.CheckKeyLen when building.
+	//
+	// Since 0.5.12
+	OptCheckKeyLen bool `protobuf:"varint,97,opt,name=OptCheckKeyLen,proto3" json:"OptCheckKeyLen,omitempty"`
+	// DeletedBM set 1 at the i-th bit if the leaf of node id i is deleted by
+	// SlimTrie.Delete().
+	//
+	// Since 0.5.12
+	DeletedBM *Bitmap `protobuf:"bytes,98,opt,name=DeletedBM,proto3" json:"DeletedBM,omitempty"`
+	// OptBigThreshold is Opt.BigThreshold when building.
+	//
+	// Since 0.5.12
+	OptBigThreshold int32 `protobuf:"varint,99,opt,name=OptBigThreshold,proto3" json:"OptBigThreshold,omitempty"`
+	// OptWithChecksum is Opt.WithChecksum when building.
+	// Marshal() appends a checksum footer if it is true.
+	//
+	// Since 0.5.12
+	OptWithChecksum bool `protobuf:"varint,100,opt,name=OptWithChecksum,proto3" json:"OptWithChecksum,omitempty"`
+	// OptNoInnerPrefix is Opt.NoInnerPrefix when building.
+	//
+	// Since 0.5.12
+	OptNoInnerPrefix bool `protobuf:"varint,101,opt,name=OptNoInnerPrefix,proto3" json:"OptNoInnerPrefix,omitempty"`
+	// KeyNormalizer is the name of the KeyNormalizer keys are normalized by.
+	// It is "" if keys are not normalized.
+	//
+	// Since 0.5.12
+	KeyNormalizer        string   `protobuf:"bytes,102,opt,name=KeyNormalizer,proto3" json:"KeyNormalizer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Slim) Reset()         { *m = Slim{} }
@@ -347,6 +501,167 @@ func (m *Slim) GetLeaves() *VLenArray {
 	return nil
 }
 
+func (m *Slim) GetValueType() string {
+	if m != nil {
+		return m.ValueType
+	}
+	return ""
+}
+
+func (m *Slim) GetHasBuildOpt() bool {
+	if m != nil {
+		return m.HasBuildOpt
+	}
+	return false
+}
+
+func (m *Slim) GetOptDedupValue() bool {
+	if m != nil {
+		return m.OptDedupValue
+	}
+	return false
+}
+
+func (m *Slim) GetOptInnerPrefix() bool {
+	if m != nil {
+		return m.OptInnerPrefix
+	}
+	return false
+}
+
+func (m *Slim) GetOptLeafPrefix() bool {
+	if m != nil {
+		return m.OptLeafPrefix
+	}
+	return false
+}
+
+func (m *Slim) GetOptComplete() bool {
+	if m != nil {
+		return m.OptComplete
+	}
+	return false
+}
+
+func (m *Slim) GetOptSelfCheck() bool {
+	if m != nil {
+		return m.OptSelfCheck
+	}
+	return false
+}
+
+func (m *Slim) GetOptNoShortTable() bool {
+	if m != nil {
+		return m.OptNoShortTable
+	}
+	return false
+}
+
+func (m *Slim) GetOptLeafBlockSize() int32 {
+	if m != nil {
+		return m.OptLeafBlockSize
+	}
+	return 0
+}
+
+func (m *Slim) GetOptWithFoldedIndex() bool {
+	if m != nil {
+		return m.OptWithFoldedIndex
+	}
+	return false
+}
+
+func (m *Slim) GetFolded() *Slim {
+	if m != nil {
+		return m.Folded
+	}
+	return nil
+}
+
+func (m *Slim) GetCollation() string {
+	if m != nil {
+		return m.Collation
+	}
+	return ""
+}
+
+func (m *Slim) GetBuiltAt() int64 {
+	if m != nil {
+		return m.BuiltAt
+	}
+	return 0
+}
+
+func (m *Slim) GetOptWithTerminator() bool {
+	if m != nil {
+		return m.OptWithTerminator
+	}
+	return false
+}
+
+func (m *Slim) GetOptLeafExceptions() bool {
+	if m != nil {
+		return m.OptLeafExceptions
+	}
+	return false
+}
+
+func (m *Slim) GetHasKeyLen() bool {
+	if m != nil {
+		return m.HasKeyLen
+	}
+	return false
+}
+
+func (m *Slim) GetKeyLen() int32 {
+	if m != nil {
+		return m.KeyLen
+	}
+	return 0
+}
+
+func (m *Slim) GetOptCheckKeyLen() bool {
+	if m != nil {
+		return m.OptCheckKeyLen
+	}
+	return false
+}
+
+func (m *Slim) GetDeletedBM() *Bitmap {
+	if m != nil {
+		return m.DeletedBM
+	}
+	return nil
+}
+
+func (m *Slim) GetOptBigThreshold() int32 {
+	if m != nil {
+		return m.OptBigThreshold
+	}
+	return 0
+}
+
+func (m *Slim) GetOptWithChecksum() bool {
+	if m != nil {
+		return m.OptWithChecksum
+	}
+	return false
+}
+
+func (m *Slim) GetOptNoInnerPrefix() bool {
+	if m != nil {
+		return m.OptNoInnerPrefix
+	}
+	return false
+}
+
+func (m *Slim) GetKeyNormalizer() string {
+	if m != nil {
+		return m.KeyNormalizer
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Bitmap)(nil), "Bitmap")
 	proto.RegisterType((*VLenArray)(nil), "VLenArray")
@@ -356,30 +671,54 @@ func init() {
 func init() { proto.RegisterFile("slim.proto", fileDescriptor_slim_a15a3a1219580880) }
 
 var fileDescriptor_slim_a15a3a1219580880 = []byte{
-	// 388 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0x41, 0xaf, 0x93, 0x40,
-	0x14, 0x85, 0x83, 0xa5, 0xbc, 0xbe, 0x5b, 0x78, 0x92, 0x49, 0xa3, 0xb3, 0x30, 0xed, 0xc8, 0x42,
-	0x59, 0x11, 0xa3, 0x3b, 0xa3, 0x0b, 0x31, 0x9a, 0xb4, 0x69, 0x9b, 0x66, 0x68, 0x34, 0x71, 0x61,
-	0x42, 0xcb, 0xad, 0x4e, 0xa4, 0x43, 0x33, 0x4c, 0x4c, 0xeb, 0x1f, 0xf3, 0x47, 0xf9, 0x27, 0x0c,
-	0x43, 0x05, 0x6a, 0xdf, 0x8e, 0xfb, 0x9d, 0x73, 0x0f, 0xe7, 0x06, 0x00, 0xca, 0x5c, 0xec, 0xa3,
-	0x83, 0x2a, 0x74, 0x11, 0x7c, 0x05, 0x27, 0x16, 0x7a, 0x9f, 0x1e, 0xc8, 0x08, 0xfa, 0x9f, 0x0b,
-	0x95, 0x95, 0x74, 0xc4, 0x7a, 0xa1, 0xcd, 0xeb, 0x81, 0x3c, 0x81, 0x5b, 0x9e, 0xca, 0x1f, 0x53,
-	0x99, 0xe1, 0x91, 0x8e, 0x59, 0x2f, 0xec, 0xf3, 0x16, 0x10, 0x06, 0xc3, 0x04, 0x73, 0xdc, 0xea,
-	0x5a, 0x0f, 0x8d, 0xde, 0x45, 0xc1, 0x6f, 0x0b, 0x6e, 0x3f, 0xcd, 0x51, 0xbe, 0x53, 0x2a, 0x3d,
-	0x11, 0x17, 0xac, 0x25, 0x05, 0x66, 0x85, 0x7d, 0x6e, 0x2d, 0xc9, 0x23, 0x70, 0x3e, 0xe4, 0xfa,
-	0xbd, 0xd4, 0x74, 0x68, 0xd0, 0x79, 0x22, 0xcf, 0x01, 0x56, 0x0a, 0x4b, 0x94, 0x5b, 0x8c, 0x17,
-	0xf4, 0x2d, 0xb3, 0xc2, 0xe1, 0xcb, 0x9b, 0xa8, 0xae, 0xc9, 0x3b, 0x92, 0x31, 0x16, 0xa5, 0xd0,
-	0xa2, 0x90, 0xf1, 0x82, 0x8e, 0xfe, 0x37, 0x36, 0x52, 0x75, 0xc5, 0x47, 0x71, 0xc4, 0x2c, 0x11,
-	0xbf, 0x90, 0x3e, 0x36, 0x2f, 0x6b, 0x41, 0x75, 0x79, 0x7c, 0xd2, 0x58, 0xd2, 0x31, 0xb3, 0x42,
-	0x97, 0xd7, 0x43, 0xf0, 0xe7, 0x01, 0xd8, 0x49, 0x2e, 0xf6, 0xd5, 0x91, 0xb1, 0xf8, 0x36, 0x95,
-	0x12, 0x55, 0xdb, 0xb5, 0x8b, 0xaa, 0xf8, 0xe4, 0x7b, 0xa1, 0xb4, 0x89, 0xbf, 0xab, 0xe3, 0x1b,
-	0x50, 0xb5, 0x5c, 0x16, 0x19, 0xae, 0x4f, 0x07, 0xbc, 0xa7, 0x65, 0x2b, 0x91, 0x09, 0x38, 0x26,
-	0xb2, 0x2e, 0xd2, 0x31, 0x9d, 0x31, 0x79, 0x0a, 0x37, 0x26, 0x36, 0x5e, 0xd0, 0xc9, 0xa5, 0xe3,
-	0x1f, 0x27, 0x63, 0x00, 0xf3, 0xb8, 0x4e, 0x37, 0x39, 0x52, 0xc6, 0x7a, 0xa1, 0xc7, 0x3b, 0x84,
-	0xbc, 0x00, 0xcf, 0x84, 0xad, 0x14, 0xee, 0xc4, 0x11, 0x4b, 0xfa, 0xcc, 0x04, 0x41, 0xd4, 0x7c,
-	0x24, 0x7e, 0x69, 0x20, 0x11, 0xb8, 0x73, 0x4c, 0x77, 0xcd, 0xc2, 0xeb, 0xab, 0x85, 0x0b, 0x9d,
-	0x04, 0xe0, 0xcc, 0x31, 0xfd, 0x89, 0x25, 0x7d, 0x73, 0xe5, 0x3c, 0x2b, 0x33, 0x7b, 0xe0, 0xfa,
-	0xde, 0xcc, 0x1e, 0x78, 0xfe, 0xdd, 0xcc, 0x1e, 0x3c, 0xf4, 0xfd, 0xd8, 0xf9, 0x62, 0x6b, 0x25,
-	0x70, 0xe3, 0x98, 0xdf, 0xf2, 0xd5, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x00, 0x30, 0xd4, 0xc4,
-	0xa4, 0x02, 0x00, 0x00,
+	// 769 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x95, 0x5b, 0x6f, 0xd3, 0x4a,
+	0x10, 0xc7, 0xe5, 0x93, 0x4b, 0x93, 0x49, 0xd2, 0xe6, 0xac, 0xaa, 0x73, 0xf6, 0x01, 0x5a, 0x13,
+	0x41, 0x71, 0x0b, 0x8a, 0x10, 0xbc, 0x21, 0x78, 0xa8, 0xd3, 0x56, 0xbd, 0x24, 0x71, 0x71, 0x42,
+	0x8b, 0x0a, 0x14, 0xdc, 0x78, 0xd2, 0x58, 0x75, 0xbc, 0x96, 0xbd, 0x41, 0x49, 0xbf, 0x21, 0x6f,
+	0x7c, 0x24, 0xb4, 0x6b, 0xd7, 0x97, 0xa4, 0x6f, 0x99, 0xdf, 0xfc, 0x77, 0x76, 0x6e, 0xeb, 0x00,
+	0x84, 0xae, 0x33, 0x6d, 0xfb, 0x01, 0xe3, 0xac, 0x75, 0x0d, 0x65, 0xdd, 0xe1, 0x53, 0xcb, 0x27,
+	0x9b, 0x50, 0xba, 0x64, 0x81, 0x1d, 0xd2, 0x4d, 0xb5, 0xa0, 0x15, 0xcd, 0xc8, 0x20, 0x4f, 0xa0,
+	0x6a, 0x5a, 0xde, 0xdd, 0x89, 0x67, 0xe3, 0x9c, 0x6e, 0xa9, 0x05, 0xad, 0x64, 0xa6, 0x80, 0xa8,
+	0x50, 0x1b, 0xa0, 0x8b, 0x23, 0x1e, 0xf9, 0x35, 0xe9, 0xcf, 0xa2, 0xd6, 0x9f, 0x7f, 0xa0, 0x7a,
+	0xd1, 0x45, 0x6f, 0x3f, 0x08, 0xac, 0x05, 0xa9, 0x83, 0xd2, 0xa7, 0xa0, 0x2a, 0x5a, 0xc9, 0x54,
+	0xfa, 0xe4, 0x3f, 0x28, 0x1f, 0xba, 0xbc, 0xe3, 0x71, 0x5a, 0x93, 0x28, 0xb6, 0xc8, 0x4b, 0x80,
+	0xf3, 0x00, 0x43, 0xf4, 0x46, 0xa8, 0xf7, 0xe8, 0x47, 0x55, 0xd1, 0x6a, 0x6f, 0xd7, 0xda, 0x51,
+	0x9a, 0x66, 0xc6, 0x25, 0x85, 0x2c, 0x74, 0xb8, 0xc3, 0x3c, 0xbd, 0x47, 0x37, 0x97, 0x85, 0x89,
+	0x4b, 0x54, 0x71, 0xe4, 0xcc, 0xd1, 0x1e, 0x38, 0xf7, 0x48, 0xff, 0x97, 0x97, 0xa5, 0x40, 0x54,
+	0xae, 0x2f, 0x38, 0x86, 0x74, 0x4b, 0x55, 0xb4, 0xba, 0x19, 0x19, 0xe2, 0x8c, 0xee, 0xb2, 0xd1,
+	0x9d, 0x3c, 0xa3, 0x45, 0x67, 0x12, 0x40, 0x5a, 0x50, 0x97, 0x86, 0x31, 0x1e, 0x87, 0xc8, 0x43,
+	0xba, 0xab, 0x16, 0xb4, 0x86, 0x99, 0x63, 0x64, 0x17, 0x6a, 0x87, 0xf3, 0x11, 0xfa, 0x71, 0x7e,
+	0x7b, 0xf9, 0xfc, 0xb2, 0x3e, 0xb2, 0x07, 0xcd, 0xc4, 0x7c, 0x08, 0xf9, 0x4a, 0x86, 0x5c, 0xe1,
+	0xad, 0xdf, 0x55, 0x28, 0x0e, 0x5c, 0x67, 0x2a, 0xba, 0xaf, 0x3b, 0xb7, 0x27, 0x9e, 0x87, 0x41,
+	0xda, 0xc4, 0x2c, 0x12, 0x35, 0x0c, 0x26, 0x2c, 0xe0, 0xb2, 0x86, 0xf5, 0xa8, 0x86, 0x04, 0x88,
+	0xf6, 0xf5, 0x99, 0x8d, 0xc3, 0x85, 0x8f, 0x8f, 0xb4, 0x2f, 0x75, 0x91, 0x6d, 0x28, 0xcb, 0x90,
+	0x51, 0x87, 0x32, 0xa2, 0x18, 0x93, 0x67, 0xb0, 0x26, 0xc3, 0xea, 0x3d, 0xba, 0x9d, 0x57, 0x3c,
+	0x70, 0xb2, 0x05, 0x20, 0x7f, 0x0e, 0xad, 0x1b, 0x17, 0xa9, 0x2a, 0x6b, 0xcb, 0x10, 0xf2, 0x06,
+	0x1a, 0x32, 0xd8, 0x79, 0x80, 0x63, 0x67, 0x8e, 0x21, 0xdd, 0x91, 0x81, 0xa0, 0x9d, 0x6c, 0x8f,
+	0x99, 0x17, 0x90, 0x36, 0xd4, 0xbb, 0x68, 0x8d, 0x93, 0x03, 0xef, 0x57, 0x0e, 0xe4, 0xfc, 0xa4,
+	0x05, 0xe5, 0x2e, 0x5a, 0xbf, 0x30, 0xa4, 0x1f, 0x56, 0x94, 0xb1, 0x47, 0x34, 0xec, 0xc2, 0x72,
+	0x67, 0xb2, 0x70, 0x7a, 0xa4, 0x2a, 0x5a, 0xd5, 0x4c, 0x81, 0x68, 0xf8, 0xb1, 0x15, 0xea, 0x33,
+	0xc7, 0xb5, 0x0d, 0x9f, 0xd3, 0x73, 0x55, 0xd1, 0x2a, 0x66, 0x16, 0x91, 0xe7, 0xd0, 0x30, 0x7c,
+	0x7e, 0x80, 0xf6, 0xcc, 0x97, 0xc7, 0xe8, 0x27, 0xa9, 0xc9, 0x43, 0xb2, 0x03, 0xeb, 0x86, 0xcf,
+	0x33, 0xd5, 0x50, 0x53, 0xca, 0x96, 0x68, 0x1c, 0x2d, 0x2d, 0x82, 0x0e, 0x92, 0x68, 0x29, 0x14,
+	0x59, 0x19, 0x3e, 0xef, 0xb0, 0xa9, 0xef, 0x22, 0x47, 0x3a, 0x8c, 0xb2, 0xca, 0x20, 0xb1, 0xac,
+	0x86, 0xcf, 0x07, 0xe8, 0x8e, 0x3b, 0x13, 0x1c, 0xdd, 0xd1, 0xcf, 0x52, 0x92, 0x63, 0x44, 0x83,
+	0x0d, 0xc3, 0xe7, 0x7d, 0x96, 0x19, 0xd2, 0x85, 0x94, 0x2d, 0x63, 0xb1, 0xab, 0x71, 0x02, 0xe9,
+	0xfb, 0xb8, 0x94, 0xbb, 0xb5, 0xc2, 0x49, 0x1b, 0x88, 0xe1, 0xf3, 0x4b, 0x87, 0x4f, 0x8e, 0x98,
+	0x6b, 0xa3, 0x1d, 0x7d, 0x27, 0xbe, 0xc8, 0xc0, 0x8f, 0x78, 0xc8, 0x53, 0x28, 0x47, 0x26, 0xbd,
+	0x92, 0x33, 0x2a, 0xb5, 0xc5, 0xa6, 0x9b, 0x31, 0x14, 0xe3, 0xe9, 0x30, 0xd7, 0xb5, 0xc4, 0x73,
+	0xa0, 0x5f, 0xa3, 0xf1, 0x24, 0x80, 0x50, 0x58, 0x13, 0x83, 0xe0, 0xfb, 0x9c, 0x7e, 0x53, 0x15,
+	0xad, 0x60, 0x3e, 0x98, 0xe4, 0x35, 0xfc, 0x1b, 0x5f, 0x36, 0xc4, 0x60, 0xea, 0x78, 0x16, 0x67,
+	0x01, 0xfd, 0x2e, 0xb3, 0x58, 0x75, 0xc4, 0x6a, 0x51, 0x48, 0xf2, 0xf6, 0x42, 0x7a, 0x9d, 0xa8,
+	0xf3, 0x0e, 0x91, 0xd3, 0xb1, 0x15, 0x9e, 0xe1, 0xa2, 0x8b, 0x1e, 0xfd, 0x21, 0x55, 0x29, 0x10,
+	0xdf, 0xb8, 0xd8, 0xf5, 0x33, 0xfa, 0xc6, 0xc5, 0x3c, 0x5a, 0x01, 0xd9, 0xfa, 0xd8, 0x6f, 0x25,
+	0x2b, 0x90, 0xa1, 0xe4, 0x05, 0x54, 0x0f, 0x50, 0x0c, 0xd1, 0xd6, 0x7b, 0xf4, 0x26, 0xff, 0xb6,
+	0x52, 0x4f, 0x3c, 0x3d, 0xdd, 0xb9, 0x1d, 0x4e, 0x02, 0x0c, 0x27, 0xcc, 0xb5, 0xe9, 0x48, 0xde,
+	0xb7, 0x8c, 0x63, 0xa5, 0xa8, 0x58, 0x5e, 0x13, 0xce, 0xa6, 0xd4, 0x4e, 0xe6, 0x9c, 0xc5, 0xf1,
+	0x9c, 0xfb, 0x2c, 0xbb, 0xa7, 0x28, 0xa5, 0x2b, 0x5c, 0x6c, 0xea, 0x19, 0x2e, 0xfa, 0x2c, 0x98,
+	0x5a, 0xae, 0x73, 0x8f, 0x01, 0x1d, 0xcb, 0xe1, 0xe4, 0xe1, 0x69, 0xb1, 0x52, 0x6f, 0x36, 0x4e,
+	0x8b, 0x95, 0x46, 0x73, 0xfd, 0xb4, 0x58, 0xd9, 0x68, 0x36, 0xf5, 0xf2, 0x55, 0x91, 0x07, 0x0e,
+	0xde, 0x94, 0xe5, 0xbf, 0xd1, 0xbb, 0xbf, 0x03, 0x00, 0xeb, 0xd2, 0xf9, 0x11, 0x9b, 0x06, 0x00,
+	0x00,
 }
@@ -38,6 +38,15 @@ const (
 	// MaxNodeCnt is the max number of node. Node id in SlimTrie is int32.
 	MaxNodeCnt = (1 << 31) - 1
 
+	// MaxKeyLen is the max length in byte of a key SlimTrie stores.
+	// The position of a bit in a key is int32, thus 8*len(key) must not
+	// overflow int32.
+	// A stored key includes the terminator added by Opt.WithTerminator, and
+	// it is the sort key if Opt.Collation or Opt.KeyNormalizer is used.
+	//
+	// Since 0.5.12
+	MaxKeyLen = (1 << 28) - 1
+
 	// minPrefix is the minimal prefix to create.
 	// If a sub set keys have common prefix but prefix length is smaller than
 	// minPrefix, it creates an inner node instead of a step.
@@ -51,6 +60,9 @@ const (
 	bigWordSize  = int32(8)
 	bigInnerSize = int32(1)<<uint(bigWordSize) + 1
 
+	// defaultBigThreshold is the default Opt.BigThreshold.
+	defaultBigThreshold = int32(10)
+
 	// maxShortSize is the max bits a short node can have.
 	// The number of bits of short node is decided during creating.
 	maxShortSize = int32(10)
@@ -72,12 +84,57 @@ const (
 //
 // `Children` stores node branches and children position.
 //
+// A zero value SlimTrie, such as `&SlimTrie{}` that is neither created nor
+// unmarshaled, is safe to query and finds nothing, as an empty SlimTrie does.
+//
+// A SlimTrie is read only after it is created or unmarshaled: query methods,
+// such as Get(), Search(), RangeGet(), scanning and Marshal(), do not modify
+// shared state and are safe to call from any number of goroutines without
+// locking.
+// Leaves of a SlimTrie opened with OpenSplit() are loaded only once, by
+// whichever goroutine accesses them first.
+// Methods that modify a SlimTrie, such as Unmarshal(), Reset(),
+// SetValueFunc(), BuildRangeIndex(), Delete() and Set(), must not be called
+// concurrently with any other method.
+//
 // Since 0.2.0
 type SlimTrie struct {
 	inner   *Slim
 	vars    *slimVars
 	levels  []levelInfo
 	encoder encode.Encoder
+
+	// lazyLeaves loads Leaves when they are accessed for the first time.
+	// It is nil if Leaves are in inner.
+	lazyLeaves *lazyLeaves
+
+	// folded is the companion SlimTrie of the case-folded index.
+	// It is nil if it is not built with Opt.WithFoldedIndex.
+	folded *SlimTrie
+
+	// collation converts query keys to sort keys.
+	// It is nil if keys are in byte order.
+	collation Collation
+
+	// valueFunc computes a value from the ordinal of a leaf.
+	// It is a pointer so that a SlimTrie without it has no func field, which
+	// reflect based tools such as size.Of() do not support.
+	valueFunc *valueFunc
+
+	// rangeIndex is the key ordinal range of every subtree.
+	// It is nil if it is not built with BuildRangeIndex().
+	rangeIndex *rangeIndex
+}
+
+type valueFunc struct {
+	fn func(leafOrdinal int32) interface{}
+}
+
+func newValueFunc(fn func(leafOrdinal int32) interface{}) *valueFunc {
+	if fn == nil {
+		return nil
+	}
+	return &valueFunc{fn: fn}
 }
 
 // Opt specifies options for creating a SlimTrie.
@@ -133,11 +190,227 @@ type Opt struct {
 	// This option implies "InnerPrefix" and "LeafPrefix".
 	// With this option there is no false positive and SlimTrie works just like
 	// a static key-value map.
+	// SlimTrie.ExactGet() relies on it to verify a key.
 	//
 	// Default false.
 	//
 	// Since 0.5.10
 	Complete *bool
+
+	// ValueType is a user defined tag of the type of values, such as
+	// "github.com/me/pkg.Record".
+	// It is stored in the marshaled data and can be retrieved with
+	// SlimTrie.ValueType().
+	//
+	// If the encoder passed to NewSlimTrie() is nil, or a SlimTrie is loaded
+	// without an encoder, the encoder registered with this tag by
+	// encode.Register() is used.
+	//
+	// Default "".
+	//
+	// Since 0.5.12
+	ValueType string
+
+	// SelfCheck tells NewSlimTrie() to query every stored key after building
+	// and ensure it is found and points to the right value.
+	// If any check fails, NewSlimTrie() returns an error ErrSelfCheck.
+	//
+	// It costs about the same time as a Get() on every key thus it is
+	// disabled by default.
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	SelfCheck *bool
+
+	// LeafBlockSize specifies the number of leaves to pack into a shared
+	// block, if values are not of the same size.
+	// With blocks, a leaf is located by one offset per block, instead of bits
+	// in a position bitmap.
+	// It reduces space if values are small, and costs a longer time to
+	// retrieve a value, since it has to skip other leaves in a block.
+	//
+	// Values of the same size never need a position bitmap and are not
+	// affected.
+	//
+	// Default 0: do not pack.
+	//
+	// Since 0.5.12
+	LeafBlockSize int32
+
+	// NoShortTable tells SlimTrie not to replace most used inner node
+	// bitmaps with shorter ones.
+	// It makes building faster and costs more space.
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	NoShortTable *bool
+
+	// WithFoldedIndex tells SlimTrie to build a companion index of ASCII
+	// case-folded keys, in which 'A' to 'Z' are converted to 'a' to 'z'.
+	// It points to the same leaves as the exact keys do, and is queried with
+	// SlimTrie.GetFolded().
+	// The folded index is marshaled together with the SlimTrie.
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	WithFoldedIndex *bool
+
+	// Collation specifies a custom order of keys.
+	// Keys passed to NewSlimTrie() must be strictly ascending by
+	// Collation.Compare(), and query keys are converted by
+	// Collation.SortKey().
+	// The name of it is stored, and a SlimTrie built with it can be loaded
+	// only if it is registered with RegisterCollation().
+	//
+	// Default nil: keys are in byte order.
+	//
+	// Since 0.5.12
+	Collation Collation
+
+	// KeyNormalizer converts keys to a normal form, such as case-folded keys,
+	// both when building and in every query.
+	// Keys passed to NewSlimTrie() must be strictly ascending after
+	// normalizing, thus two keys of the same normal form are rejected.
+	// Keys rebuilt by scanning methods are the normalized keys.
+	// With Collation, normalized keys are ordered by Collation.
+	// The name of it is stored, and a SlimTrie built with it can be loaded
+	// only if it is registered with RegisterKeyNormalizer().
+	//
+	// Default nil: keys are not normalized.
+	//
+	// Since 0.5.12
+	KeyNormalizer KeyNormalizer
+
+	// ValueFunc computes the value of a key from its leaf ordinal: the index
+	// of it in the ascending keys, i.e., the i-th key passed to NewSlimTrie()
+	// has ordinal i.
+	// With ValueFunc, values passed to NewSlimTrie() are not stored, and
+	// Get(), Search() and RangeGet() return the value computed by ValueFunc.
+	// Methods that read raw leaf bytes, such as GetInto() and scanning, see
+	// no value.
+	//
+	// A function can not be marshaled.
+	// Use SlimTrie.SetValueFunc() to supply it after loading.
+	//
+	// Default nil.
+	//
+	// Since 0.5.12
+	ValueFunc func(leafOrdinal int32) interface{}
+
+	// WithBuildTime tells SlimTrie to store the time it is built, which is
+	// returned by SlimTrie.BuiltAt().
+	// It makes the marshaled data differ every time it is built, while the
+	// build time is not written by SlimTrie.MarshalCanonical().
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	WithBuildTime *bool
+
+	// WithTerminator tells SlimTrie to append a KeyTerminator to every key
+	// when building and to every query key, so that no stored key is a
+	// prefix of another.
+	// Keys must not contain KeyTerminator, otherwise NewSlimTrie() returns an
+	// ErrTerminatorInKey error.
+	//
+	// Without it, a key that is a prefix of others is separated from them by
+	// an empty label, and the part of a key after it could be a false
+	// positive, such as Get("ab") finds "abc" if "ab" is not stored.
+	// With it, every key is separated at the terminator, at the cost of one
+	// more byte per key.
+	//
+	// Since keys are prefix free, Route() and PathValues() find only the key
+	// itself.
+	// Scanning yields keys without the terminator.
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	WithTerminator *bool
+
+	// LeafExceptions tells SlimTrie to store leaves of the most common value
+	// size in fixed width, if values are not of the same size, and to record
+	// the size of only the other leaves, the exceptions.
+	// It is meant for values mostly of the same size with a few outliers,
+	// such as 8-byte values mixed with a few large blobs: a leaf is located
+	// almost as fast as in a fixed size layout, and no position bitmap is
+	// built for all leaves.
+	// It overrides LeafBlockSize.
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	LeafExceptions *bool
+
+	// CheckKeyLen tells SlimTrie to reject a query key without a traversal,
+	// if all keys are of the same length and the query key is of another
+	// length, such as for keys that are all 32-byte hashes.
+	// It applies to Get(), GetID() and other methods that look up exactly a
+	// key.
+	// It has no effect if keys are of different lengths.
+	// With KeyNormalizer or Collation, the length of a key is that in the
+	// normalized or collated form.
+	// See SlimTrie.ExpectedKeyLen().
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	CheckKeyLen *bool
+
+	// BigThreshold controls how many inner nodes are big: a big inner node
+	// branches by 8-bit labels with a 257-bit bitmap, while a normal inner
+	// node branches by 4-bit labels with a 17-bit bitmap.
+	//
+	// Inner nodes are created in breadth-first order from the root.
+	// A node is created big if its keys branch into more than BigThreshold
+	// distinct bytes, and once a node is not big, no following node is big.
+	//
+	// A smaller BigThreshold creates more big nodes, for keys with a high
+	// fan-out at every byte, such as random bytes: a big node consumes a byte
+	// of the key in one node instead of two levels of normal nodes, thus a
+	// query visits fewer nodes, while a sparse big node costs much more space.
+	// A negative BigThreshold creates no big node, for the least space.
+	//
+	// Default 0: 10.
+	//
+	// Since 0.5.12
+	BigThreshold int32
+
+	// WithChecksum tells SlimTrie to append a CRC32C checksum footer of the
+	// marshaled data to the output of Marshal().
+	// Unmarshal() verifies the footer if it is present, and returns an
+	// ErrCorrupt error if data is damaged, instead of loading a SlimTrie that
+	// returns wrong values or panics.
+	// Readers without checksum support ignore the footer.
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	WithChecksum *bool
+
+	// NoInnerPrefix tells SlimTrie not to create an inner node with a prefix:
+	// if keys share more than one word after a label, an inner node is
+	// created for every shared word, each with a single label, instead of one
+	// inner node with the shared words as its prefix.
+	// Neither the text nor the length of a prefix is stored, thus
+	// InnerPrefixes is always empty, no matter what InnerPrefix is.
+	//
+	// It is meant for bounding the memory of untrusted keys: the space of
+	// prefixes grows with the length of shared parts of keys, which is up to
+	// the key set, while an inner node costs a fixed size bitmap.
+	// The trade-off is a deeper trie with more inner nodes: a query visits a
+	// node for every shared word, and a long shared part costs more than as a
+	// prefix, e.g., 17 bits or a short bitmap for every 4 bits of it.
+	// There is no more false positive than with InnerPrefix since every bit of
+	// a shared part is stored as a label.
+	//
+	// Default false.
+	//
+	// Since 0.5.12
+	NoInnerPrefix *bool
 }
 
 func Bool(v bool) *bool {
@@ -154,10 +427,41 @@ func normalizeOpt(o *Opt) *Opt {
 	if o.LeafPrefix == nil {
 		o.LeafPrefix = Bool(false)
 	}
+	if o.SelfCheck == nil {
+		o.SelfCheck = Bool(false)
+	}
+	if o.NoShortTable == nil {
+		o.NoShortTable = Bool(false)
+	}
+	if o.WithFoldedIndex == nil {
+		o.WithFoldedIndex = Bool(false)
+	}
+	if o.WithBuildTime == nil {
+		o.WithBuildTime = Bool(false)
+	}
+	if o.WithTerminator == nil {
+		o.WithTerminator = Bool(false)
+	}
+	if o.LeafExceptions == nil {
+		o.LeafExceptions = Bool(false)
+	}
+	if o.CheckKeyLen == nil {
+		o.CheckKeyLen = Bool(false)
+	}
+	if o.WithChecksum == nil {
+		o.WithChecksum = Bool(false)
+	}
+	if o.NoInnerPrefix == nil {
+		o.NoInnerPrefix = Bool(false)
+	}
 	if o.Complete != nil && *o.Complete == true {
 		o.InnerPrefix = Bool(true)
 		o.LeafPrefix = Bool(true)
 	}
+	if n, _ := splitNormalizer(o.Collation); n == nil {
+		// keys are normalized wherever they are collated.
+		o.Collation = withNormalizer(o.KeyNormalizer, o.Collation)
+	}
 	return o
 }
 
@@ -169,6 +473,18 @@ func (st *SlimTrie) GetVersion() string {
 	return slimtrieVersion
 }
 
+// FormatVersion returns the version of the format Marshal() writes.
+//
+// It is always the latest format, no matter what version the SlimTrie is
+// loaded from: data of a legacy version is converted to the latest in-memory
+// structure by Unmarshal(), thus a Marshal() after Unmarshal() upgrades the
+// data to the latest format.
+//
+// Since 0.5.12
+func (st *SlimTrie) FormatVersion() string {
+	return st.GetVersion()
+}
+
 func (st *SlimTrie) compatibleVersions() []string {
 	return []string{
 		"==1.0.0", // before 0.5.8 it is "1.0.0" for historical reason.
@@ -176,6 +492,7 @@ func (st *SlimTrie) compatibleVersions() []string {
 		"==0.5.9",
 		"==0.5.10",
 		"==0.5.11",
+		"==0.5.12",
 		"==" + slimtrieVersion,
 	}
 }
@@ -188,8 +505,18 @@ func (st *SlimTrie) compatibleVersions() []string {
 //	   int is not of fixed size.
 //	   struct { X int64; Y int32; } hax fixed size.
 //
+// A nil element in values, e.g., in a []interface{}, marks a key without
+// value: the key is stored but its leaf is absent.
+// Get() returns nil and true for such a key.
+//
 // Since 0.2.0
 func NewSlimTrie(e encode.Encoder, keys []string, values interface{}, opts ...Opt) (*SlimTrie, error) {
+	return newSlimTrie(e, keys, values, opts, nil)
+}
+
+// newSlimTrie creates a SlimTrie the same as NewSlimTrie() does, and fills
+// report with the statistics of creating if it is not nil.
+func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opts []Opt, report *BuildReport) (*SlimTrie, error) {
 
 	opt := Opt{}
 
@@ -199,6 +526,10 @@ func NewSlimTrie(e encode.Encoder, keys []string, values interface{}, opts ...Op
 
 	normalizeOpt(&opt)
 
+	if e == nil && opt.ValueType != "" {
+		e, _ = encode.Lookup(opt.ValueType)
+	}
+
 	n := len(keys)
 	must.Be.OK(func() {
 		rvals := reflect.ValueOf(values)
@@ -213,21 +544,99 @@ func NewSlimTrie(e encode.Encoder, keys []string, values interface{}, opts ...Op
 		}
 	})
 
+	if opt.ValueFunc != nil {
+		values = nil
+	}
+
 	vals := encodeValues(n, values, e)
 
-	ns, err := newSlim(keys, vals, &opt)
+	sortKeys := keys
+	if opt.Collation != nil {
+		var err error
+		sortKeys, err = collateKeys(opt.Collation, keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// key length is checked in the normalized or collated form, without the
+	// terminator.
+	collated := sortKeys
+
+	if *opt.WithTerminator {
+		var err error
+		sortKeys, err = terminateKeys(sortKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ns, err := newSlim(sortKeys, vals, &opt, report)
 	if err != nil {
 		return nil, err
 	}
+	err = buildFolded(ns, sortKeys, &opt)
+	if err != nil {
+		return nil, err
+	}
+	recordOpt(ns, &opt)
+	recordKeyLen(ns, collated)
 
 	st := &SlimTrie{
-		inner:   ns,
-		encoder: e,
+		inner:     ns,
+		encoder:   e,
+		collation: opt.Collation,
+		valueFunc: newValueFunc(opt.ValueFunc),
+	}
+	err = st.init()
+	if err != nil {
+		return nil, err
+	}
+
+	if *opt.SelfCheck {
+		err := st.selfCheck(keys, vals, &opt)
+		if err != nil {
+			return nil, err
+		}
 	}
-	st.init()
 	return st, nil
 }
 
+// ValueType returns the user defined tag of the type of values, which is
+// specified by Opt.ValueType when creating it.
+// It returns "" if no tag is specified.
+//
+// Since 0.5.12
+func (st *SlimTrie) ValueType() string {
+	return st.inner.GetValueType()
+}
+
+// Encoder returns the encoder to encode and decode values.
+// It is the one passed to NewSlimTrie() or SetEncoder(), or the one
+// registered with ValueType() if none is passed.
+// It returns nil if there is no encoder.
+//
+// Since 0.5.12
+func (st *SlimTrie) Encoder() encode.Encoder {
+	return st.encoder
+}
+
+// SetEncoder replaces the encoder to encode and decode values.
+// The encoder must be the same as, or encode values the same way as, the one
+// used to create the SlimTrie, otherwise values are decoded incorrectly.
+//
+// To load data of a version before 0.5.12, which does not record the size of
+// a value, SetEncoder() must be called before Unmarshal(), since the size is
+// decided by the encoder when loading.
+// Calling it after loading only changes how values are decoded.
+// A SlimTrie loaded with an encoder already set does not look up the encoder
+// registered with ValueType().
+//
+// Since 0.5.12
+func (st *SlimTrie) SetEncoder(e encode.Encoder) {
+	st.encoder = e
+}
+
 // func (st *SlimTrie) GetStat() map[string]float64 {
 //     return st.inner.Stat
 // }
@@ -246,7 +655,15 @@ func (st *SlimTrie) content() []string {
 	return rst
 }
 
-func (st *SlimTrie) init() {
+// init builds the data derived from st.inner for querying.
+// It returns an ErrCorrupt error if st.inner is found invalid.
+func (st *SlimTrie) init() error {
+	st.rangeIndex = nil
 	st.initVars()
-	st.initLevels()
+
+	err := st.initLevels()
+	if err != nil {
+		return err
+	}
+	return st.initFolded()
 }
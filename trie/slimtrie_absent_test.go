@@ -0,0 +1,88 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_absentValue(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := []interface{}{int32(0), nil, int32(2), nil, nil, int32(5), int32(6), nil}
+
+	// Keys with the same value as the previous one are removed by
+	// Opt.DedupValue, including keys without value.
+	for _, opt := range []Opt{
+		{DedupValue: Bool(false)},
+		{DedupValue: Bool(false), Complete: Bool(true)},
+		{DedupValue: Bool(false), LeafBlockSize: 2},
+	} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+
+		for _, s := range []*SlimTrie{st, st2} {
+			for i, k := range keys {
+				ta.True(s.Has(k))
+
+				v, found := s.Get(k)
+				ta.True(found, "key: %q", k)
+				ta.Equal(values[i], v, "key: %q", k)
+
+				i32, found := s.GetI32(k)
+				ta.True(found, "key: %q", k)
+				if values[i] == nil {
+					ta.Equal(int32(0), i32)
+				} else {
+					ta.Equal(values[i], i32)
+				}
+
+				var dst int32 = -1
+				ta.True(s.GetInto(k, &dst))
+				if values[i] == nil {
+					ta.Equal(int32(-1), dst, "unchanged")
+				} else {
+					ta.Equal(values[i], dst)
+				}
+			}
+		}
+
+		if opt.Complete != nil {
+			got, err := st.ToMap()
+			ta.NoError(err)
+			for i, k := range keys {
+				ta.Equal(values[i], got[k])
+			}
+		}
+	}
+
+	t.Run("NewFromStreams", func(t *testing.T) {
+		kch := make(chan string, len(keys))
+		vch := make(chan interface{}, len(values))
+		for i, k := range keys {
+			kch <- k
+			vch <- values[i]
+		}
+		close(kch)
+		close(vch)
+
+		st, err := NewFromStreams(kch, vch, encode.I32{}, &Opt{DedupValue: Bool(false)})
+		ta.NoError(err)
+
+		for i, k := range keys {
+			v, found := st.Get(k)
+			ta.True(found)
+			ta.Equal(values[i], v)
+		}
+	})
+}
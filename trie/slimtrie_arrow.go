@@ -0,0 +1,71 @@
+package trie
+
+import (
+	"unsafe"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// NewFromArrow creates a SlimTrie from keys packed in one buffer, in the
+// layout of an arrow binary column: the i-th key is
+// data[offsets[i]:offsets[i+1]], thus there are len(offsets)-1 keys.
+//
+// Keys are referenced in data without being copied into Go strings, which
+// saves allocation and memory for a bulk load of a large key set.
+// data must not be modified until NewFromArrow returns.
+// A SlimTrie does not reference data after creation.
+//
+// Keys must be ascending sorted.
+// values is the same as the values argument of NewSlimTrie(), and nil means
+// no value is stored.
+//
+// It returns an ErrInvalidOffsets error if offsets are not ascending or out of
+// data, an ErrLengthMismatch error if the number of values is not the number
+// of keys, and an ErrKeyOutOfOrder error if keys are not sorted.
+//
+// Since 0.5.12
+func NewFromArrow(data []byte, offsets []int32, values []interface{}, e encode.Encoder, opts ...Opt) (*SlimTrie, error) {
+
+	n := len(offsets) - 1
+	if n < 0 {
+		n = 0
+	}
+
+	for i := 0; i < n; i++ {
+		if offsets[i] < 0 || offsets[i] > offsets[i+1] {
+			return nil, errors.Wrapf(ErrInvalidOffsets,
+				"offsets[%d]=%d, offsets[%d]=%d", i, offsets[i], i+1, offsets[i+1])
+		}
+	}
+
+	if n > 0 && int(offsets[n]) > len(data) {
+		return nil, errors.Wrapf(ErrInvalidOffsets,
+			"offsets[%d]=%d > len(data)=%d", n, offsets[n], len(data))
+	}
+
+	if values != nil && len(values) != n {
+		return nil, errors.Wrapf(ErrLengthMismatch, "%d keys, %d values", n, len(values))
+	}
+
+	keys := make([]string, n)
+	for i := 0; i < n; i++ {
+		keys[i] = unsafeString(data[offsets[i]:offsets[i+1]])
+	}
+
+	// A nil []interface{} is not a nil interface{}.
+	var vals interface{}
+	if values != nil {
+		vals = values
+	}
+
+	return NewSlimTrie(e, keys, vals, opts...)
+}
+
+// unsafeString returns a string that shares memory with b.
+func unsafeString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
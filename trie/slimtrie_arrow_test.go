@@ -0,0 +1,83 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func toArrow(keys []string) ([]byte, []int32) {
+	data := make([]byte, 0)
+	offsets := []int32{0}
+	for _, k := range keys {
+		data = append(data, k...)
+		offsets = append(offsets, int32(len(data)))
+	}
+	return data, offsets
+}
+
+func TestNewFromArrow(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := make([]interface{}, len(keys))
+	for i := range keys {
+		values[i] = int32(i)
+	}
+
+	data, offsets := toArrow(keys)
+
+	st, err := NewFromArrow(data, offsets, values, encode.I32{}, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	// The SlimTrie does not reference data.
+	for i := range data {
+		data[i] = 0
+	}
+
+	want, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)), Opt{Complete: Bool(true)})
+	ta.NoError(err)
+	slimtrieEqual(want, st, t)
+
+	t.Run("noValue", func(t *testing.T) {
+		data, offsets := toArrow(keys)
+		st, err := NewFromArrow(data, offsets, nil, nil)
+		ta.NoError(err)
+		for _, k := range keys {
+			ta.NotEqual(int32(-1), st.GetID(k))
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		for _, offsets := range [][]int32{nil, {0}} {
+			st, err := NewFromArrow(nil, offsets, nil, nil)
+			ta.NoError(err)
+			ta.Equal(int32(-1), st.GetID("a"))
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+
+		cases := []struct {
+			data    string
+			offsets []int32
+			values  []interface{}
+			want    error
+		}{
+			{"abc", []int32{0, 2, 1}, nil, ErrInvalidOffsets},
+			{"abc", []int32{-1, 2}, nil, ErrInvalidOffsets},
+			{"abc", []int32{0, 1, 4}, nil, ErrInvalidOffsets},
+			{"abc", []int32{0, 1, 3}, []interface{}{int32(1)}, ErrLengthMismatch},
+			{"cab", []int32{0, 1, 3}, nil, ErrKeyOutOfOrder},
+			{"abc", []int32{0, 1, 1}, nil, ErrKeyOutOfOrder},
+		}
+
+		for i, c := range cases {
+			_, err := NewFromArrow([]byte(c.data), c.offsets, c.values, encode.I32{})
+			ta.Equal(c.want, errors.Cause(err), "%d-th: %+v", i+1, c)
+		}
+	})
+}
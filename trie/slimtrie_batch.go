@@ -0,0 +1,154 @@
+package trie
+
+import (
+	"github.com/openacid/errors"
+)
+
+// MergePolicy specifies how WithBatch() deals with a key that exists in both
+// the SlimTrie and the batch.
+//
+// Since 0.5.12
+type MergePolicy int
+
+const (
+	// MergeReject makes WithBatch() return an ErrDuplicateKey error.
+	MergeReject MergePolicy = iota
+	// MergeKeepOld keeps the existing value.
+	MergeKeepOld
+	// MergeOverwrite replaces the existing value with the one in the batch.
+	MergeOverwrite
+)
+
+// WithBatch returns a new SlimTrie with all keys and values in st and a sorted
+// batch of keys and values, built once with the same options as st.
+// st is not changed.
+//
+// keys must be strictly ascending, otherwise it returns an ErrKeyOutOfOrder
+// error.
+// With Opt.KeyNormalizer, keys are normalized before merging, and the
+// normalized keys must be strictly ascending.
+// values could be nil, in which case keys are added without value.
+// Otherwise it must be of the same length as keys.
+//
+// By default a key existing in st makes it return an ErrDuplicateKey error.
+// Specify policy to keep the existing value or to overwrite it.
+//
+// Existing keys are rebuilt from the trie, which requires a SlimTrie created
+// with Opt{Complete: Bool(true)}, otherwise it returns an ErrIncomplete error.
+// Keys removed by Opt.DedupValue when creating st and keys deleted by Delete()
+// are lost.
+//
+// Since 0.5.12
+func (st *SlimTrie) WithBatch(keys []string, values []interface{}, policy ...MergePolicy) (*SlimTrie, error) {
+
+	pol := MergeReject
+	if len(policy) > 0 {
+		pol = policy[0]
+	}
+
+	if values != nil && len(values) != len(keys) {
+		return nil, errors.Wrapf(ErrLengthMismatch, "%d keys and %d values", len(keys), len(values))
+	}
+
+	n, c := splitNormalizer(st.collation)
+	if c != nil {
+		return nil, errors.Wrapf(ErrCollation, "original keys can not be rebuilt from sort keys")
+	}
+
+	if n != nil {
+		// keys rebuilt from st are normalized, so must be the batch.
+		nkeys := make([]string, len(keys))
+		for i, k := range keys {
+			nkeys[i] = n.Normalize(k)
+		}
+		keys = nkeys
+	}
+
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] >= keys[i] {
+			return nil, errors.Wrapf(ErrKeyOutOfOrder,
+				"keys[%d] >= keys[%d] %q %q", i-1, i, keys[i-1], keys[i])
+		}
+	}
+
+	batchValue := func(i int) interface{} {
+		if values == nil {
+			return nil
+		}
+		return values[i]
+	}
+
+	mkeys := make([]string, 0, len(keys))
+	mvals := make([]interface{}, 0, len(keys))
+
+	// i is the next key in the batch to merge.
+	i := 0
+	var dupErr error
+
+	err := st.IterKV(func(key string, val interface{}) bool {
+
+		for ; i < len(keys) && keys[i] < key; i++ {
+			mkeys = append(mkeys, keys[i])
+			mvals = append(mvals, batchValue(i))
+		}
+
+		if st.inner.DeletedBM != nil && st.GetID(key) == -1 {
+			// deleted by Delete()
+			return true
+		}
+
+		if i < len(keys) && keys[i] == key {
+			switch pol {
+			case MergeReject:
+				dupErr = errors.Wrapf(ErrDuplicateKey, "keys[%d] %s", i, key)
+				return false
+			case MergeOverwrite:
+				val = batchValue(i)
+			}
+			i++
+		}
+
+		mkeys = append(mkeys, key)
+		mvals = append(mvals, val)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dupErr != nil {
+		return nil, dupErr
+	}
+
+	for ; i < len(keys); i++ {
+		mkeys = append(mkeys, keys[i])
+		mvals = append(mvals, batchValue(i))
+	}
+
+	var vals interface{} = mvals
+	if st.getLeaves() == nil && values == nil {
+		// neither st nor the batch has values.
+		vals = nil
+	}
+
+	rst, err := st.rebuild(mkeys, vals)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to build merged SlimTrie")
+	}
+	return rst, nil
+}
+
+// rebuild creates a SlimTrie from keys and values with the same options and
+// encoder as st.
+// A SlimTrie loaded from data without build options is rebuilt with
+// Opt{Complete: Bool(true)}, since its keys are rebuilt from it.
+//
+// Since 0.5.12
+func (st *SlimTrie) rebuild(keys []string, values interface{}) (*SlimTrie, error) {
+
+	opt := st.BuildOptions()
+	if !st.inner.HasBuildOpt {
+		opt.Complete = Bool(true)
+	}
+
+	return NewSlimTrie(st.encoder, keys, values, opt)
+}
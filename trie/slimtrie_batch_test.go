@@ -0,0 +1,125 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_WithBatch(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"b", "d", "f"}
+	values := []int32{1, 2, 3}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values,
+		Opt{Complete: Bool(true), DedupValue: Bool(false)})
+	ta.NoError(err)
+
+	m, err := st.WithBatch([]string{"a", "c", "g", "h"}, []interface{}{int32(10), int32(11), int32(12), nil})
+	ta.NoError(err)
+
+	got, err := m.ToMap()
+	ta.NoError(err)
+	ta.Equal(map[string]interface{}{
+		"a": int32(10),
+		"b": int32(1),
+		"c": int32(11),
+		"d": int32(2),
+		"f": int32(3),
+		"g": int32(12),
+		"h": nil,
+	}, got)
+	ta.Equal(st.BuildOptions(), m.BuildOptions())
+
+	// st is not changed
+	got, err = st.ToMap()
+	ta.NoError(err)
+	ta.Equal(3, len(got))
+
+	t.Run("overlap", func(t *testing.T) {
+		batch := []string{"c", "d"}
+		bvals := []interface{}{int32(11), int32(20)}
+
+		_, err := st.WithBatch(batch, bvals)
+		ta.Equal(ErrDuplicateKey, errors.Cause(err))
+
+		m, err := st.WithBatch(batch, bvals, MergeKeepOld)
+		ta.NoError(err)
+		v, _ := m.Get("d")
+		ta.Equal(int32(2), v)
+
+		m, err = st.WithBatch(batch, bvals, MergeOverwrite)
+		ta.NoError(err)
+		v, _ = m.Get("d")
+		ta.Equal(int32(20), v)
+		v, _ = m.Get("c")
+		ta.Equal(int32(11), v)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := st.WithBatch([]string{"c", "a"}, nil)
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+
+		_, err = st.WithBatch([]string{"c"}, []interface{}{})
+		ta.Equal(ErrLengthMismatch, errors.Cause(err))
+
+		inc, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+		_, err = inc.WithBatch([]string{"c"}, nil)
+		ta.Equal(ErrIncomplete, errors.Cause(err))
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		m, err := st.WithBatch([]string{"a", "e"}, nil)
+		ta.NoError(err)
+		for _, k := range []string{"a", "b", "d", "e", "f"} {
+			ta.True(m.Has(k), k)
+		}
+		ta.False(m.Has("c"))
+	})
+
+	t.Run("normalizer", func(t *testing.T) {
+		ta := require.New(t)
+
+		norm := lowerNormalizer{"test.lower.v1"}
+		st, err := NewSlimTrie(encode.I32{}, []string{"B", "d"}, []int32{1, 2},
+			Opt{Complete: Bool(true), KeyNormalizer: norm})
+		ta.NoError(err)
+
+		m, err := st.WithBatch([]string{"A", "c"}, []interface{}{int32(10), int32(11)})
+		ta.NoError(err)
+		ta.Equal("test.lower.v1", m.KeyNormalizer())
+
+		got, err := m.ToMap()
+		ta.NoError(err)
+		ta.Equal(map[string]interface{}{
+			"a": int32(10),
+			"b": int32(1),
+			"c": int32(11),
+			"d": int32(2),
+		}, got)
+
+		v, found := m.Get("C")
+		ta.True(found)
+		ta.Equal(int32(11), v)
+
+		_, err = st.WithBatch([]string{"D"}, []interface{}{int32(20)})
+		ta.Equal(ErrDuplicateKey, errors.Cause(err))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		m, err := st.WithBatch(keys, []interface{}{int32(1), int32(2), int32(3)})
+		ta.NoError(err)
+		testPresentKeysGet(t, m, keys, values)
+	})
+}
@@ -1,6 +1,9 @@
 package trie
 
 import (
+	"fmt"
+	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/openacid/slim/encode"
@@ -32,6 +35,68 @@ func BenchmarkSlimTrie_GetID_20k_vlen10(b *testing.B) {
 	}
 }
 
+func BenchmarkSlimTrie_GetID_BigThreshold(b *testing.B) {
+
+	keys := getKeys("200kweb2")
+	values := makeI32s(len(keys))
+
+	for _, threshold := range []int32{-1, 0, 1} {
+
+		st, _ := NewSlimTrie(encode.I32{}, keys, values, Opt{BigThreshold: threshold})
+		b.Logf("BigThreshold=%d: big inner nodes: %d, levels: %d, %d bytes",
+			threshold, st.inner.BigInnerCnt, len(st.levels), st.MappedBytes())
+
+		b.Run(fmt.Sprintf("BigThreshold=%d", threshold), func(b *testing.B) {
+			var id int32
+			for i := 0; i < b.N; i++ {
+				id += st.GetID(keys[i%len(keys)])
+			}
+			Outputxxx = id
+		})
+	}
+}
+
+func BenchmarkSlimTrie_GetID_NoInnerPrefix(b *testing.B) {
+
+	// Pathological keys: pairs of keys sharing a long random part, the
+	// prefix of an inner node.
+	rng := rand.New(rand.NewSource(0))
+	keys := make([]string, 0, 20000)
+	for i := 0; len(keys) < cap(keys); i++ {
+		shared := make([]byte, 64)
+		rng.Read(shared)
+		pref := fmt.Sprintf("%08d", i) + string(shared)
+		keys = append(keys, pref+"\x01", pref+"\x02")
+	}
+	sort.Strings(keys)
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		name string
+		opt  Opt
+	}{
+		{"InnerPrefix", Opt{InnerPrefix: Bool(true)}},
+		{"step", Opt{}},
+		{"NoInnerPrefix", Opt{NoInnerPrefix: Bool(true)}},
+	}
+
+	for _, c := range cases {
+
+		st, _ := NewSlimTrie(encode.I32{}, keys, values, c.opt)
+		stat := st.Stats()
+		b.Logf("%s: inner nodes: %d, levels: %d, inner prefix: %d bytes, %d bytes",
+			c.name, stat.InnerCnt, len(st.levels), len(st.inner.InnerPrefixes.Bytes), st.MappedBytes())
+
+		b.Run(c.name, func(b *testing.B) {
+			var id int32
+			for i := 0; i < b.N; i++ {
+				id += st.GetID(keys[i%len(keys)])
+			}
+			Outputxxx = id
+		})
+	}
+}
+
 func BenchmarkSlimTrie_withPrefixContent_GetID_20k_vlen10(b *testing.B) {
 
 	keys := getKeys("20kvl10")
@@ -57,3 +122,136 @@ func BenchmarkSlimTrie_withPrefixContent_GetID_20k_vlen10(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkSlimTrie_GetID_20k_vlen10_miss(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+
+	// Prepend a byte that no key starts with.
+	misses := make([]string, len(keys))
+	for j, k := range keys {
+		misses[j] = "\xff" + k
+	}
+
+	var id int32
+
+	b.ResetTimer()
+
+	i := b.N
+	for {
+		for _, k := range misses {
+			id += st.GetID(k)
+
+			i--
+			if i == 0 {
+				Outputxxx = id
+				return
+			}
+		}
+	}
+}
+
+func BenchmarkSlimTrie_ResolveInto_20k_vlen10(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+
+	res := &Resolution{}
+	var id int32
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	i := b.N
+	for {
+		for _, k := range keys {
+			st.ResolveInto(res, k)
+			id += res.LeafOrdinal
+
+			i--
+			if i == 0 {
+				Outputxxx = id
+				return
+			}
+		}
+	}
+}
+
+func BenchmarkSlimTrie_GetID_10m(b *testing.B) {
+
+	if testing.Short() {
+		b.Skip("skip creating a 10M-key trie in short mode")
+	}
+
+	// A trie much larger than CPU caches, queried in random order, thus
+	// every level of a descent is likely a cache miss.
+	n := 10 * 1000 * 1000
+	rng := rand.New(rand.NewSource(0))
+	us := make([]uint64, n)
+	for i := range us {
+		us[i] = rng.Uint64()
+	}
+	sort.Slice(us, func(i, j int) bool { return us[i] < us[j] })
+
+	keys := make([]string, 0, n)
+	for i, u := range us {
+		if i > 0 && u == us[i-1] {
+			continue
+		}
+		keys = append(keys, encode.Uint64Key(u))
+	}
+
+	st, err := NewSlimTrie(encode.Dummy{}, keys, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Logf("keys: %d, levels: %d, %d bytes", len(keys), len(st.levels), st.MappedBytes())
+
+	// In key order, adjacent queries share most of a path and it is mostly
+	// served from cache.
+	b.Run("sorted", func(b *testing.B) {
+		var id int32
+		for i := 0; i < b.N; i++ {
+			id += st.GetID(keys[i%len(keys)])
+		}
+		Outputxxx = id
+	})
+
+	rng.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+	b.Run("random", func(b *testing.B) {
+		var id int32
+		for i := 0; i < b.N; i++ {
+			id += st.GetID(keys[i%len(keys)])
+		}
+		Outputxxx = id
+	})
+}
+
+func BenchmarkSlimTrie_GetID_ShortNodes(b *testing.B) {
+
+	for _, name := range []string{"20kvl10", "200kweb2"} {
+
+		keys := getKeys(name)
+		values := makeI32s(len(keys))
+
+		for _, noShort := range []bool{false, true} {
+
+			st, _ := NewSlimTrie(encode.I32{}, keys, values, Opt{NoShortTable: Bool(noShort)})
+			stats := st.Stats()
+			b.Logf("%s NoShortTable=%v: short inner nodes: %d/%d, ShortSize: %d, %d bytes",
+				name, noShort, stats.ShortCnt, stats.InnerCnt, st.inner.ShortSize, st.MappedBytes())
+
+			b.Run(fmt.Sprintf("%s/NoShortTable=%v", name, noShort), func(b *testing.B) {
+				var id int32
+				for i := 0; i < b.N; i++ {
+					id += st.GetID(keys[i%len(keys)])
+				}
+				Outputxxx = id
+			})
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// binaryKeyAlphabet are bytes that are easy to be mishandled: 0x00 is also
+// the KeyTerminator and the padding of a bitstr, 0xff is the greatest.
+var binaryKeyAlphabet = "\x00\x01a\xff"
+
+// allBinaryStrs returns all strings of length [0, maxLen] of
+// binaryKeyAlphabet, in ascending order.
+func allBinaryStrs(maxLen int) []string {
+	rst := []string{""}
+	last := []string{""}
+	for l := 1; l <= maxLen; l++ {
+		next := make([]string, 0, len(last)*len(binaryKeyAlphabet))
+		for _, s := range last {
+			for i := 0; i < len(binaryKeyAlphabet); i++ {
+				next = append(next, s+binaryKeyAlphabet[i:i+1])
+			}
+		}
+		rst = append(rst, next...)
+		last = next
+	}
+	sort.Strings(rst)
+	return rst
+}
+
+func TestSlimTrie_binaryKeys(t *testing.T) {
+
+	ta := require.New(t)
+
+	all := allBinaryStrs(4)
+	rnd := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 200; round++ {
+
+		keys := make([]string, 0)
+		for _, s := range all {
+			if rnd.Intn(8) == 0 {
+				keys = append(keys, s)
+			}
+		}
+		values := makeI32s(len(keys))
+		present := make(map[string]int32)
+		for i, k := range keys {
+			present[k] = values[i]
+		}
+
+		for _, opt := range []Opt{
+			{},
+			{InnerPrefix: Bool(true)},
+			{LeafPrefix: Bool(true)},
+			{Complete: Bool(true)},
+			{Complete: Bool(true), NoShortTable: Bool(true)},
+		} {
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			for i, k := range keys {
+				v, found := st.Get(k)
+				ta.True(found, "keys: %q, opt: %+v, key: %q", keys, opt, k)
+				ta.Equal(values[i], v, "keys: %q, opt: %+v, key: %q", keys, opt, k)
+			}
+
+			if !st.hasCompleteKeys() {
+				continue
+			}
+
+			for _, k := range all {
+				v, found := st.Get(k)
+				if _, ok := present[k]; !ok {
+					ta.False(found, "keys: %q, key: %q, got: %v", keys, k, v)
+				}
+			}
+
+			got, _ := collectIter(st.Iterate())
+			ta.Equal(keys, nonNil(got), "keys: %q", keys)
+
+			for _, p := range all[:len(all)/8] {
+				want := make([]string, 0)
+				for _, k := range keys {
+					if strings.HasPrefix(k, p) {
+						want = append(want, k)
+					}
+				}
+				got, _ := collectIter(st.WalkPrefix(p))
+				ta.Equal(want, nonNil(got), "keys: %q, prefix: %q", keys, p)
+				ta.Equal(len(want), st.PrefixCount(p), "keys: %q, prefix: %q", keys, p)
+			}
+
+			for _, k := range all {
+				lv, ev, rv := st.Search(k)
+				i := sort.SearchStrings(keys, k)
+				var wl, we, wr interface{}
+				if i > 0 {
+					wl = values[i-1]
+				}
+				j := i
+				if i < len(keys) && keys[i] == k {
+					we = values[i]
+					j = i + 1
+				}
+				if j < len(keys) {
+					wr = values[j]
+				}
+				ta.Equal([]interface{}{wl, we, wr}, []interface{}{lv, ev, rv}, "keys: %q, key: %q", keys, k)
+			}
+		}
+	}
+}
+
+func TestSlimTrie_binaryKeys_terminator(t *testing.T) {
+
+	ta := require.New(t)
+
+	// 0x00 is the KeyTerminator, thus keys with it can not be stored with
+	// Opt.WithTerminator.
+	keys := []string{"a", "a\x00b"}
+	_, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)), Opt{WithTerminator: Bool(true)})
+	ta.Equal(ErrTerminatorInKey, errors.Cause(err))
+}
+
+// nonNil converts a nil slice to an empty one to compare with.
+func nonNil(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
+func TestSlimTrie_binaryKeys_nulSuffix(t *testing.T) {
+
+	ta := require.New(t)
+
+	// Every key is a prefix of the next ones, which differ only in trailing
+	// 0x00.
+	keys := make([]string, 0)
+	for _, k := range getKeys("20kvl10")[:3000] {
+		keys = append(keys, k, k+"\x00", k+"\x00\x00", k+"\x00\x01")
+	}
+	sort.Strings(keys)
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	testPresentKeysGRS(t, st, keys, values)
+	testAbsentKeysGRS(t, st, keys)
+
+	for _, k := range keys[:1000] {
+		for _, absent := range []string{k + "\x00\x00\x00", k + "\x01", k + "\x00\x02"} {
+			i := sort.SearchStrings(keys, absent)
+			if i < len(keys) && keys[i] == absent {
+				continue
+			}
+			_, found := st.Get(absent)
+			ta.False(found, "key: %q", absent)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package trie
+
+import "github.com/openacid/slim/encode"
+
+// BuildReport describes what is created from a key set, for tuning the
+// design of keys and options without inspecting the created SlimTrie.
+// It is returned by NewWithReport().
+//
+// Since 0.5.12
+type BuildReport struct {
+	// KeyCnt is the number of keys passed in.
+	KeyCnt int32 `json:"key_cnt"`
+
+	// LeafCnt is the number of leaves. It is less than KeyCnt if keys are
+	// removed by Opt.DedupValue.
+	LeafCnt int32 `json:"leaf_cnt"`
+
+	// InnerCnt is the number of inner nodes, which is the sum of
+	// BigInnerCnt, NormalInnerCnt and ShortInnerCnt.
+	InnerCnt int32 `json:"inner_cnt"`
+
+	// BigInnerCnt is the number of inner nodes with 8-bit labels.
+	BigInnerCnt int32 `json:"big_inner_cnt"`
+
+	// NormalInnerCnt is the number of inner nodes with 4-bit labels, stored
+	// as a 17-bit bitmap.
+	NormalInnerCnt int32 `json:"normal_inner_cnt"`
+
+	// ShortInnerCnt is the number of inner nodes with 4-bit labels, stored as
+	// a short bitmap.
+	ShortInnerCnt int32 `json:"short_inner_cnt"`
+
+	// InnerPrefixBytes is the size of stored inner node prefixes.
+	// It is 0 if only the lengths of prefixes are stored, i.e., without
+	// Opt.InnerPrefix.
+	InnerPrefixBytes int32 `json:"inner_prefix_bytes"`
+
+	// LeafPrefixBytes is the size of stored leaf prefixes.
+	LeafPrefixBytes int32 `json:"leaf_prefix_bytes"`
+
+	// LeafBytes is the size of stored values.
+	LeafBytes int32 `json:"leaf_bytes"`
+
+	// MaxDepth is the number of inner nodes on the longest path from the root
+	// to a leaf, i.e., the most nodes a query visits before reaching a leaf.
+	MaxDepth int32 `json:"max_depth"`
+}
+
+// NewWithReport creates a SlimTrie the same as NewSlimTrie() does, and
+// returns a BuildReport of it.
+// The report is collected when creating, thus it costs almost nothing.
+//
+// Since 0.5.12
+func NewWithReport(e encode.Encoder, keys []string, values interface{}, opts ...Opt) (*SlimTrie, *BuildReport, error) {
+
+	report := &BuildReport{}
+	st, err := newSlimTrie(e, keys, values, opts, report)
+	if err != nil {
+		return nil, nil, err
+	}
+	return st, report, nil
+}
+
+// fillReport fills report with what c has created into ns from keyCnt keys.
+func (c *creator) fillReport(ns *Slim, keyCnt int32, report *BuildReport) {
+
+	innerCnt := int32(len(c.innerIndexes))
+
+	report.KeyCnt = keyCnt
+	report.LeafCnt = c.nodeCnt - innerCnt
+	report.InnerCnt = innerCnt
+	report.BigInnerCnt = c.bigCnt
+	report.ShortInnerCnt = c.shortCnt
+	report.NormalInnerCnt = innerCnt - c.bigCnt - c.shortCnt
+
+	if *c.option.InnerPrefix {
+		report.InnerPrefixBytes = int32(len(ns.InnerPrefixes.Bytes))
+	}
+	if ns.LeafPrefixes != nil {
+		report.LeafPrefixBytes = int32(len(ns.LeafPrefixes.Bytes))
+	}
+	if ns.Leaves != nil {
+		report.LeafBytes = int32(len(ns.Leaves.Bytes))
+	}
+
+	if c.maxLeafLevel > 0 {
+		report.MaxDepth = c.maxLeafLevel - 1
+	}
+}
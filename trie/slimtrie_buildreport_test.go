@@ -0,0 +1,89 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithReport(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{
+		{},
+		{Complete: Bool(true)},
+		{InnerPrefix: Bool(true)},
+		{NoShortTable: Bool(true)},
+		{BigThreshold: -1},
+		{BigThreshold: 1, LeafBlockSize: 16},
+		{NoInnerPrefix: Bool(true)},
+	} {
+		st, rpt, err := NewWithReport(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		want, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+		ta.True(want.Equal(st), "opt: %+v", opt)
+
+		stats := st.Stats()
+		ta.Equal(int32(len(keys)), rpt.KeyCnt)
+		ta.Equal(stats.LeafCnt, rpt.LeafCnt, "opt: %+v", opt)
+		ta.Equal(stats.InnerCnt, rpt.InnerCnt, "opt: %+v", opt)
+		ta.Equal(stats.BigInnerCnt, rpt.BigInnerCnt, "opt: %+v", opt)
+		ta.Equal(stats.ShortCnt, rpt.ShortInnerCnt, "opt: %+v", opt)
+		ta.Equal(rpt.InnerCnt, rpt.BigInnerCnt+rpt.NormalInnerCnt+rpt.ShortInnerCnt, "opt: %+v", opt)
+		ta.Equal(stats.InnerPrefixBytes, rpt.InnerPrefixBytes, "opt: %+v", opt)
+		ta.Equal(stats.LeafPrefixBytes, rpt.LeafPrefixBytes, "opt: %+v", opt)
+		ta.Equal(int32(len(st.inner.Leaves.Bytes)), rpt.LeafBytes, "opt: %+v", opt)
+
+		// levels[0] is a trivial level, the root is at levels[1].
+		ta.Equal(int32(len(st.levels)-2), rpt.MaxDepth, "opt: %+v", opt)
+	}
+
+	t.Run("dedup", func(t *testing.T) {
+		ta := require.New(t)
+
+		_, rpt, err := NewWithReport(encode.I32{}, []string{"a", "b", "c"}, []int32{1, 1, 2})
+		ta.NoError(err)
+		ta.Equal(int32(3), rpt.KeyCnt)
+		ta.Equal(int32(2), rpt.LeafCnt)
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		ta := require.New(t)
+
+		_, rpt, err := NewWithReport(encode.I32{}, []string{"abc"}, []int32{1})
+		ta.NoError(err)
+		ta.Equal(int32(0), rpt.MaxDepth)
+		ta.Equal(int32(1), rpt.LeafCnt)
+
+		// one inner node for every shared 4-bit word, and one to branch.
+		_, rpt, err = NewWithReport(encode.I32{}, []string{"abc1", "abc2"}, []int32{1, 2}, Opt{NoInnerPrefix: Bool(true)})
+		ta.NoError(err)
+		ta.Equal(int32(8), rpt.MaxDepth)
+		ta.Equal(int32(8), rpt.InnerCnt)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, rpt, err := NewWithReport(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+		ta.Equal(0, st.Len())
+		ta.Equal(&BuildReport{}, rpt)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, rpt, err := NewWithReport(encode.I32{}, []string{"b", "a"}, []int32{1, 2})
+		ta.Error(err)
+		ta.Nil(st)
+		ta.Nil(rpt)
+	})
+}
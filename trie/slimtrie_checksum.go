@@ -0,0 +1,67 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"github.com/openacid/errors"
+)
+
+// checksumMagic starts the checksum footer Marshal() appends with
+// Opt.WithChecksum.
+// The footer is checksumMagic followed by the CRC32C of all bytes before the
+// footer, i.e., the header and the body, in little endian.
+//
+// The footer follows the body, thus a reader that reads only the header and
+// the body ignores it.
+//
+// Since 0.5.12
+const checksumMagic = "slC1"
+
+const checksumFooterSize = len(checksumMagic) + 4
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// writeChecksum writes a checksum footer of crc to w.
+//
+// Since 0.5.12
+func writeChecksum(w io.Writer, crc uint32) (int64, error) {
+
+	footer := make([]byte, checksumFooterSize)
+	copy(footer, checksumMagic)
+	binary.LittleEndian.PutUint32(footer[len(checksumMagic):], crc)
+
+	n, err := w.Write(footer)
+	if err != nil {
+		return int64(n), errors.WithMessage(err, "failed to write checksum")
+	}
+	return int64(n), nil
+}
+
+// verifyChecksum verifies the checksum footer following the first n bytes of
+// buf.
+// It returns false if there is no footer, or an ErrCorrupt error if the
+// checksum does not match.
+//
+// Since 0.5.12
+func verifyChecksum(buf []byte, n int64) (bool, error) {
+
+	if n < 0 || n+int64(checksumFooterSize) > int64(len(buf)) {
+		return false, nil
+	}
+
+	footer := buf[n : n+int64(checksumFooterSize)]
+	if !bytes.HasPrefix(footer, []byte(checksumMagic)) {
+		return false, nil
+	}
+
+	want := binary.LittleEndian.Uint32(footer[len(checksumMagic):])
+	got := crc32.Checksum(buf[:n], crc32cTable)
+	if got != want {
+		return true, errors.Wrapf(ErrCorrupt, "checksum mismatch: stored: %08x, computed: %08x", want, got)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,128 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/low/pbcmpl"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_WithChecksum(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{WithChecksum: Bool(true)})
+	ta.NoError(err)
+
+	noSum, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	buf, err := st.Marshal()
+	ta.NoError(err)
+
+	plain, err := noSum.Marshal()
+	ta.NoError(err)
+
+	body := len(buf) - checksumFooterSize
+	ta.Equal(checksumMagic, string(buf[body:body+len(checksumMagic)]))
+
+	t.Run("writeTo", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		w := &bytes.Buffer{}
+		n, err := st.WriteTo(w)
+		ta.NoError(err)
+		ta.Equal(int64(len(buf)), n)
+		ta.Equal(buf, w.Bytes())
+	})
+
+	t.Run("load", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+		testPresentKeysGet(t, st2, keys, values)
+
+		// the footer is written again
+		b2, err := st2.Marshal()
+		ta.NoError(err)
+		ta.Equal(buf, b2)
+
+		// data without checksum is still loaded
+		ta.NoError(st2.Unmarshal(plain))
+		testPresentKeysGet(t, st2, keys, values)
+	})
+
+	t.Run("oldReader", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		// A reader without checksum support reads only the header and body.
+		ns := &Slim{}
+		n, _, err := pbcmpl.Unmarshal(bytes.NewReader(buf), ns)
+		ta.NoError(err)
+		ta.Equal(body, int(n))
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		_, h, err := pbcmpl.ReadHeader(bytes.NewReader(buf))
+		ta.NoError(err)
+		hsize := int(h.GetHeaderSize())
+
+		// a damaged body or checksum is detected.
+		for i := hsize; i < len(buf); i += 97 {
+			bad := append([]byte{}, buf...)
+			bad[i] ^= 0x10
+			if i >= body && i < body+len(checksumMagic) {
+				// a broken magic looks like no footer.
+				continue
+			}
+			err := st2.Unmarshal(bad)
+			ta.Equal(ErrCorrupt, errors.Cause(err), "%d-th byte", i)
+		}
+
+		// a damaged header is not loaded.
+		for i := 0; i < hsize; i++ {
+			bad := append([]byte{}, buf...)
+			bad[i] ^= 0x10
+			ta.Error(st2.Unmarshal(bad), "%d-th byte", i)
+		}
+
+		// a removed footer is detected.
+		for i := 1; i <= checksumFooterSize; i++ {
+			err := st2.Unmarshal(buf[:len(buf)-i])
+			ta.Equal(ErrCorrupt, errors.Cause(err), "remove %d bytes", i)
+		}
+	})
+
+	t.Run("canonical", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		can, err := st.MarshalCanonical()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(can))
+		testPresentKeysGet(t, st2, keys, values)
+
+		can[len(can)/2] ^= 0x10
+		ta.Equal(ErrCorrupt, errors.Cause(st2.Unmarshal(can)))
+	})
+}
@@ -0,0 +1,64 @@
+package trie
+
+import "github.com/golang/protobuf/proto"
+
+// Clone returns a deep copy of the SlimTrie.
+// The clone does not share any storage with st, thus it is safe to use,
+// modify with Set() or Delete(), Reset() or discard either one independently.
+// Only the read-only index built by BuildRangeIndex() is shared.
+//
+// A clone of a SlimTrie loaded from a buffer, such as a memory mapped file,
+// does not refer to the buffer.
+// Leaves not yet loaded by a SlimTrie opened with OpenSplit() or
+// UnmarshalIndexOnly() are loaded, and it panics if loading fails.
+//
+// Since 0.5.12
+func (st *SlimTrie) Clone() *SlimTrie {
+
+	ns, err := st.fullInner()
+	if err != nil {
+		panic(err)
+	}
+
+	c := &SlimTrie{
+		inner:     proto.Clone(ns).(*Slim),
+		encoder:   st.encoder,
+		collation: st.collation,
+		valueFunc: st.valueFunc,
+	}
+	// the same data as st, which is initialized without error.
+	_ = c.init()
+	c.rangeIndex = st.rangeIndex
+
+	return c
+}
+
+// ShallowClone returns a SlimTrie that shares the underlying read-only
+// storage with st, and has its own query-time state.
+//
+// A SlimTrie is read only after creation, thus both st and the shallow clone
+// are safe to query concurrently.
+// Unmarshal() or Reset() on either of them replaces its own storage and does
+// not affect the other.
+// Delete() or Set() on either of them modifies the shared storage and affects
+// both.
+//
+// The shared storage is not released until both of them are released.
+// Use Clone() if the storage must not be shared.
+//
+// Since 0.5.12
+func (st *SlimTrie) ShallowClone() *SlimTrie {
+
+	c := &SlimTrie{
+		inner:      st.inner,
+		encoder:    st.encoder,
+		lazyLeaves: st.lazyLeaves,
+		collation:  st.collation,
+		valueFunc:  st.valueFunc,
+	}
+	// the same data as st, which is initialized without error.
+	_ = c.init()
+	c.rangeIndex = st.rangeIndex
+
+	return c
+}
@@ -0,0 +1,90 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Clone(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	t.Run("shallow", func(t *testing.T) {
+		c := st.ShallowClone()
+
+		ta.True(c.inner == st.inner, "storage is shared")
+		ta.True(c.vars != st.vars, "transient state is not shared")
+		testPresentKeysGet(t, c, keys, values)
+		ta.Equal(st.String(), c.String())
+
+		c.Reset()
+		testPresentKeysGet(t, st, keys, values)
+	})
+
+	t.Run("deep", func(t *testing.T) {
+		c := st.Clone()
+
+		ta.True(c.inner != st.inner)
+		ta.True(c.vars != st.vars)
+		testPresentKeysGet(t, c, keys, values)
+		slimtrieEqual(st, c, t)
+
+		// Modifying the clone storage does not affect the original.
+		c.inner.Leaves.Bytes[0] ^= 0xff
+		testPresentKeysGet(t, st, keys, values)
+	})
+
+	t.Run("detached", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		for _, load := range []func(s *SlimTrie) error{
+			func(s *SlimTrie) error { return s.Unmarshal(buf) },
+			func(s *SlimTrie) error { return s.UnmarshalIndexOnly(bytes.NewReader(buf), int64(len(buf))) },
+		} {
+			src, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(load(src))
+
+			c := src.Clone()
+			ta.True(c.Equal(src))
+
+			// Mutating the clone does not affect the source.
+			ta.True(c.Set(keys[0], int32(-1)))
+			ta.True(c.Delete(keys[1]))
+			testPresentKeysGet(t, src, keys, values)
+
+			v, found := c.Get(keys[0])
+			ta.True(found)
+			ta.Equal(int32(-1), v)
+			_, found = c.Get(keys[1])
+			ta.False(found)
+
+			// The clone survives a Reset of the source.
+			src.Reset()
+			testPresentKeysGet(t, c, keys[2:], values[2:])
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		e, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+
+		for _, c := range []*SlimTrie{e.Clone(), e.ShallowClone()} {
+			_, found := c.Get("a")
+			ta.False(found)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package trie
+
+import (
+	"sync"
+
+	"github.com/openacid/errors"
+)
+
+// Collation defines a custom order of keys, such as a locale-sensitive order of
+// human-language strings.
+//
+// A trie branches on bytes thus it is always in byte order.
+// To order keys by a collation, SlimTrie stores the sort key of every key,
+// whose byte order is the same as the collation order, and converts a query
+// key to its sort key before searching.
+//
+// Keys rebuilt by scanning methods such as IterKV() are sort keys, and a
+// start key or prefix passed to them is not converted.
+//
+// Since 0.5.12
+type Collation interface {
+
+	// Name identifies a collation.
+	// It is stored in the marshaled data and is used to find the registered
+	// collation when loading.
+	// A collation must change its name if its order changes.
+	Name() string
+
+	// Compare returns a negative number, 0 or a positive number if a is
+	// before, the same as or after b.
+	Compare(a, b string) int
+
+	// SortKey returns a string whose byte order is the same as the collation
+	// order of key, i.e., Compare(a, b) < 0 if and only if SortKey(a) <
+	// SortKey(b).
+	SortKey(key string) string
+}
+
+var (
+	collationsMu sync.RWMutex
+	collations   = map[string]Collation{}
+)
+
+// RegisterCollation registers a Collation by its name, so that a SlimTrie
+// built with it can be loaded.
+// A later registration with the same name replaces the previous one.
+//
+// Since 0.5.12
+func RegisterCollation(c Collation) {
+	collationsMu.Lock()
+	defer collationsMu.Unlock()
+
+	collations[c.Name()] = c
+}
+
+// LookupCollation returns the Collation registered with name.
+//
+// Since 0.5.12
+func LookupCollation(name string) (Collation, bool) {
+	collationsMu.RLock()
+	defer collationsMu.RUnlock()
+
+	c, ok := collations[name]
+	return c, ok
+}
+
+// Collation returns the name of the Collation the SlimTrie is built with, or
+// "" if keys are in byte order.
+//
+// Since 0.5.12
+func (st *SlimTrie) Collation() string {
+	return st.inner.GetCollation()
+}
+
+// collateKeys checks keys are strictly ascending by c and returns their sort
+// keys.
+// It returns an ErrKeyOutOfOrder error if keys are not sorted by c, or an
+// ErrCollation error if the sort keys disagree with c.Compare().
+//
+// Since 0.5.12
+func collateKeys(c Collation, keys []string) ([]string, error) {
+
+	sortKeys := make([]string, len(keys))
+	for i, k := range keys {
+		sortKeys[i] = c.SortKey(k)
+
+		if i == 0 {
+			continue
+		}
+
+		if c.Compare(keys[i-1], k) >= 0 {
+			return nil, errors.Wrapf(ErrKeyOutOfOrder,
+				"collation %s: keys[%d] >= keys[%d] %q %q", c.Name(), i-1, i, keys[i-1], k)
+		}
+
+		if sortKeys[i-1] >= sortKeys[i] {
+			return nil, errors.Wrapf(ErrCollation,
+				"collation %s: sort key of keys[%d] >= sort key of keys[%d] %s %s", c.Name(), i-1, i, keys[i-1], k)
+		}
+	}
+
+	return sortKeys, nil
+}
+
+// loadCollation finds the registered Collation and KeyNormalizer for a loaded
+// SlimTrie.
+// It returns an ErrCollation error if it is built with a Collation or a
+// KeyNormalizer that is not registered.
+//
+// Since 0.5.12
+func (st *SlimTrie) loadCollation() error {
+
+	st.collation = nil
+
+	name := st.inner.GetCollation()
+	if name != "" {
+		c, ok := LookupCollation(name)
+		if !ok {
+			return errors.Wrapf(ErrCollation, "collation %s is not registered", name)
+		}
+		st.collation = c
+	}
+
+	return st.loadKeyNormalizer()
+}
+
+// sortKey converts a query key to the key stored in SlimTrie.
+func (st *SlimTrie) sortKey(key string) string {
+	if st.collation != nil {
+		key = st.collation.SortKey(key)
+	}
+	return st.terminate(key)
+}
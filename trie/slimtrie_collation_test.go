@@ -0,0 +1,111 @@
+package trie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// caseCollation orders keys case-insensitively, and by byte order if they are
+// the same case-insensitively.
+type caseCollation struct {
+	name string
+}
+
+func (c caseCollation) Name() string { return c.name }
+
+func (c caseCollation) Compare(a, b string) int {
+	r := strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	if r != 0 {
+		return r
+	}
+	return strings.Compare(a, b)
+}
+
+func (c caseCollation) SortKey(key string) string {
+	return strings.ToLower(key) + "\x00" + key
+}
+
+// badCollation has a SortKey that disagrees with Compare.
+type badCollation struct{ caseCollation }
+
+func (c badCollation) SortKey(key string) string { return key }
+
+func TestSlimTrie_Collation(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"apple", "Banana", "banana", "cherry"}
+	values := []int32{0, 1, 2, 3}
+	coll := caseCollation{"test.case.v1"}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values,
+		Opt{Complete: Bool(true), Collation: coll, SelfCheck: Bool(true)})
+	ta.NoError(err)
+	ta.Equal("test.case.v1", st.Collation())
+	ta.Equal(coll, st.BuildOptions().Collation)
+
+	testPresentKeysGet(t, st, keys, values)
+
+	_, found := st.Get("BANANA")
+	ta.False(found)
+
+	l, eq, r := st.Search("B")
+	ta.Equal(int32(0), l)
+	ta.Nil(eq)
+	ta.Equal(int32(1), r)
+
+	l, eq, r = st.Search("bb")
+	ta.Equal(int32(2), l)
+	ta.Nil(eq)
+	ta.Equal(int32(3), r)
+
+	t.Run("outOfOrder", func(t *testing.T) {
+		_, err := NewSlimTrie(encode.I32{}, []string{"Banana", "apple"}, []int32{0, 1},
+			Opt{Collation: coll})
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+
+		// sorted in byte order but not by collation
+		_, err = NewSlimTrie(encode.I32{}, []string{"b", "C", "a"}, []int32{0, 1, 2},
+			Opt{Collation: coll})
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+	})
+
+	t.Run("badSortKey", func(t *testing.T) {
+		_, err := NewSlimTrie(encode.I32{}, keys, values,
+			Opt{Collation: badCollation{caseCollation{"test.bad"}}})
+		ta.Equal(ErrCollation, errors.Cause(err))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		kch, vch := sendStreams(keys, values)
+		st, err := NewFromStreams(kch, vch, encode.I32{}, &Opt{Collation: coll})
+		ta.NoError(err)
+		testPresentKeysGet(t, st, keys, values)
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		err = st2.Unmarshal(buf)
+		ta.Equal(ErrCollation, errors.Cause(err))
+
+		RegisterCollation(coll)
+		c, ok := LookupCollation("test.case.v1")
+		ta.True(ok)
+		ta.Equal(coll, c)
+
+		ta.NoError(st2.Unmarshal(buf))
+		testPresentKeysGet(t, st2, keys, values)
+
+		st2.Reset()
+		ta.Nil(st2.collation)
+	})
+}
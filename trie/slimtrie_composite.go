@@ -0,0 +1,29 @@
+package trie
+
+import "github.com/openacid/slim/encode"
+
+// NewComposite creates a SlimTrie with composite keys.
+// Every element of keys is a tuple of fields, which is encoded by
+// encode.CompositeKey().
+// Tuples must be ascending sorted, compared field by field.
+//
+// Other arguments are the same as NewSlimTrie().
+//
+// Since 0.5.12
+func NewComposite(e encode.Encoder, keys [][]interface{}, values interface{}, opts ...Opt) (*SlimTrie, error) {
+
+	ks := make([]string, len(keys))
+	for i, fields := range keys {
+		ks[i] = encode.CompositeKey(fields...)
+	}
+
+	return NewSlimTrie(e, ks, values, opts...)
+}
+
+// GetComposite is the same as Get() except it accepts the fields of a
+// composite key, see NewComposite().
+//
+// Since 0.5.12
+func (st *SlimTrie) GetComposite(fields ...interface{}) (interface{}, bool) {
+	return st.Get(encode.CompositeKey(fields...))
+}
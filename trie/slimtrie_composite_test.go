@@ -0,0 +1,47 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Composite(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := [][]interface{}{
+		{"acme", uint32(1), int64(-5)},
+		{"acme", uint32(1), int64(7)},
+		{"acme", uint32(256), int64(0)},
+		{"acme\x00", uint32(0), int64(0)},
+		{"b", uint32(0), int64(1)},
+	}
+	values := makeI32s(len(keys))
+
+	st, err := NewComposite(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for i, k := range keys {
+		v, found := st.GetComposite(k...)
+		ta.True(found, "key: %v", k)
+		ta.Equal(values[i], v, "key: %v", k)
+	}
+
+	_, found := st.GetComposite("acme", uint32(1), int64(0))
+	ta.False(found)
+
+	_, found = st.GetComposite("acm", uint32(1), int64(-5))
+	ta.False(found)
+
+	t.Run("outOfOrder", func(t *testing.T) {
+		keys := [][]interface{}{
+			{"a", int32(1)},
+			{"a", int32(-1)},
+		}
+		_, err := NewComposite(encode.I32{}, keys, makeI32s(2))
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+	})
+}
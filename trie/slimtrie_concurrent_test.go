@@ -0,0 +1,78 @@
+package trie
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSlimTrie_concurrentRead queries one SlimTrie from many goroutines.
+// Run it with `go test -race` to detect data races in the read path.
+func TestSlimTrie_concurrentRead(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	buf := &bytes.Buffer{}
+	ta.NoError(st.MarshalSplit(buf))
+
+	// leaves of a split SlimTrie are loaded by the first reader.
+	split, err := OpenSplit(bytes.NewReader(buf.Bytes()), encode.I32{})
+	ta.NoError(err)
+
+	for _, s := range []*SlimTrie{st, split, st.ShallowClone()} {
+
+		var wg sync.WaitGroup
+		errs := make(chan string, 16)
+
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+
+				for i := g; i < len(keys); i += 64 {
+					k := keys[i]
+
+					v, found := s.Get(k)
+					if !found || v != values[i] {
+						errs <- k
+						return
+					}
+
+					_, eq, _ := s.Search(k)
+					if eq != values[i] {
+						errs <- k
+						return
+					}
+
+					v, _ = s.RangeGet(k)
+					if v != values[i] {
+						errs <- k
+						return
+					}
+
+					s.NearestN(k, 3)
+					s.PrefixCount(k)
+
+					it := s.ScanRange(k, "")
+					it.Next()
+				}
+			}(g)
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for k := range errs {
+			ta.Fail("wrong result", "key: %q", k)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !debug
+// +build !debug
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_corruptLeaf(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "ab", "b"}
+	st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)))
+	ta.NoError(err)
+
+	// the root is an inner node, where a query lands only if data is
+	// corrupted.
+	// It reports a miss instead of panicking, while with tag debug,
+	// must.Be panics.
+	v, ok := st.lookupLeaf(0)
+	ta.Nil(v)
+	ta.False(ok)
+	ta.Nil(st.getLeaf(0))
+
+	v, ok = st.lookupLeaf(st.GetID("b"))
+	ta.True(ok)
+	ta.Equal(int32(2), v)
+}
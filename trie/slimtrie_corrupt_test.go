@@ -0,0 +1,114 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_corruptLoop(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "ab", "b", "ba", "bb", "c"}
+
+	// makeCyclic creates a SlimTrie in which node 1 has itself as a child.
+	makeCyclic := func() *SlimTrie {
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)),
+			Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		// Clear labels of the root and node 1, the first child of node 1
+		// becomes 1 and the last child becomes 0.
+		ns := st.inner
+		for _, id := range []int32{0, 1} {
+			qr := &querySession{}
+			st.getNode(id, qr)
+			for i := qr.from; i < qr.to; i++ {
+				ns.Inners.Words[i>>6] &^= 1 << uint(i&63)
+			}
+		}
+		ns.Inners.RankIndex = nil
+		ns.Inners.indexit("r128")
+		return st
+	}
+
+	st := makeCyclic()
+
+	ta.Equal(int32(-1), st.leftMost(1, nil))
+	ta.Equal(int32(-1), st.rightMost(1, nil))
+
+	first, last := st.LeafOrdinalRange(1)
+	ta.Equal(int32(-1), first)
+	ta.Equal(int32(-1), last)
+
+	for _, k := range []string{"", "a", "ab", "b", "bc", "c", "d"} {
+		ta.NotPanics(func() {
+			l, eq, r := st.Search(k)
+			ta.Nil(l, "Search(%q)", k)
+			ta.Nil(eq, "Search(%q)", k)
+			ta.Nil(r, "Search(%q)", k)
+
+			v, found := st.RangeGet(k)
+			ta.Nil(v, "RangeGet(%q)", k)
+			ta.False(found, "RangeGet(%q)", k)
+
+			st.NearestN(k, 3)
+			st.ScanRange(k, "")
+			st.ScanRangeReverse("", k)
+		}, "key: %q", k)
+	}
+
+	t.Run("init", func(t *testing.T) {
+		ta := require.New(t)
+
+		st := makeCyclic()
+		ta.Equal(ErrCorrupt, errors.Cause(st.init()))
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NotPanics(func() { err = st2.Unmarshal(buf) })
+		ta.Equal(ErrCorrupt, errors.Cause(err))
+	})
+
+	t.Run("iterate", func(t *testing.T) {
+		ta := require.New(t)
+
+		ta.NotPanics(func() {
+			for _, it := range []*Iterator{
+				st.Iterate(),
+				st.IterateReverse(),
+				st.WalkPrefix("a"),
+			} {
+				for _, _, ok := it.Next(); ok; _, _, ok = it.Next() {
+				}
+			}
+		})
+
+		err := st.IterKV(func(key string, val interface{}) bool { return true })
+		ta.Equal(ErrCorrupt, errors.Cause(err))
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)),
+			Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ta.Equal(st.GetID("a"), st.leftMost(1, nil))
+
+		_, eq, _ := st.Search("a")
+		ta.Equal(int32(0), eq)
+		v, found := st.RangeGet("ab")
+		ta.True(found)
+		ta.Equal(int32(1), v)
+
+		ta.NoError(st.IterKV(func(key string, val interface{}) bool { return true }))
+	})
+}
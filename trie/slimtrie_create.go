@@ -39,6 +39,12 @@ type creator struct {
 	bigCnt  int32
 	nodeCnt int32
 
+	// shortCnt is the number of inner nodes replaced with short ones.
+	shortCnt int32
+
+	// maxLeafLevel is the greatest level of a leaf, the root is at level 1.
+	maxLeafLevel int32
+
 	leafCnt int32
 
 	withLeaves bool
@@ -128,7 +134,7 @@ func (c *creator) addInner(nid int32, bmindex []int32, bmsize int32, prefixBitFr
 
 	if c.isBig {
 		c.bigCnt++
-	} else {
+	} else if !*c.option.NoShortTable {
 
 		// Only index non-big inner node.
 
@@ -325,15 +331,70 @@ func findMinShortSize(sorted [][]counterElt) (int32, int32) {
 
 func (c *creator) build() *Slim {
 
+	ns := &Slim{
+		BigInnerCnt: c.bigCnt,
+	}
+
+	// shortIndex is a bitmap indicating which inner node is replaced with a
+	// short one.
+	shortIndex := make([]int32, 0, c.nodeCnt)
+
+	if !*c.option.NoShortTable {
+		shortIndex = c.buildShort(ns, shortIndex)
+	}
+
+	innerCnt := int32(len(c.innerIndexes))
+
+	c.shortCnt = int32(len(shortIndex))
+	ns.ShortBM = newBM(shortIndex, innerCnt, "r64")
+
+	// If it is empty, do not create NodeTypeBM. Query funcs check this field to
+	// to determine if it is empty.
+	if c.nodeCnt > 0 {
+		// Extend to avoid index out of bound panic.
+		ns.NodeTypeBM = newBM(c.innerIndexes, c.nodeCnt, "r64")
+	}
+	ns.Inners = &Bitmap{
+		Words: bitmap.OfMany(c.innerBMs, c.innerSizes),
+	}
+	ns.Inners.indexit("r128")
+
+	ns.InnerPrefixes = &VLenArray{}
+	ns.InnerPrefixes.EltCnt = int32(len(c.prefixIndexes))
+	ns.InnerPrefixes.PresenceBM = newBM(c.prefixIndexes, innerCnt, "r128")
+	if *c.option.InnerPrefix {
+		ns.InnerPrefixes.PositionBM = newBM(stepToPos(c.prefixByteLens, 0), 0, "s32")
+		ns.InnerPrefixes.Bytes = c.prefixes
+
+	} else {
+		ns.InnerPrefixes.FixedSize = 2
+		ns.InnerPrefixes.Bytes = c.prefix4BitLens
+	}
+
+	if *c.option.LeafPrefix {
+		// c.leafCnt is counted only when there are values.
+		leafCnt := c.nodeCnt - int32(len(c.innerIndexes))
+		ns.LeafPrefixes = &VLenArray{}
+		ns.LeafPrefixes.PresenceBM = newBM(c.leafPrefixIndexes, leafCnt, "r64")
+		ns.LeafPrefixes.PositionBM = newBM(stepToPos(c.leafPrefixLens, 0), 0, "s32")
+		ns.LeafPrefixes.Bytes = c.leafPrefixes
+	}
+
+	return ns
+}
+
+// buildShort finds out the best short size and replaces most used 17-bit
+// bitmaps with short ones.
+// It fills ShortSize and ShortTable in ns and returns the inner indexes of
+// short nodes appended to shortIndex.
+func (c *creator) buildShort(ns *Slim, shortIndex []int32) []int32 {
+
 	sorted := sortedBMCounts(c.innerBMCnt)
 	shortSize, shortCnt := findMinShortSize(sorted)
 
 	_ = shortCnt
 
-	ns := &Slim{
-		ShortSize:   shortSize,
-		BigInnerCnt: c.bigCnt,
-	}
+	ns.ShortSize = shortSize
 
 	// Mapping most used 17-bit bitmap inner node to short inner node.
 	//
@@ -364,10 +425,6 @@ func (c *creator) build() *Slim {
 
 	// convert most used node bitmap to short
 
-	// shortIndex is a bitmap indicating which inner node is replaced with a
-	// short one.
-	shortIndex := make([]int32, 0, c.nodeCnt)
-
 	for innerI := c.bigCnt; innerI < int32(len(c.innerBMs)); innerI++ {
 		bmindex := c.innerBMs[innerI]
 
@@ -384,41 +441,7 @@ func (c *creator) build() *Slim {
 		}
 	}
 
-	innerCnt := int32(len(c.innerIndexes))
-
-	ns.ShortBM = newBM(shortIndex, innerCnt, "r64")
-
-	// If it is empty, do not create NodeTypeBM. Query funcs check this field to
-	// to determine if it is empty.
-	if c.nodeCnt > 0 {
-		// Extend to avoid index out of bound panic.
-		ns.NodeTypeBM = newBM(c.innerIndexes, c.nodeCnt, "r64")
-	}
-	ns.Inners = &Bitmap{
-		Words: bitmap.OfMany(c.innerBMs, c.innerSizes),
-	}
-	ns.Inners.indexit("r128")
-
-	ns.InnerPrefixes = &VLenArray{}
-	ns.InnerPrefixes.EltCnt = int32(len(c.prefixIndexes))
-	ns.InnerPrefixes.PresenceBM = newBM(c.prefixIndexes, innerCnt, "r128")
-	if *c.option.InnerPrefix {
-		ns.InnerPrefixes.PositionBM = newBM(stepToPos(c.prefixByteLens, 0), 0, "s32")
-		ns.InnerPrefixes.Bytes = c.prefixes
-
-	} else {
-		ns.InnerPrefixes.FixedSize = 2
-		ns.InnerPrefixes.Bytes = c.prefix4BitLens
-	}
-
-	if *c.option.LeafPrefix {
-		ns.LeafPrefixes = &VLenArray{}
-		ns.LeafPrefixes.PresenceBM = newBM(c.leafPrefixIndexes, c.leafCnt, "r64")
-		ns.LeafPrefixes.PositionBM = newBM(stepToPos(c.leafPrefixLens, 0), 0, "s32")
-		ns.LeafPrefixes.Bytes = c.leafPrefixes
-	}
-
-	return ns
+	return shortIndex
 }
 
 func (c *creator) buildLeaves(bytesValues [][]byte) *VLenArray {
@@ -440,7 +463,10 @@ func (c *creator) buildLeaves(bytesValues [][]byte) *VLenArray {
 		elts = c.leaves
 	}
 
-	return newVLenArray(elts)
+	if *c.option.LeafExceptions {
+		return newExceptionVLenArray(elts)
+	}
+	return newBlockVLenArray(elts, c.option.LeafBlockSize)
 }
 
 // Select the `bytes`s by `indexes`. return the result and total size.
@@ -457,7 +483,9 @@ func selectByIndexes(indexes []int32, bytesSlice [][]byte) ([][]byte, int) {
 	return res, sz
 }
 
-func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
+// newSlim creates the structure of a SlimTrie from sorted keys.
+// If report is not nil, it is filled with the statistics of creating.
+func newSlim(keys []string, bytesValues [][]byte, opt *Opt, report *BuildReport) (*Slim, error) {
 
 	n := len(keys)
 	if n == 0 {
@@ -467,7 +495,14 @@ func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
 	for i := 0; i < n-1; i++ {
 		if keys[i] >= keys[i+1] {
 			return nil, errors.Wrapf(ErrKeyOutOfOrder,
-				"keys[%d] >= keys[%d] %s %s", i, i+1, keys[i], keys[i+1])
+				"keys[%d] >= keys[%d] %q %q", i, i+1, keys[i], keys[i+1])
+		}
+	}
+
+	for i, k := range keys {
+		if len(k) > MaxKeyLen {
+			return nil, errors.Wrapf(ErrKeyTooLong,
+				"keys[%d] len: %d > %d", i, len(k), MaxKeyLen)
 		}
 	}
 
@@ -476,6 +511,11 @@ func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
 	sb := sigbits.New(keys)
 	c := newCreator(n, bytesValues != nil, opt)
 
+	bigThreshold := opt.BigThreshold
+	if bigThreshold == 0 {
+		bigThreshold = defaultBigThreshold
+	}
+
 	queue := make([]subset, 0, n*2)
 	queue = append(queue, subset{0, int32(n), 0, 1})
 
@@ -489,6 +529,9 @@ func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
 			must.Be.True(tokeep[s])
 			c.addLeafIndex(nid, s)
 			c.setLeafPrefix(nid, keys[s], o.fromKeyBit)
+			if o.level > c.maxLeafLevel {
+				c.maxLeafLevel = o.level
+			}
 			continue
 		}
 
@@ -504,7 +547,11 @@ func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
 
 			prefCnt := prefCounts[8-(wordStart&7)]
 
-			if prefCnt > 10 {
+			// Without prefix, a node that does not branch at its first byte
+			// has only one label.
+			branches := !*opt.NoInnerPrefix || wordStart&^7 == o.fromKeyBit
+
+			if bigThreshold >= 0 && prefCnt > bigThreshold && branches {
 				// create big inner node with 257 bits
 				must.Be.Equal(int32(0), o.fromKeyBit&7)
 				wordStart &= ^7
@@ -512,7 +559,7 @@ func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
 				bitmapSize = bigInnerSize
 
 				prefLen := (wordStart - o.fromKeyBit) / bigWordSize
-				if prefLen < minPrefix {
+				if prefLen < minPrefix || *opt.NoInnerPrefix {
 					wordStart = o.fromKeyBit
 				}
 			} else {
@@ -528,7 +575,7 @@ func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
 			bitmapSize = innerSize
 
 			prefLen := (wordStart - o.fromKeyBit) / wordSize
-			if prefLen < minPrefix {
+			if prefLen < minPrefix || *opt.NoInnerPrefix {
 				wordStart = o.fromKeyBit
 			}
 		}
@@ -595,6 +642,10 @@ func newSlim(keys []string, bytesValues [][]byte, opt *Opt) (*Slim, error) {
 	slim := c.build()
 	slim.Leaves = c.buildLeaves(bytesValues)
 
+	if report != nil {
+		c.fillReport(slim, int32(n), report)
+	}
+
 	return slim, nil
 }
 
@@ -608,12 +659,22 @@ func encodeValues(n int, values interface{}, e encode.Encoder) [][]byte {
 
 	for i := 0; i < n; i++ {
 		v := getV(rvals, int32(i))
-		bs := e.Encode(v)
-		vals = append(vals, bs)
+		vals = append(vals, encodeValue(v, e))
 	}
 	return vals
 }
 
+// encodeValue encodes a value.
+// A nil value is encoded to an empty slice, i.e., an absent leaf.
+//
+// Since 0.5.12
+func encodeValue(v interface{}, e encode.Encoder) []byte {
+	if v == nil {
+		return []byte{}
+	}
+	return e.Encode(v)
+}
+
 // newToKeep creates a []bool about which record to keep in slim.
 // If DedupValue is true, value[i+1] with the same value with value[i] do not need to keep.
 func newToKeep(n int, values [][]byte, opt *Opt) []bool {
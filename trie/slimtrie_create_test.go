@@ -0,0 +1,45 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_leafPrefixWithoutValue(t *testing.T) {
+
+	ta := require.New(t)
+
+	// The first leaf has a leaf prefix "xyz" and the other 100 leaves have
+	// none, thus the presence bitmap of leaf prefixes must cover leaves
+	// beyond the last one with a prefix.
+	keys := []string{"axyz"}
+	for i := 0; i < 100; i++ {
+		keys = append(keys, "b"+string([]byte{byte(i)}))
+	}
+
+	for _, opt := range []Opt{
+		{LeafPrefix: Bool(true)},
+		{Complete: Bool(true)},
+	} {
+		st, err := NewSlimTrie(nil, keys, nil, opt)
+		ta.NoError(err)
+
+		for _, k := range keys {
+			ta.True(st.Has(k), "opt: %+v, key: %q", opt, k)
+		}
+		ta.False(st.Has("axy"))
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(nil, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+
+		for _, k := range keys {
+			ta.True(st2.Has(k), "opt: %+v, key: %q", opt, k)
+		}
+		ta.False(st2.Has("axy"))
+	}
+}
@@ -0,0 +1,126 @@
+package trie
+
+import (
+	"sort"
+
+	"github.com/openacid/low/bitmap"
+)
+
+// cursor points to a leaf and moves to the previous or next leaf in key
+// order.
+//
+// Leaf indexes are in breadth-first order, thus the neighbor of a leaf in key
+// order is found by walking up to a parent that has a sibling on the moving
+// side, then walking down to the nearest leaf in the sibling.
+//
+// Since 0.5.12
+type cursor struct {
+	st *SlimTrie
+
+	// path is the node ids from root to the current leaf.
+	path []int32
+}
+
+// newCursor creates a cursor pointing to the leaf leafID.
+//
+// Since 0.5.12
+func (st *SlimTrie) newCursor(leafID int32) *cursor {
+
+	path := []int32{leafID}
+	for id := leafID; id != 0; {
+		id = st.parentOf(id)
+		path = append(path, id)
+	}
+
+	// reverse it to be from root to leaf
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return &cursor{st: st, path: path}
+}
+
+// leaf returns the node id of the current leaf.
+func (c *cursor) leaf() int32 {
+	return c.path[len(c.path)-1]
+}
+
+// next moves the cursor to the next leaf in key order.
+// It returns false and does not move if the current leaf is the last one,
+// or false if an invalid child id is found.
+func (c *cursor) next() bool {
+
+	for j := len(c.path) - 1; j > 0; j-- {
+		_, last := c.st.childRange(c.path[j-1])
+		if c.path[j] < last {
+			sibling := c.path[j] + 1
+			c.path = c.path[:j]
+			return c.st.leftMost(sibling, &c.path) != -1
+		}
+	}
+	return false
+}
+
+// prev moves the cursor to the previous leaf in key order.
+// It returns false and does not move if the current leaf is the first one,
+// or false if an invalid child id is found.
+func (c *cursor) prev() bool {
+
+	for j := len(c.path) - 1; j > 0; j-- {
+		first, _ := c.st.childRange(c.path[j-1])
+		if c.path[j] > first {
+			sibling := c.path[j] - 1
+			c.path = c.path[:j]
+			return c.st.rightMost(sibling, &c.path) != -1
+		}
+	}
+	return false
+}
+
+// childRange returns the first and the last child id of an inner node.
+// Children of a node are contiguous.
+//
+// Since 0.5.12
+func (st *SlimTrie) childRange(nodeID int32) (int32, int32) {
+
+	ns := st.inner
+	qr := &querySession{}
+	st.getNode(nodeID, qr)
+
+	first, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+	last, bit := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.to-1)
+
+	return first + 1, last + bit
+}
+
+// parentOf returns the id of the parent of a non-root node.
+//
+// Node nodeID is pointed to by the nodeID-th "1" in Inners, which is in the
+// label bitmap of the parent.
+//
+// Since 0.5.12
+func (st *SlimTrie) parentOf(nodeID int32) int32 {
+
+	ns := st.inner
+	lastLevel := st.levels[len(st.levels)-1]
+
+	// the position of the label bit pointing to nodeID
+	bitCnt := len(ns.Inners.Words) * 64
+	labelBit := int32(sort.Search(bitCnt, func(i int) bool {
+		r, b := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, int32(i))
+		return r+b >= nodeID
+	}))
+
+	// the last inner node whose label bitmap starts at or before labelBit
+	qr := &querySession{}
+	ithInner := int32(sort.Search(int(lastLevel.inner), func(i int) bool {
+		st.getIthInnerFrom(int32(i), qr)
+		return qr.from > labelBit
+	})) - 1
+
+	// the node id of the ithInner-th inner node
+	return int32(sort.Search(int(lastLevel.total), func(i int) bool {
+		r, b := bitmap.Rank64(ns.NodeTypeBM.Words, ns.NodeTypeBM.RankIndex, int32(i))
+		return r+b > ithInner
+	}))
+}
@@ -0,0 +1,80 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// sortedKVs returns keys of kvs in order and the according values.
+func sortedKVs(kvs map[string]interface{}) ([]string, []interface{}) {
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = kvs[k]
+	}
+	return keys, values
+}
+
+func TestSlimTrie_cursor(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 20; seed++ {
+
+		rng := rand.New(rand.NewSource(seed))
+		st, kvs := RandomTrie(rng, 200, 8)
+		if len(kvs) == 0 {
+			continue
+		}
+		keys, values := sortedKVs(kvs)
+
+		c := st.newCursor(st.leftMost(0, nil))
+		ta.False(c.prev())
+
+		for i := range keys {
+			ta.Equal(st.GetID(keys[i]), c.leaf(), "seed %d %d-th", seed, i)
+			ta.Equal(values[i], st.getLeaf(c.leaf()))
+
+			// a new cursor at the same leaf builds the same path
+			ta.Equal(c.path, st.newCursor(c.leaf()).path)
+
+			ta.Equal(i < len(keys)-1, c.next())
+		}
+
+		for i := len(keys) - 1; i >= 0; i-- {
+			ta.Equal(st.GetID(keys[i]), c.leaf(), "seed %d %d-th", seed, i)
+			ta.Equal(i > 0, c.prev())
+		}
+	}
+
+	t.Run("20kvl10", func(t *testing.T) {
+		keys := getKeys("20kvl10")
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)))
+		ta.NoError(err)
+
+		c := st.newCursor(st.leftMost(0, nil))
+		for i := range keys {
+			ta.Equal(st.GetID(keys[i]), c.leaf(), "%d-th", i)
+			ta.Equal(i < len(keys)-1, c.next())
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, []string{"a"}, []int32{1})
+		ta.NoError(err)
+
+		c := st.newCursor(0)
+		ta.Equal([]int32{0}, c.path)
+		ta.False(c.next())
+		ta.False(c.prev())
+	})
+}
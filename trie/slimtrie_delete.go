@@ -0,0 +1,53 @@
+package trie
+
+import "github.com/openacid/low/bitmap"
+
+// Delete marks the leaf of key as deleted and returns true.
+// It returns false if key is not found or is already deleted.
+//
+// The structure of the trie is not changed: Delete only records the node id
+// of the leaf in a bitmap, which is marshaled along with the trie.
+// Get(), GetID(), Has() and other methods looking up an exact key do not find
+// a deleted key, nor do Route(), LongestPrefix() and PathValues() report it
+// as a prefix.
+// Methods walking the trie, such as Search(), RangeGet(), scanning,
+// iteration and counting methods such as Len(), still see a deleted leaf.
+// WithBatch() drops deleted keys, i.e., it rebuilds a trie without them.
+//
+// Delete requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+// Otherwise a key not stored could be a false positive of a stored key, which
+// is then deleted.
+//
+// Delete modifies the storage shared with a shallow clone, and must not be
+// called concurrently with any other method.
+//
+// Since 0.5.12
+func (st *SlimTrie) Delete(key string) bool {
+
+	id := st.GetID(key)
+	if id == -1 {
+		return false
+	}
+
+	ns := st.inner
+	if ns.DeletedBM == nil {
+		total := st.levels[len(st.levels)-1].total
+		ns.DeletedBM = &Bitmap{Words: make([]uint64, (total+63)>>6)}
+	}
+
+	ns.DeletedBM.Words[id>>6] |= bitmap.Bit[id&63]
+	return true
+}
+
+// isDeleted returns true if the leaf nodeID is deleted by Delete().
+//
+// Since 0.5.12
+func (st *SlimTrie) isDeleted(nodeID int32) bool {
+	bm := st.inner.DeletedBM
+	if bm == nil {
+		return false
+	}
+
+	i := int(nodeID >> 6)
+	return i < len(bm.Words) && bm.Words[i]&bitmap.Bit[nodeID&63] != 0
+}
@@ -0,0 +1,87 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Delete(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "ab", "abc", "abd", "b", "bc"}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	ta.False(st.Delete("x"))
+	ta.False(st.Delete("abcd"))
+	ta.Nil(st.inner.DeletedBM)
+
+	ta.True(st.Delete("ab"))
+	ta.False(st.Delete("ab"), "already deleted")
+	ta.True(st.Delete("bc"))
+
+	deleted := map[string]bool{"ab": true, "bc": true}
+
+	test := func(st *SlimTrie) {
+		for i, k := range keys {
+			v, found := st.Get(k)
+			if deleted[k] {
+				ta.False(found, "%d-th key %q", i, k)
+				ta.Nil(v)
+				ta.Equal(int32(-1), st.GetID(k))
+				ta.False(st.Has(k))
+			} else {
+				ta.True(found, "%d-th key %q", i, k)
+				ta.Equal(values[i], v, "%d-th key %q", i, k)
+			}
+		}
+
+		// the structure is unchanged
+		ta.Equal(len(keys), st.Len())
+	}
+
+	test(st)
+
+	t.Run("marshal", func(t *testing.T) {
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+		test(st2)
+
+		ta.True(st2.Delete("a"))
+		_, found := st.Get("a")
+		ta.True(found, "not affect the original")
+	})
+
+	t.Run("withBatch", func(t *testing.T) {
+		st2, err := st.WithBatch([]string{"bc", "c"}, []interface{}{int32(10), int32(11)})
+		ta.NoError(err)
+
+		m, err := st2.ToMap()
+		ta.NoError(err)
+		ta.Equal(map[string]interface{}{
+			"a":   int32(0),
+			"abc": int32(2),
+			"abd": int32(3),
+			"b":   int32(4),
+			"bc":  int32(10),
+			"c":   int32(11),
+		}, m)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ta.False((&SlimTrie{}).Delete(""))
+
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.False(st.Delete(""))
+	})
+}
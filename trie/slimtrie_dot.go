@@ -0,0 +1,97 @@
+package trie
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openacid/low/bitmap"
+	"github.com/openacid/low/bitstr"
+	"github.com/openacid/low/bmtree"
+)
+
+// DOT returns a GraphViz digraph of SlimTrie, for debugging, e.g., rendered
+// with `dot -Tsvg`.
+//
+// An inner node is labeled with its node id, the size in bit of its labels,
+// the labels and the length in bit of its prefix, followed by the prefix bits
+// if the prefix is stored.
+// A leaf node is labeled with its node id, its leaf index, its leaf prefix if
+// there is one and its value if values are stored.
+// An edge is annotated with the 4-bit or 8-bit label of a branch.
+// An empty label is the branch of a key that ends at the parent node.
+//
+// Since 0.5.12
+func (st *SlimTrie) DOT() string {
+
+	b := &strings.Builder{}
+	b.WriteString("digraph slimtrie {\n")
+
+	if st.inner.GetNodeTypeBM() == nil {
+		b.WriteString("}\n")
+		return b.String()
+	}
+
+	ns := st.inner
+	total := st.levels[len(st.levels)-1].total
+	withValue := st.getLeaves() != nil
+
+	qr := &querySession{}
+	emp := querySession{}
+
+	for id := int32(0); id < total; id++ {
+
+		*qr = emp
+		st.getNode(id, qr)
+
+		if qr.isInner == 0 {
+			label := fmt.Sprintf("#%03d\nleaf %d", id, qr.ithLeaf)
+			if qr.hasLeafPrefix {
+				label += fmt.Sprintf("\nprefix %q", qr.leafPrefix)
+			}
+			if withValue {
+				label += fmt.Sprintf("\n=%v", st.getIthLeaf(qr.ithLeaf))
+			}
+			fmt.Fprintf(b, "  n%d [shape=ellipse, label=%s];\n", id, strconv.Quote(label))
+			continue
+		}
+
+		labels := st.getLabels(qr)
+		strs := make([]string, len(labels))
+		for i, l := range labels {
+			strs[i] = bmtree.PathStr(l)
+		}
+
+		label := fmt.Sprintf("#%03d\n%d-bit: %s", id, qr.wordSize, strings.Join(strs, ","))
+		if qr.innerPrefixLen > 0 {
+			label += fmt.Sprintf("\n+%d", qr.innerPrefixLen)
+		}
+		if qr.hasInnerPrefix {
+			label += " " + bitStrString(qr.innerPrefix)
+		}
+		fmt.Fprintf(b, "  n%d [shape=box, label=%s];\n", id, strconv.Quote(label))
+
+		leftChildID, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+		for i, s := range strs {
+			fmt.Fprintf(b, "  n%d -> n%d [label=%s];\n", id, leftChildID+1+int32(i), strconv.Quote(s))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// bitStrString returns the payload bits of a bitstr, e.g., "0110" for the
+// first 4 bits of "a".
+// A stored inner prefix starts at the byte containing the first bit of a node,
+// thus it may include bits already checked by the ancestors.
+//
+// Since 0.5.12
+func bitStrString(bs []byte) string {
+	n := bitstr.Len(bs)
+	s := &strings.Builder{}
+	for i := int32(0); i < n; i++ {
+		s.WriteByte('0' + bs[i>>3]>>uint(7-i&7)&1)
+	}
+	return s.String()
+}
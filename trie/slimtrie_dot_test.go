@@ -0,0 +1,65 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_DOT(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"ab", "abc", "b"}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	want := trim(`
+digraph slimtrie {
+  n0 [shape=box, label="#000\n4-bit: 0001,0010\n+4 0110"];
+  n0 -> n1 [label="0001"];
+  n0 -> n2 [label="0010"];
+  n1 [shape=box, label="#001\n4-bit: ,0110\n+8 01100010"];
+  n1 -> n3 [label=""];
+  n1 -> n4 [label="0110"];
+  n2 [shape=ellipse, label="#002\nleaf 0\n=2"];
+  n3 [shape=ellipse, label="#003\nleaf 1\n=0"];
+  n4 [shape=ellipse, label="#004\nleaf 2\nprefix \"c\"\n=1"];
+}
+`)
+	ta.Equal(want+"\n", st.DOT())
+
+	t.Run("noValue", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, keys, nil)
+		ta.NoError(err)
+
+		want := trim(`
+digraph slimtrie {
+  n0 [shape=box, label="#000\n4-bit: 0001,0010\n+4"];
+  n0 -> n1 [label="0001"];
+  n0 -> n2 [label="0010"];
+  n1 [shape=box, label="#001\n4-bit: ,0110\n+8"];
+  n1 -> n3 [label=""];
+  n1 -> n4 [label="0110"];
+  n2 [shape=ellipse, label="#002\nleaf 0"];
+  n3 [shape=ellipse, label="#003\nleaf 1"];
+  n4 [shape=ellipse, label="#004\nleaf 2"];
+}
+`)
+		ta.Equal(want+"\n", st.DOT())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.Equal("digraph slimtrie {\n}\n", st.DOT())
+		ta.Equal("digraph slimtrie {\n}\n", (&SlimTrie{}).DOT())
+	})
+}
@@ -0,0 +1,111 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_IsEmpty(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	empty, err := NewSlimTrie(encode.I32{}, nil, []int32{}, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+	ta.True(empty.IsEmpty())
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true), WithFoldedIndex: Bool(true)})
+	ta.NoError(err)
+	ta.False(st.IsEmpty())
+
+	st.BuildRangeIndex()
+	st.Reset()
+	ta.True(st.IsEmpty())
+
+	// queries on an empty and a Reset() SlimTrie returns the same.
+	queries := map[string]func(s *SlimTrie) []interface{}{
+		"Get":        func(s *SlimTrie) []interface{} { v, ok := s.Get(keys[1]); return []interface{}{v, ok} },
+		"ExactGet":   func(s *SlimTrie) []interface{} { v, ok := s.ExactGet(keys[1]); return []interface{}{v, ok} },
+		"GetID":      func(s *SlimTrie) []interface{} { return []interface{}{s.GetID(keys[1])} },
+		"Has":        func(s *SlimTrie) []interface{} { return []interface{}{s.Has(keys[1])} },
+		"Len":        func(s *SlimTrie) []interface{} { return []interface{}{s.Len()} },
+		"GetFolded":  func(s *SlimTrie) []interface{} { v, ok := s.GetFolded(keys[1]); return []interface{}{v, ok} },
+		"RangeGet":   func(s *SlimTrie) []interface{} { v, ok := s.RangeGet(keys[1]); return []interface{}{v, ok} },
+		"Search":     func(s *SlimTrie) []interface{} { l, e, r := s.Search(keys[1]); return []interface{}{l, e, r} },
+		"Children":   func(s *SlimTrie) []interface{} { return []interface{}{s.Children(0)} },
+		"NearestN":   func(s *SlimTrie) []interface{} { return []interface{}{s.NearestN(keys[1], 3)} },
+		"PathValues": func(s *SlimTrie) []interface{} { return []interface{}{s.PathValues(keys[1])} },
+		"PrefixCount": func(s *SlimTrie) []interface{} {
+			return []interface{}{s.PrefixCount(""), s.PrefixCount(keys[1])}
+		},
+		"CountRange": func(s *SlimTrie) []interface{} { return []interface{}{s.CountRange(keys[1], keys[5])} },
+		"GetLeafIndex": func(s *SlimTrie) []interface{} {
+			i, ok := s.GetLeafIndex(keys[1])
+			return []interface{}{i, ok}
+		},
+		"LongestPrefix": func(s *SlimTrie) []interface{} {
+			l, v, ok := s.LongestPrefix(keys[1])
+			return []interface{}{l, v, ok}
+		},
+		"RangeGetWithBounds": func(s *SlimTrie) []interface{} {
+			a, b, v, ok := s.RangeGetWithBounds(keys[1])
+			return []interface{}{a, b, v, ok}
+		},
+		"PartialGetID": func(s *SlimTrie) []interface{} {
+			id, n := s.PartialGetID(keys[1])
+			return []interface{}{id, n}
+		},
+		"Iterate": func(s *SlimTrie) []interface{} {
+			ks, _ := collectIter(s.Iterate())
+			rks, _ := collectIter(s.IterateReverse())
+			return []interface{}{ks, rks}
+		},
+		"ScanRange": func(s *SlimTrie) []interface{} {
+			ks, _ := collectIter(s.ScanRange(keys[1], keys[5]))
+			return []interface{}{ks}
+		},
+		"WalkPrefix": func(s *SlimTrie) []interface{} {
+			ks, _ := collectIter(s.WalkPrefix(keys[1][:1]))
+			return []interface{}{ks}
+		},
+		"ScanFrom": func(s *SlimTrie) []interface{} {
+			var n int
+			s.ScanFrom(keys[1], true, true, func(k, v []byte) bool { n++; return true })
+			return []interface{}{n}
+		},
+		"Walk": func(s *SlimTrie) []interface{} {
+			var n int
+			s.Walk(func(int32, bool, int32) bool { n++; return true })
+			return []interface{}{n}
+		},
+		"ToMap": func(s *SlimTrie) []interface{} {
+			m, err := s.ToMap()
+			return []interface{}{m, err}
+		},
+	}
+
+	for name, q := range queries {
+		var want, got, zero []interface{}
+		ta.NotPanics(func() { want = q(empty) }, "empty: %s", name)
+		ta.NotPanics(func() { got = q(st) }, "reset: %s", name)
+		ta.NotPanics(func() { zero = q(&SlimTrie{}) }, "zero value: %s", name)
+		ta.Equal(want, got, "%s", name)
+		ta.Equal(want, zero, "%s", name)
+	}
+
+	t.Run("reuse", func(t *testing.T) {
+		ta := require.New(t)
+
+		// A Reset() SlimTrie loads new data.
+		src, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+
+		ta.NoError(st.Unmarshal(mustMarshal(src)))
+		ta.False(st.IsEmpty())
+		testPresentKeysGet(t, st, keys, values)
+	})
+}
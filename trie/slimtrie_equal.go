@@ -0,0 +1,103 @@
+package trie
+
+import "bytes"
+
+// Equal returns true if st and other have the same structure: node bitmaps,
+// label bitmaps, prefixes, leaves, the folded index, the key collation and
+// the key normalizer.
+//
+// Fields are compared one by one instead of comparing marshaled bytes, thus a
+// SlimTrie converted from data of an older version equals the same SlimTrie
+// created by this version.
+// Bitmap indexes are not compared since they are rebuilt from the bitmaps,
+// and neither are the recorded build options, the build time, the value
+// type or the recorded key length, which data of an older version does not
+// have.
+//
+// Leaves not yet loaded by a SlimTrie opened with OpenSplit() or
+// UnmarshalIndexOnly() are loaded, and it returns false if loading fails.
+//
+// Since 0.5.12
+func (st *SlimTrie) Equal(other *SlimTrie) bool {
+
+	if st == other {
+		return true
+	}
+	if st == nil || other == nil {
+		return false
+	}
+
+	a, err := st.fullInner()
+	if err != nil {
+		return false
+	}
+	b, err := other.fullInner()
+	if err != nil {
+		return false
+	}
+
+	return slimEqual(a, b)
+}
+
+func slimEqual(a, b *Slim) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.BigInnerCnt == b.BigInnerCnt &&
+		a.ShortSize == b.ShortSize &&
+		bitmapEqual(a.NodeTypeBM, b.NodeTypeBM) &&
+		bitmapEqual(a.Inners, b.Inners) &&
+		bitmapEqual(a.ShortBM, b.ShortBM) &&
+		uint32sEqual(a.ShortTable, b.ShortTable) &&
+		vlenArrayEqual(a.InnerPrefixes, b.InnerPrefixes) &&
+		vlenArrayEqual(a.LeafPrefixes, b.LeafPrefixes) &&
+		vlenArrayEqual(a.Leaves, b.Leaves) &&
+		bitmapEqual(a.DeletedBM, b.DeletedBM) &&
+		a.Collation == b.Collation &&
+		a.KeyNormalizer == b.KeyNormalizer &&
+		slimEqual(a.Folded, b.Folded)
+}
+
+// bitmapEqual compares only the words, and a nil bitmap equals an empty one.
+func bitmapEqual(a, b *Bitmap) bool {
+	aw, bw := a.GetWords(), b.GetWords()
+	if len(aw) != len(bw) {
+		return false
+	}
+	for i := range aw {
+		if aw[i] != bw[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func vlenArrayEqual(a, b *VLenArray) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.N == b.N &&
+		a.EltCnt == b.EltCnt &&
+		bitmapEqual(a.PresenceBM, b.PresenceBM) &&
+		bitmapEqual(a.PositionBM, b.PositionBM) &&
+		a.FixedSize == b.FixedSize &&
+		bytes.Equal(a.Bytes, b.Bytes) &&
+		a.BlockSize == b.BlockSize &&
+		uint32sEqual(a.BlockOffsets, b.BlockOffsets) &&
+		bitmapEqual(a.ExceptionBM, b.ExceptionBM) &&
+		uint32sEqual(a.ExceptionOffsets, b.ExceptionOffsets)
+}
+
+func uint32sEqual(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
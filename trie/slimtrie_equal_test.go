@@ -0,0 +1,133 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/low/vers"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Equal(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	t.Run("self", func(t *testing.T) {
+		ta := require.New(t)
+
+		ta.True(st.Equal(st))
+		ta.True(st.Equal(st.Clone()))
+		ta.True(st.Equal(st.ShallowClone()))
+
+		var n *SlimTrie
+		ta.True(n.Equal(nil))
+		ta.False(st.Equal(nil))
+		ta.False(n.Equal(st))
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		ta := require.New(t)
+
+		for _, b := range [][]byte{mustMarshal(st), mustMarshalCanonical(st)} {
+			st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st2.Unmarshal(b))
+
+			ta.True(st.Equal(st2))
+			ta.True(st2.Equal(st))
+		}
+	})
+
+	t.Run("differs", func(t *testing.T) {
+		ta := require.New(t)
+
+		vs := makeI32s(len(keys))
+		vs[len(vs)-1]++
+		a, err := NewSlimTrie(encode.I32{}, keys, vs, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.False(st.Equal(a), "different value")
+
+		b, err := NewSlimTrie(encode.I32{}, keys[1:], values[1:], Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.False(st.Equal(b), "different keys")
+
+		c, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+		ta.False(st.Equal(c), "different prefixes")
+
+		d := st.Clone()
+		d.inner.Leaves.Bytes[0] ^= 1
+		ta.False(st.Equal(d), "different leaf bytes")
+	})
+
+	t.Run("indexes", func(t *testing.T) {
+		ta := require.New(t)
+
+		// Indexes are not compared.
+		c := st.Clone()
+		c.inner.NodeTypeBM.RankIndex = nil
+		c.inner.HasBuildOpt = false
+		c.inner.BuiltAt = 1
+		ta.True(st.Equal(c))
+	})
+}
+
+func TestSlimTrie_Equal_old_data(t *testing.T) {
+
+	testOldData(t,
+		func(t *testing.T,
+			dataSetName, dataOpt, ver string,
+			keys []string,
+			buf []byte) {
+
+			ta := require.New(t)
+
+			old, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(proto.Unmarshal(buf, old))
+
+			upgraded, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(upgraded.Unmarshal(mustMarshal(old)))
+			ta.True(old.Equal(upgraded))
+
+			if !vers.Check(ver, ">=0.5.10") {
+				return
+			}
+
+			opt := Opt{}
+			switch dataOpt {
+			case "innpref":
+				opt.InnerPrefix = Bool(true)
+			case "allpref":
+				opt.Complete = Bool(true)
+			}
+
+			st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)), opt)
+			ta.NoError(err)
+			ta.True(st.Equal(old), "converted from %s equals a new one", ver)
+		})
+}
+
+func mustMarshal(st *SlimTrie) []byte {
+	b, err := st.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func mustMarshalCanonical(st *SlimTrie) []byte {
+	b, err := st.MarshalCanonical()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,149 @@
+package trie
+
+import (
+	"sort"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// GetFolded looks up key case-insensitively in the folded index built with
+// Opt.WithFoldedIndex, and returns the value of the matching original key.
+//
+// Folding is ASCII only: 'A' to 'Z' are converted to 'a' to 'z', and all other
+// bytes, including those of multi-byte UTF-8 characters, are compared as is.
+//
+// If several original keys fold to the same key, e.g., "Abc" and "abc", the
+// first one in byte order, "Abc", is found.
+//
+// The folded index is built with the same options as the exact one, thus it
+// has false positive just like Get() does, unless Opt.Complete is set.
+//
+// If the SlimTrie is built without Opt.WithFoldedIndex, it always returns nil
+// and false.
+//
+// Since 0.5.12
+func (st *SlimTrie) GetFolded(key string) (interface{}, bool) {
+
+	if st.folded == nil {
+		return nil, false
+	}
+
+	v, found := st.folded.Get(st.terminate(foldKey(key)))
+	if !found {
+		return nil, false
+	}
+
+	bs, ok := st.getIthLeafBytes(v.(int32))
+	if !ok {
+		return nil, false
+	}
+	return st.decodeLeaf(bs), true
+}
+
+// HasFoldedIndex returns true if the SlimTrie is built with
+// Opt.WithFoldedIndex.
+//
+// Since 0.5.12
+func (st *SlimTrie) HasFoldedIndex() bool {
+	return st.folded != nil
+}
+
+// initFolded creates the companion SlimTrie of the folded index in inner.
+//
+// Since 0.5.12
+func (st *SlimTrie) initFolded() error {
+	st.folded = nil
+	if st.inner.Folded == nil {
+		return nil
+	}
+
+	st.folded = &SlimTrie{
+		inner:   st.inner.Folded,
+		encoder: encode.I32{},
+	}
+	return st.folded.init()
+}
+
+// buildFolded builds the folded index of keys into ns.Folded, if
+// opt.WithFoldedIndex is set.
+// A leaf value in the folded index is the leaf index in ns of the original
+// key.
+//
+// Since 0.5.12
+func buildFolded(ns *Slim, keys []string, opt *Opt) error {
+
+	if !*opt.WithFoldedIndex || len(keys) == 0 {
+		return nil
+	}
+
+	exact := &SlimTrie{inner: ns}
+	err := exact.init()
+	if err != nil {
+		return err
+	}
+
+	type foldedKey struct {
+		key     string
+		leafIdx int32
+	}
+
+	fks := make([]foldedKey, 0, len(keys))
+	for _, k := range keys {
+		id := exact.GetID(k)
+		if id == -1 {
+			continue
+		}
+		leafIdx, _ := exact.getLeafIndex(id)
+		fks = append(fks, foldedKey{foldKey(k), leafIdx})
+	}
+
+	// keys are sorted, a stable sort keeps the first original key at the
+	// front of those folded to the same key.
+	sort.SliceStable(fks, func(i, j int) bool {
+		return fks[i].key < fks[j].key
+	})
+
+	fkeys := make([]string, 0, len(fks))
+	fvals := make([][]byte, 0, len(fks))
+	e := encode.I32{}
+	for i, fk := range fks {
+		if i > 0 && fk.key == fks[i-1].key {
+			continue
+		}
+		fkeys = append(fkeys, fk.key)
+		fvals = append(fvals, e.Encode(fk.leafIdx))
+	}
+
+	fopt := *opt
+	fopt.DedupValue = Bool(false)
+	fopt.SelfCheck = Bool(false)
+	fopt.WithFoldedIndex = Bool(false)
+	fopt.ValueType = ""
+
+	fns, err := newSlim(fkeys, fvals, &fopt, nil)
+	if err != nil {
+		return errors.WithMessage(err, "failed to build folded index")
+	}
+
+	ns.Folded = fns
+	return nil
+}
+
+// foldKey converts ASCII upper case letters in key to lower case.
+func foldKey(key string) string {
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= 'A' && c <= 'Z' {
+			bs := []byte(key)
+			for j := i; j < len(bs); j++ {
+				if bs[j] >= 'A' && bs[j] <= 'Z' {
+					bs[j] += 'a' - 'A'
+				}
+			}
+			return string(bs)
+		}
+	}
+	return key
+}
@@ -0,0 +1,116 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_GetFolded(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"ABC",
+		"Abd",
+		"Hello",
+		"abc",
+		"hello\xc3\x84",
+		"xyz",
+	}
+	values := []int32{0, 1, 2, 3, 4, 5}
+
+	for _, opt := range []Opt{
+		{Complete: Bool(true), WithFoldedIndex: Bool(true)},
+		{Complete: Bool(true), WithFoldedIndex: Bool(true), DedupValue: Bool(false)},
+	} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+		ta.True(st.HasFoldedIndex())
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+
+		var split bytes.Buffer
+		ta.NoError(st.MarshalSplit(&split))
+		st3, err := OpenSplit(bytes.NewReader(split.Bytes()), encode.I32{})
+		ta.NoError(err)
+
+		cases := []struct {
+			key   string
+			want  interface{}
+			found bool
+		}{
+			{"abc", int32(0), true},
+			{"ABC", int32(0), true},
+			{"aBd", int32(1), true},
+			{"HELLO", int32(2), true},
+			{"HELLO\xc3\x84", int32(4), true},
+			// non-ASCII bytes are not folded
+			{"HELLO\xc3\xa4", nil, false},
+			{"XYZ", int32(5), true},
+			{"xy", nil, false},
+			{"", nil, false},
+		}
+
+		for _, s := range []*SlimTrie{st, st2, st3, st.Clone(), st.ShallowClone()} {
+			for _, c := range cases {
+				v, found := s.GetFolded(c.key)
+				ta.Equal(c.found, found, "key: %q", c.key)
+				ta.Equal(c.want, v, "key: %q", c.key)
+			}
+
+			// exact queries are not affected
+			v, found := s.Get("abc")
+			ta.True(found)
+			ta.Equal(int32(3), v)
+			_, found = s.Get("ABd")
+			ta.False(found)
+		}
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		kch, vch := sendStreams(keys, values)
+		st, err := NewFromStreams(kch, vch, encode.I32{},
+			&Opt{Complete: Bool(true), WithFoldedIndex: Bool(true)})
+		ta.NoError(err)
+
+		v, found := st.GetFolded("aBD")
+		ta.True(found)
+		ta.Equal(int32(1), v)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+		ta.False(st.HasFoldedIndex())
+
+		v, found := st.GetFolded("ABC")
+		ta.False(found)
+		ta.Nil(v)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{WithFoldedIndex: Bool(true)})
+		ta.NoError(err)
+
+		_, found := st.GetFolded("a")
+		ta.False(found)
+	})
+}
+
+func TestFoldKey(t *testing.T) {
+
+	ta := require.New(t)
+
+	ta.Equal("", foldKey(""))
+	ta.Equal("abc", foldKey("abc"))
+	ta.Equal("abc-z@[", foldKey("aBC-Z@["))
+	ta.Equal("\xc3\x84", foldKey("\xc3\x84"))
+}
@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+package trie
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+)
+
+func FuzzUnmarshal(f *testing.F) {
+
+	keys := getKeys("20kvl10")[:1000]
+	vals := makeI32s(len(keys))
+
+	opts := []Opt{
+		{},
+		{Complete: Bool(true)},
+		{LeafBlockSize: 8},
+		{LeafExceptions: Bool(true)},
+		{BigThreshold: 1},
+		{WithFoldedIndex: Bool(true)},
+		{WithChecksum: Bool(true)},
+	}
+	for _, opt := range opts {
+		st, err := NewSlimTrie(encode.I32{}, keys, vals, opt)
+		if err != nil {
+			f.Fatal(err)
+		}
+		b, err := st.Marshal()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+
+	for _, fn := range []string{
+		"slimtrie-data-10vl5-0.5.9",
+		"slimtrie-data-10vl5-allpref-0.5.10",
+		"slimtrie-data-empty-0.5.9",
+	} {
+		b, err := ioutil.ReadFile("testdata/" + fn)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Unmarshal(b) == nil {
+			queryLoaded(st)
+		}
+	})
+}
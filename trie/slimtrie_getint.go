@@ -13,7 +13,17 @@ func (st *SlimTrie) GetI8(key string) (int8, bool) {
 
 	ith, _ := st.getLeafIndex(eqID)
 
-	v := int8(st.inner.Leaves.Bytes[ith])
+	ls, ok := st.loadLeaves()
+	if !ok {
+		return 0, false
+	}
+
+	b := ls.getFixed(ith, 1)
+	if b == nil {
+		return 0, true
+	}
+
+	v := int8(b[0])
 
 	return v, true
 }
@@ -30,9 +40,15 @@ func (st *SlimTrie) GetI16(key string) (int16, bool) {
 	}
 
 	ith, _ := st.getLeafIndex(eqID)
-	stIdx := ith << 1
+	ls, ok := st.loadLeaves()
+	if !ok {
+		return 0, false
+	}
 
-	b := st.inner.Leaves.Bytes[stIdx : stIdx+2]
+	b := ls.getFixed(ith, 2)
+	if b == nil {
+		return 0, true
+	}
 
 	v := int16(b[0]) | int16(b[1])<<8
 
@@ -51,9 +67,15 @@ func (st *SlimTrie) GetI32(key string) (int32, bool) {
 	}
 
 	ith, _ := st.getLeafIndex(eqID)
-	stIdx := ith << 2
+	ls, ok := st.loadLeaves()
+	if !ok {
+		return 0, false
+	}
 
-	b := st.inner.Leaves.Bytes[stIdx : stIdx+4]
+	b := ls.getFixed(ith, 4)
+	if b == nil {
+		return 0, true
+	}
 
 	v := int32(b[0]) | int32(b[1])<<8 | int32(b[2])<<16 | int32(b[3])<<24
 
@@ -72,9 +94,15 @@ func (st *SlimTrie) GetI64(key string) (int64, bool) {
 	}
 
 	ith, _ := st.getLeafIndex(eqID)
-	stIdx := ith << 3
+	ls, ok := st.loadLeaves()
+	if !ok {
+		return 0, false
+	}
 
-	b := st.inner.Leaves.Bytes[stIdx : stIdx+8]
+	b := ls.getFixed(ith, 8)
+	if b == nil {
+		return 0, true
+	}
 
 	v := int64(b[0]) | int64(b[1])<<8 | int64(b[2])<<16 | int64(b[3])<<24 | int64(b[4])<<32 | int64(b[5])<<40 | int64(b[6])<<48 | int64(b[7])<<56
 
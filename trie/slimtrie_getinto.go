@@ -0,0 +1,49 @@
+package trie
+
+import (
+	"reflect"
+
+	"github.com/openacid/slim/encode"
+)
+
+// GetInto is same as Get() except that it stores the value into dst, which must
+// be a pointer to the value type, and returns only whether the key is found.
+//
+// If the encoder implements encode.DecoderInto, the value is decoded directly
+// into dst, without allocating a new value and boxing it into an interface{}.
+// Otherwise the value is decoded with Decode() and then copied to dst.
+//
+// If SlimTrie does not store values, dst is not modified.
+//
+// Since 0.5.12
+func (st *SlimTrie) GetInto(key string, dst interface{}) bool {
+
+	eqID := st.GetID(key)
+
+	if eqID == -1 {
+		return false
+	}
+
+	if !st.hasLeaves() {
+		return true
+	}
+
+	leafI, _ := st.getLeafIndex(eqID)
+	bs, ok := st.getIthLeafBytes(leafI)
+	if !ok {
+		return false
+	}
+	if len(bs) == 0 {
+		// absent value
+		return true
+	}
+
+	if d, ok := st.encoder.(encode.DecoderInto); ok {
+		d.DecodeInto(bs, dst)
+		return true
+	}
+
+	_, v := st.encoder.Decode(bs)
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(v))
+	return true
+}
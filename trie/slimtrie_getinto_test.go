@@ -0,0 +1,106 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+type getIntoValue struct {
+	A, B, C, D int64
+	E          [8]int64
+}
+
+func TestSlimTrie_GetInto(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+
+	t.Run("decodeInto", func(t *testing.T) {
+		values := makeI32s(len(keys))
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		for i, k := range keys {
+			var v int32
+			ta.True(st.GetInto(k, &v))
+			ta.Equal(values[i], v)
+		}
+
+		v := int32(-1)
+		ta.False(st.GetInto("abcx", &v))
+		ta.Equal(int32(-1), v)
+	})
+
+	t.Run("typeEncoder", func(t *testing.T) {
+		e, err := encode.NewTypeEncoder(getIntoValue{})
+		ta.NoError(err)
+
+		values := make([]getIntoValue, len(keys))
+		for i := range values {
+			values[i] = getIntoValue{A: int64(i), E: [8]int64{7: int64(i)}}
+		}
+
+		st, err := NewSlimTrie(e, keys, values)
+		ta.NoError(err)
+
+		for i, k := range keys {
+			var v getIntoValue
+			ta.True(st.GetInto(k, &v))
+			ta.Equal(values[i], v)
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		values := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+		st, err := NewSlimTrie(encode.String16{}, keys, values)
+		ta.NoError(err)
+
+		for i, k := range keys {
+			var v string
+			ta.True(st.GetInto(k, &v))
+			ta.Equal(values[i], v)
+		}
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil)
+		ta.NoError(err)
+
+		v := int32(-1)
+		ta.True(st.GetInto("abc", &v))
+		ta.Equal(int32(-1), v)
+	})
+}
+
+func BenchmarkSlimTrie_GetInto(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := make([]getIntoValue, len(keys))
+	for i := range values {
+		values[i] = getIntoValue{A: int64(i)}
+	}
+
+	e, _ := encode.NewTypeEncoder(getIntoValue{})
+	st, _ := NewSlimTrie(e, keys, values)
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		var v getIntoValue
+		for i := 0; i < b.N; i++ {
+			x, _ := st.Get(keys[i%len(keys)])
+			v = x.(getIntoValue)
+		}
+		_ = v
+	})
+
+	b.Run("GetInto", func(b *testing.B) {
+		b.ReportAllocs()
+		var v getIntoValue
+		for i := 0; i < b.N; i++ {
+			st.GetInto(keys[i%len(keys)], &v)
+		}
+	})
+}
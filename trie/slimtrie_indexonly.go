@@ -0,0 +1,312 @@
+package trie
+
+import (
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/errors"
+	"github.com/openacid/low/pbcmpl"
+	"github.com/openacid/low/vers"
+	"github.com/openacid/slim/encode"
+)
+
+const (
+	// the protobuf field number of Slim.Leaves
+	slimLeavesField = 60
+	// the protobuf field number of VLenArray.Bytes
+	vlenArrayBytesField = 30
+)
+
+// UnmarshalIndexOnly loads a SlimTrie from the first size bytes of r, which
+// are written by Marshal(), except the content of leaves, which is kept in r.
+//
+// The structure, i.e., the bitmaps, prefixes and the indexes to locate a
+// leaf, is read into memory, while the content of a leaf is read from r only
+// when it is accessed by Get(), GetInto(), Search() or RangeGet().
+// Methods that read the leaves of other keys or the leaves as a whole, such
+// as scanning, iteration, typed getters like GetI32(), Set(), Marshal() and
+// MappedBytes(), load the content of all leaves into memory once, the same
+// as a SlimTrie opened with OpenSplit() does, and after that no leaf is read
+// from r.
+//
+// Thus r must be kept readable while the SlimTrie is in use.
+// If reading r fails, a query method reports not found, see LoadLeaves().
+//
+// Only data of version 0.5.12 or the current version is supported, otherwise
+// it returns an ErrIncompatible error.
+// The checksum footer written with Opt.WithChecksum is not verified, since it
+// requires reading all the data.
+//
+// Since 0.5.12
+func (st *SlimTrie) UnmarshalIndexOnly(r io.ReaderAt, size int64) error {
+
+	st.inner = &Slim{}
+	st.lazyLeaves = nil
+
+	_, h, err := pbcmpl.ReadHeader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return errors.WithMessage(err, "failed to unmarshal header")
+	}
+
+	ver := h.GetVersion()
+	if !vers.Check(ver, "==0.5.12", "=="+slimtrieVersion) {
+		return errors.Wrapf(ErrIncompatible,
+			`version: "%s", compatible versions: "==0.5.12 || ==%s"`, ver, slimtrieVersion)
+	}
+
+	from := h.GetHeaderSize()
+	to := from + h.GetBodySize()
+	if to > size {
+		return errors.Wrapf(ErrCorrupt, "body ends at %d, beyond size %d", to, size)
+	}
+
+	// Copy every field except Leaves.Bytes.
+	meta := make([]byte, 0)
+	var leavesMeta []byte
+	var bytesFrom, bytesTo int64
+
+	wr := &wireReader{r: r, off: from, end: to}
+	for wr.off < wr.end {
+		f, err := wr.next()
+		if err != nil {
+			return err
+		}
+
+		if f.num != slimLeavesField {
+			meta, err = wr.appendField(meta, f)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		leavesMeta = make([]byte, 0)
+		lr := &wireReader{r: r, off: f.payload, end: f.to}
+		for lr.off < lr.end {
+			lf, err := lr.next()
+			if err != nil {
+				return err
+			}
+
+			if lf.num == vlenArrayBytesField {
+				bytesFrom, bytesTo = lf.payload, lf.to
+				continue
+			}
+
+			leavesMeta, err = lr.appendField(leavesMeta, lf)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = proto.Unmarshal(meta, st.inner)
+	if err != nil {
+		return errors.WithMessage(err, "failed to unmarshal inner")
+	}
+
+	if leavesMeta != nil {
+		ls := &VLenArray{}
+		err = proto.Unmarshal(leavesMeta, ls)
+		if err != nil {
+			return errors.WithMessage(err, "failed to unmarshal leaves")
+		}
+		st.inner.Leaves = ls
+	}
+
+	rebuildIndexes(st.inner)
+
+	if ls := st.inner.Leaves; ls != nil {
+		st.inner.Leaves = nil
+
+		d := &diskLeaves{r: r, from: bytesFrom, size: bytesTo - bytesFrom, leaves: ls}
+		st.lazyLeaves = &lazyLeaves{
+			load: d.load,
+			at:   d.get,
+			size: vlenArrayBytes(ls) + int(d.size),
+		}
+	}
+
+	if st.encoder == nil && st.inner.ValueType != "" {
+		st.encoder, _ = encode.Lookup(st.inner.ValueType)
+	}
+
+	err = st.loadCollation()
+	if err != nil {
+		return err
+	}
+
+	return st.init()
+}
+
+// diskLeaves reads the content of leaves from an io.ReaderAt.
+//
+// Since 0.5.12
+type diskLeaves struct {
+	r io.ReaderAt
+
+	// from is the offset of Leaves.Bytes in r and size is the length of it.
+	from int64
+	size int64
+
+	// leaves is Leaves without Bytes.
+	leaves *VLenArray
+}
+
+// load reads all leaves.
+func (d *diskLeaves) load() (*VLenArray, error) {
+	b, err := d.read(0, d.size)
+	if err != nil {
+		return nil, err
+	}
+
+	ls := *d.leaves
+	ls.Bytes = b
+	return &ls, nil
+}
+
+// get reads the ith leaf, the same as VLenArray.get() returns.
+func (d *diskLeaves) get(ith int32) ([]byte, error) {
+
+	va := d.leaves
+
+	ithElt, present := va.ithElt(ith)
+	if !present {
+		return []byte{}, nil
+	}
+
+	if va.BlockSize > 0 {
+		blockI := ithElt / va.BlockSize
+		to := d.size
+		if int(blockI)+1 < len(va.BlockOffsets) {
+			to = int64(va.BlockOffsets[blockI+1])
+		}
+		b, err := d.read(int64(va.BlockOffsets[blockI]), to)
+		if err != nil {
+			return nil, err
+		}
+		return va.eltInBlock(b, ithElt), nil
+	}
+
+	from, to := va.eltRange(ithElt)
+	return d.read(int64(from), int64(to))
+}
+
+// read reads the range [from, to) of Leaves.Bytes.
+func (d *diskLeaves) read(from, to int64) ([]byte, error) {
+
+	if from < 0 || from > to || to > d.size {
+		return nil, errors.Wrapf(ErrCorrupt, "leaf range [%d, %d) out of %d bytes", from, to, d.size)
+	}
+
+	b := make([]byte, to-from)
+	_, err := d.r.ReadAt(b, d.from+from)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read leaves")
+	}
+	return b, nil
+}
+
+// wireReader reads protobuf fields in the range [off, end) of r, one by one,
+// without reading the payload of a field unless it is asked to.
+//
+// Since 0.5.12
+type wireReader struct {
+	r   io.ReaderAt
+	off int64
+	end int64
+}
+
+// wireField is the location of an encoded protobuf field.
+type wireField struct {
+	num int32
+
+	// from is where the field starts, i.e., the tag.
+	from int64
+
+	// payload is where the value starts, after the tag and the length of a
+	// length-delimited field.
+	payload int64
+
+	// to is where the field ends.
+	to int64
+}
+
+// next locates the next field and moves to the end of it.
+func (wr *wireReader) next() (wireField, error) {
+
+	f := wireField{from: wr.off}
+
+	tag, err := wr.varint()
+	if err != nil {
+		return f, err
+	}
+	f.num = int32(tag >> 3)
+	f.payload = wr.off
+
+	switch tag & 7 {
+	case proto.WireVarint:
+		_, err = wr.varint()
+		if err != nil {
+			return f, err
+		}
+	case proto.WireFixed64:
+		wr.off += 8
+	case proto.WireFixed32:
+		wr.off += 4
+	case proto.WireBytes:
+		n, err := wr.varint()
+		if err != nil {
+			return f, err
+		}
+		f.payload = wr.off
+		wr.off += int64(n)
+	default:
+		return f, errors.Wrapf(ErrCorrupt, "unsupported wire type %d at %d", tag&7, f.from)
+	}
+
+	if wr.off > wr.end || wr.off < f.payload {
+		return f, errors.Wrapf(ErrCorrupt, "field %d at %d exceeds %d", f.num, f.from, wr.end)
+	}
+
+	f.to = wr.off
+	return f, nil
+}
+
+// appendField appends the encoded field f to buf.
+func (wr *wireReader) appendField(buf []byte, f wireField) ([]byte, error) {
+
+	n := len(buf)
+	buf = append(buf, make([]byte, f.to-f.from)...)
+
+	_, err := wr.r.ReadAt(buf[n:], f.from)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read field %d", f.num)
+	}
+	return buf, nil
+}
+
+func (wr *wireReader) varint() (uint64, error) {
+
+	var b [1]byte
+	var x uint64
+
+	for shift := uint(0); shift < 64; shift += 7 {
+		if wr.off >= wr.end {
+			return 0, errors.Wrapf(ErrCorrupt, "varint at %d exceeds %d", wr.off, wr.end)
+		}
+
+		_, err := wr.r.ReadAt(b[:], wr.off)
+		if err != nil {
+			return 0, errors.WithMessage(err, "failed to read varint")
+		}
+		wr.off++
+
+		x |= uint64(b[0]&0x7f) << shift
+		if b[0] < 0x80 {
+			return x, nil
+		}
+	}
+
+	return 0, errors.Wrapf(ErrCorrupt, "varint overflow at %d", wr.off)
+}
@@ -0,0 +1,248 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// countReaderAt counts the bytes read.
+type countReaderAt struct {
+	r *bytes.Reader
+	n int64
+}
+
+func (r *countReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.r.ReadAt(p, off)
+	r.n += int64(n)
+	return n, err
+}
+
+// failReaderAt fails every read once fail is set.
+type failReaderAt struct {
+	r    *bytes.Reader
+	fail bool
+}
+
+func (r *failReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if r.fail {
+		return 0, errors.New("read error")
+	}
+	return r.r.ReadAt(p, off)
+}
+
+func TestSlimTrie_UnmarshalIndexOnly(t *testing.T) {
+
+	keys := getKeys("20kvl10")
+	strs := makeOutlierStrs(len(keys), 100)
+	values := make([]interface{}, len(keys))
+	for i := range keys {
+		if i%7 != 0 {
+			values[i] = strs[i]
+		}
+	}
+
+	cases := []struct {
+		name string
+		enc  encode.Encoder
+		vals interface{}
+		opt  Opt
+	}{
+		{"fixed", encode.I32{}, makeI32s(len(keys)), Opt{}},
+		{"varlen", encode.String16{}, values, Opt{}},
+		{"block", encode.String16{}, values, Opt{LeafBlockSize: 16}},
+		{"exceptions", encode.String16{}, values, Opt{LeafExceptions: Bool(true)}},
+		{"complete", encode.String16{}, values, Opt{Complete: Bool(true)}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			ta := require.New(t)
+
+			st, err := NewSlimTrie(c.enc, keys, c.vals, c.opt)
+			ta.NoError(err)
+
+			buf, err := st.Marshal()
+			ta.NoError(err)
+
+			r := &countReaderAt{r: bytes.NewReader(buf)}
+			st2, err := NewSlimTrie(c.enc, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st2.UnmarshalIndexOnly(r, int64(len(buf))))
+
+			// The header is read twice.
+			leavesSize := int64(len(st.inner.Leaves.Bytes))
+			ta.True(r.n < int64(len(buf))-leavesSize+100,
+				"read: %d, total: %d, leaves: %d", r.n, len(buf), leavesSize)
+
+			loaded := r.n
+			for i, k := range keys {
+				v, found := st2.Get(k)
+				ta.True(found, "%d-th key %q", i, k)
+				want, _ := st.Get(k)
+				ta.Equal(want, v, "%d-th key %q", i, k)
+			}
+			// only the leaves are read, one by one, or a block for every leaf.
+			perLeaf := int64(1)
+			if c.opt.LeafBlockSize > 0 {
+				perLeaf = int64(c.opt.LeafBlockSize)
+			}
+			ta.True(r.n-loaded <= leavesSize*perLeaf*17/16, "read: %d, leaves: %d", r.n-loaded, leavesSize)
+
+			// load all leaves at once.
+			ta.Equal(st.LeafSizeHistogram(), st2.LeafSizeHistogram())
+
+			after := r.n
+			for _, k := range keys[:100] {
+				v, _ := st2.Get(k)
+				want, _ := st.Get(k)
+				ta.Equal(want, v, "key %q", k)
+			}
+			ta.Equal(after, r.n, "no more read after loading all leaves")
+
+			b1, err := st.Marshal()
+			ta.NoError(err)
+			b2, err := st2.Marshal()
+			ta.NoError(err)
+			ta.Equal(b1, b2)
+		})
+	}
+
+	t.Run("noValue", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(nil, keys, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(nil, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.UnmarshalIndexOnly(bytes.NewReader(buf), int64(len(buf))))
+		ta.Nil(st2.lazyLeaves)
+
+		for _, k := range keys {
+			v, found := st2.Get(k)
+			ta.True(found)
+			ta.Nil(v)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.UnmarshalIndexOnly(bytes.NewReader(buf), int64(len(buf))))
+
+		_, found := st2.Get("a")
+		ta.False(found)
+	})
+
+	t.Run("version", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)))
+		ta.NoError(err)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		cases := []struct {
+			input string
+			want  error
+		}{
+			{slimtrieVersion, nil},
+			{"0.5.12", nil},
+			{"0.5.11", ErrIncompatible},
+			{"0.5.14", ErrIncompatible},
+			{"1.0.1", ErrIncompatible},
+		}
+
+		for _, c := range cases {
+			b := append([]byte{}, buf...)
+			copy(b[:16], make([]byte, 16))
+			copy(b, c.input)
+
+			err = st2.UnmarshalIndexOnly(bytes.NewReader(b), int64(len(b)))
+			ta.Equal(c.want, errors.Cause(err), "version: %s", c.input)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)))
+		ta.NoError(err)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		for _, size := range []int{0, 10, len(buf) - 1} {
+			err = st2.UnmarshalIndexOnly(bytes.NewReader(buf), int64(size))
+			ta.Error(err, "size: %d", size)
+		}
+
+		// A broken length of a field
+		bad := append([]byte{}, buf...)
+		hsize := len(buf) - proto.Size(st.inner)
+		bad[hsize+1] = 0xff
+		err = st2.UnmarshalIndexOnly(bytes.NewReader(bad), int64(len(bad)))
+		ta.Equal(ErrCorrupt, errors.Cause(err))
+	})
+
+	t.Run("readError", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.String16{}, keys, values)
+		ta.NoError(err)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		r := &failReaderAt{r: bytes.NewReader(buf)}
+		st2, err := NewSlimTrie(encode.String16{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.UnmarshalIndexOnly(r, int64(len(buf))))
+
+		r.fail = true
+		ta.NotPanics(func() {
+			for i, k := range keys[:20] {
+				if values[i] == nil {
+					continue
+				}
+				ta.NotEqual(int32(-1), st2.GetID(k))
+
+				_, found := st2.Get(k)
+				ta.False(found, "key %q", k)
+
+				_, eqVal, _ := st2.Search(k)
+				ta.Nil(eqVal, "key %q", k)
+			}
+		})
+		ta.Error(st2.LoadLeaves())
+	})
+}
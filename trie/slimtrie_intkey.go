@@ -0,0 +1,37 @@
+package trie
+
+import "github.com/openacid/slim/encode"
+
+// NewFromUint64 creates a SlimTrie with integer keys.
+// Every key is encoded by encode.Uint64Key(), thus keys must be ascending
+// sorted in numeric order, and a query such as RangeGet() must use the
+// encoded key too, e.g.:
+//
+//	st.RangeGet(encode.Uint64Key(5))
+//
+// Other arguments are the same as NewSlimTrie().
+//
+// Since 0.5.12
+func NewFromUint64(e encode.Encoder, keys []uint64, values interface{}, opts ...Opt) (*SlimTrie, error) {
+
+	ks := make([]string, len(keys))
+	for i, k := range keys {
+		ks[i] = encode.Uint64Key(k)
+	}
+
+	return NewSlimTrie(e, ks, values, opts...)
+}
+
+// NewFromInt64 is the same as NewFromUint64() except keys are signed
+// integers encoded by encode.Int64Key().
+//
+// Since 0.5.12
+func NewFromInt64(e encode.Encoder, keys []int64, values interface{}, opts ...Opt) (*SlimTrie, error) {
+
+	ks := make([]string, len(keys))
+	for i, k := range keys {
+		ks[i] = encode.Int64Key(k)
+	}
+
+	return NewSlimTrie(e, ks, values, opts...)
+}
@@ -0,0 +1,90 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromUint64(t *testing.T) {
+
+	ta := require.New(t)
+
+	// range starts: [0, 10), [10, 256), [256, 1<<40), [1<<40, ...)
+	keys := []uint64{0, 10, 256, 1 << 40}
+	values := makeI32s(len(keys))
+
+	st, err := NewFromUint64(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for i, k := range keys {
+		v, found := st.Get(encode.Uint64Key(k))
+		ta.True(found)
+		ta.Equal(values[i], v)
+	}
+
+	cases := []struct {
+		key  uint64
+		want int32
+	}{
+		{0, 0},
+		{9, 0},
+		{10, 1},
+		{11, 1},
+		{255, 1},
+		{256, 2},
+		{1<<40 - 1, 2},
+		{1 << 40, 3},
+		{1<<64 - 1, 3},
+	}
+	for _, c := range cases {
+		v, found := st.RangeGet(encode.Uint64Key(c.key))
+		ta.True(found, "key: %d", c.key)
+		ta.Equal(c.want, v, "key: %d", c.key)
+	}
+
+	it := st.ScanRange(encode.Uint64Key(5), encode.Uint64Key(300))
+	var got []uint64
+	for k, _, ok := it.Next(); ok; k, _, ok = it.Next() {
+		u, err := encode.DecodeUint64Key(k)
+		ta.NoError(err)
+		got = append(got, u)
+	}
+	ta.Equal([]uint64{10, 256}, got)
+
+	t.Run("outOfOrder", func(t *testing.T) {
+		// 256 < 1000 but the decimal string "256" > "1000"
+		_, err := NewFromUint64(encode.I32{}, []uint64{1000, 256}, makeI32s(2))
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+	})
+}
+
+func TestNewFromInt64(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []int64{-1 << 63, -100, -1, 0, 5, 1<<63 - 1}
+	values := makeI32s(len(keys))
+
+	st, err := NewFromInt64(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for i, k := range keys {
+		v, found := st.Get(encode.Int64Key(k))
+		ta.True(found)
+		ta.Equal(values[i], v)
+	}
+
+	v, found := st.RangeGet(encode.Int64Key(-50))
+	ta.True(found)
+	ta.Equal(int32(1), v)
+
+	v, found = st.RangeGet(encode.Int64Key(3))
+	ta.True(found)
+	ta.Equal(int32(3), v)
+
+	_, err = NewFromInt64(encode.I32{}, []int64{0, -1}, makeI32s(2))
+	ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+}
@@ -0,0 +1,383 @@
+package trie
+
+import "strings"
+
+// Iterator yields keys and values of a SlimTrie in ascending key order, or
+// in descending key order.
+// It is created by SlimTrie.Iterate(), IterateReverse() and other scanning
+// methods.
+//
+// An Iterator is not safe for concurrent use, while multiple Iterators of
+// the same SlimTrie could be used concurrently.
+//
+// Since 0.5.12
+type Iterator struct {
+	st        *SlimTrie
+	next      NextRaw
+	withValue bool
+
+	// end is the greatest key to yield, if hasEnd is true.
+	end    string
+	hasEnd bool
+
+	// start is the smallest key to yield in a reverse iteration, if hasStart
+	// is true.
+	start    string
+	hasStart bool
+
+	// remain is the number of leaves left to visit, if hasRemain is true.
+	remain    int32
+	hasRemain bool
+
+	// lastOrdinal is the key ordinal of the last leaf to visit, if hasRemain
+	// is true.
+	lastOrdinal int32
+
+	// reverse is true if it yields keys in descending order.
+	reverse bool
+
+	// prefix is the prefix of keys to yield, if filter is true.
+	prefix string
+	filter bool
+}
+
+// Iterate returns an Iterator over all leaves in ascending key order, walking
+// the trie depth-first from the left-most leaf.
+//
+// A key is rebuilt from inner node prefixes, labels and the leaf prefix along
+// the path to a leaf.
+// Only with Opt{Complete: Bool(true)} a rebuilt key is exactly the stored key.
+// Otherwise the parts of a key SlimTrie does not store are absent from the
+// rebuilt key: e.g., without Opt.InnerPrefix, only the lengths of inner node
+// prefixes are stored and the prefixes are skipped; without Opt.LeafPrefix,
+// the key ends at the last label.
+// Such a partial key is not a stored key, and partial keys are not
+// necessarily ascending or distinct, while leaves are still iterated in the
+// order of the stored keys.
+//
+// Keys removed by Opt.DedupValue when creating are not iterated.
+//
+// Since 0.5.12
+func (st *SlimTrie) Iterate() *Iterator {
+
+	it := &Iterator{st: st}
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return it
+	}
+
+	path := make([]int32, 0)
+	if st.leftMost(0, &path) == -1 {
+		return it
+	}
+
+	it.withValue = st.getLeaves() != nil
+	it.next = st.newIter(path, false, it.withValue, false)
+
+	return it
+}
+
+// ScanRange returns an Iterator over leaves with keys in the range
+// [start, end], in ascending key order.
+// Both start and end are inclusive.
+// An empty start scans from the smallest key and an empty end scans to the
+// greatest key.
+// It is positioned at the first key >= start in one descent, and stops at the
+// first key > end.
+//
+// Keys removed by Opt.DedupValue when creating are not iterated.
+//
+// ScanRange requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (st *SlimTrie) ScanRange(start, end string) *Iterator {
+
+	it := &Iterator{st: st, end: end, hasEnd: end != ""}
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return it
+	}
+
+	if it.hasEnd && end < start {
+		return it
+	}
+
+	path, _ := st.getGEPath(start)
+	if len(path) == 0 {
+		return it
+	}
+
+	it.withValue = st.getLeaves() != nil
+	it.next = st.newIter(path, false, it.withValue, false)
+
+	return it
+}
+
+// IterateReverse returns an Iterator over all leaves in descending key order,
+// walking the trie depth-first from the right-most leaf, e.g., to list the N
+// greatest keys without iterating all of them.
+//
+// Keys are rebuilt the same way as Iterate() does, thus they are exactly the
+// stored keys only with Opt{Complete: Bool(true)}.
+//
+// Keys removed by Opt.DedupValue when creating are not iterated.
+//
+// Since 0.5.12
+func (st *SlimTrie) IterateReverse() *Iterator {
+
+	it := &Iterator{st: st, reverse: true}
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return it
+	}
+
+	path := make([]int32, 0)
+	if st.rightMost(0, &path) == -1 {
+		return it
+	}
+
+	it.reverse = true
+	it.withValue = st.getLeaves() != nil
+	it.next = st.newIter(path, false, it.withValue, true)
+
+	return it
+}
+
+// ScanRangeReverse is the same as ScanRange() except it iterates in
+// descending key order: from the greatest key <= end down to start.
+// Both start and end are inclusive.
+// An empty start scans to the smallest key and an empty end scans from the
+// greatest key.
+//
+// Keys removed by Opt.DedupValue when creating are not iterated.
+//
+// ScanRangeReverse requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (st *SlimTrie) ScanRangeReverse(start, end string) *Iterator {
+
+	it := &Iterator{st: st, start: start, hasStart: start != "", reverse: true}
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return it
+	}
+
+	if end != "" && end < start {
+		return it
+	}
+
+	var path []int32
+	if end == "" {
+		path = make([]int32, 0)
+		if st.rightMost(0, &path) == -1 {
+			return it
+		}
+	} else {
+		path, _ = st.getLEPath(end)
+		if len(path) == 0 {
+			return it
+		}
+	}
+
+	it.withValue = st.getLeaves() != nil
+	it.next = st.newIter(path, false, it.withValue, true)
+
+	return it
+}
+
+// WalkPrefix returns an Iterator over leaves with keys that start with prefix,
+// in ascending key order, e.g., to list completions of a prefix.
+// It descends to the node where prefix ends, the same way GetID() does, and
+// iterates all leaves in the subtree of this node.
+//
+// Keys are rebuilt the same way as Iterate() does, thus they are best-effort:
+// only with Opt{Complete: Bool(true)} a key is exactly a stored key, and
+// exactly the keys with prefix are iterated.
+// Otherwise the bits of prefix SlimTrie does not store can not be checked:
+// leaves of keys without prefix could be iterated, and keys could be partial.
+//
+// Keys removed by Opt.DedupValue when creating are not iterated.
+//
+// Since 0.5.12
+func (st *SlimTrie) WalkPrefix(prefix string) *Iterator {
+
+	it := &Iterator{st: st}
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return it
+	}
+
+	path := st.prefixPath(prefix)
+	if path == nil {
+		return it
+	}
+
+	root := path[len(path)-1]
+	first, last := st.LeafOrdinalRange(root)
+
+	path = path[:len(path)-1]
+	if st.leftMost(root, &path) == -1 {
+		return it
+	}
+
+	it.withValue = st.getLeaves() != nil
+	it.next = st.newIter(path, false, it.withValue, false)
+
+	it.remain = last - first + 1
+	it.hasRemain = true
+	it.lastOrdinal = last
+
+	it.prefix = prefix
+	it.filter = st.hasCompleteKeys()
+
+	return it
+}
+
+// prefixPath returns the node ids from the root to the node where prefix
+// ends, i.e., the root of the smallest subtree that contains all keys with
+// prefix.
+// It returns nil if no key has prefix.
+//
+// It stops at a node if prefix ends in its inner prefix or label, thus the
+// subtree may contain keys without prefix.
+//
+// Since 0.5.12
+func (st *SlimTrie) prefixPath(prefix string) []int32 {
+
+	l := int32(8 * len(prefix))
+
+	qr := &querySession{
+		keyBitLen: l,
+		key:       prefix,
+	}
+
+	path := make([]int32, 0)
+	id, i := int32(0), int32(0)
+
+	for {
+		path = append(path, id)
+
+		if i >= l {
+			break
+		}
+
+		st.getNode(id, qr)
+		if qr.isInner == 0 {
+			break
+		}
+
+		if qr.hasInnerPrefix {
+			end := i&(^7) + qr.innerPrefixLen
+			if end > l {
+				// the prefix of this node can not be fully compared.
+				break
+			}
+			if strCmpUpto(prefix[i>>3:], qr.innerPrefix) != 0 {
+				return nil
+			}
+			i = end
+		} else {
+			i += qr.innerPrefixLen
+		}
+
+		if i+qr.wordSize > l {
+			// prefix ends before or in the label to choose a branch.
+			break
+		}
+
+		lchID, has := st.getLeftChildID(qr, i)
+		if has == 0 {
+			return nil
+		}
+		id = lchID + 1
+		i += qr.wordSize
+	}
+
+	return path
+}
+
+// Seek moves the Iterator to the first key >= key, so that the next call to
+// Next() returns it, e.g., to skip keys in a merge-join of several SlimTries.
+// An Iterator in descending order, such as one created by IterateReverse(),
+// is moved to the greatest key <= key instead.
+//
+// It descends from the root the same way ScanRange() locates its start, thus
+// key could be less than the current key, and an exhausted Iterator is
+// revived by seeking to a key in range.
+// The range of the Iterator is kept: keys out of the range of ScanRange(),
+// ScanRangeReverse() or WalkPrefix() are still not yielded.
+//
+// Seek requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (it *Iterator) Seek(key string) {
+
+	st := it.st
+	it.next = nil
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return
+	}
+
+	var path []int32
+	if it.reverse {
+		path, _ = st.getLEPath(key)
+	} else {
+		if it.hasRemain && key < it.prefix {
+			key = it.prefix
+		}
+		path, _ = st.getGEPath(key)
+	}
+
+	if len(path) == 0 {
+		return
+	}
+
+	if it.hasRemain {
+		it.remain = it.lastOrdinal - st.keyOrdinal(path[len(path)-1]) + 1
+		if it.remain <= 0 {
+			return
+		}
+	}
+
+	it.next = st.newIter(path, false, it.withValue, it.reverse)
+}
+
+// Next returns the next key and its value, and true.
+// value is nil if SlimTrie does not store values.
+// It returns "", nil and false if all leaves are iterated.
+//
+// Since 0.5.12
+func (it *Iterator) Next() (key string, value interface{}, ok bool) {
+
+	if it.next == nil {
+		return "", nil, false
+	}
+
+	var k, v []byte
+	for {
+		if it.hasRemain {
+			if it.remain == 0 {
+				it.next = nil
+				return "", nil, false
+			}
+			it.remain--
+		}
+
+		k, v = it.next()
+		if k == nil || (it.hasEnd && string(k) > it.end) || (it.hasStart && string(k) < it.start) {
+			it.next = nil
+			return "", nil, false
+		}
+
+		if !it.filter || strings.HasPrefix(string(k), it.prefix) {
+			break
+		}
+	}
+
+	if it.withValue {
+		value = it.st.decodeLeaf(v)
+	}
+
+	return string(k), value, true
+}
@@ -0,0 +1,650 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Iterate(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"",
+		"a",
+		"ab",
+		"abc",
+		"abcd",
+		"abd",
+		"b",
+		"bc",
+		"\xff",
+	}
+	values := makeI32s(len(keys))
+
+	iterAll := func(st *SlimTrie) ([]string, []interface{}) {
+		var ks []string
+		var vs []interface{}
+		it := st.Iterate()
+		for {
+			k, v, ok := it.Next()
+			if !ok {
+				break
+			}
+			ks = append(ks, k)
+			vs = append(vs, v)
+		}
+
+		// an exhausted iterator keeps returning false.
+		_, _, ok := it.Next()
+		ta.False(ok)
+
+		return ks, vs
+	}
+
+	wantVals := make([]interface{}, len(values))
+	for i, v := range values {
+		wantVals[i] = v
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ks, vs := iterAll(st)
+		ta.Equal(keys, ks)
+		ta.Equal(wantVals, vs)
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+
+		ks, vs := iterAll(st)
+		ta.Equal(len(keys), len(ks))
+		ta.Equal(wantVals, vs)
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ks, vs := iterAll(st)
+		ta.Equal(keys, ks)
+		ta.Equal(make([]interface{}, len(keys)), vs)
+	})
+
+	t.Run("single", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, []string{"abc"}, []int32{5}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ks, vs := iterAll(st)
+		ta.Equal([]string{"abc"}, ks)
+		ta.Equal([]interface{}{int32(5)}, vs)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+
+		ks, _ := iterAll(st)
+		ta.Nil(ks)
+
+		ks, _ = iterAll(&SlimTrie{})
+		ta.Nil(ks)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		for seed := int64(0); seed < 10; seed++ {
+			st, kvs := RandomTrie(rand.New(rand.NewSource(seed)), 200, 5)
+
+			ks, vs := iterAll(st)
+			ta.Equal(len(kvs), len(ks))
+			for i, k := range ks {
+				ta.Equal(kvs[k], vs[i], "seed: %d, key: %q", seed, k)
+			}
+			ta.True(sort.StringsAreSorted(ks))
+		}
+	})
+}
+
+func TestSlimTrie_ScanRange(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"",
+		"a",
+		"ab",
+		"abc",
+		"abd",
+		"b",
+		"bc",
+		"\xff",
+	}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	scan := func(st *SlimTrie, start, end string) []string {
+		ks := []string{}
+		it := st.ScanRange(start, end)
+		for {
+			k, v, ok := it.Next()
+			if !ok {
+				return ks
+			}
+			ta.Equal(values[sort.SearchStrings(keys, k)], v)
+			ks = append(ks, k)
+		}
+	}
+
+	cases := []struct {
+		start, end string
+		want       []string
+	}{
+		{"", "", keys},
+		{"", "a", []string{"", "a"}},
+		{"a", "abc", []string{"a", "ab", "abc"}},
+		{"aa", "abcz", []string{"ab", "abc"}},
+		{"abc", "abc", []string{"abc"}},
+		{"abcc", "abcd", []string{}},
+		{"abd", "", []string{"abd", "b", "bc", "\xff"}},
+		{"b", "a", []string{}},
+		{"c", "", []string{"\xff"}},
+		{"\xff\x00", "", []string{}},
+	}
+
+	for i, c := range cases {
+		ta.Equal(c.want, scan(st, c.start, c.end), "%d-th: case: %+v", i+1, c)
+	}
+
+	t.Run("random", func(t *testing.T) {
+		for seed := int64(0); seed < 10; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			st, kvs := RandomTrie(rng, 200, 5)
+
+			all := make([]string, 0, len(kvs))
+			for k := range kvs {
+				all = append(all, k)
+			}
+			sort.Strings(all)
+
+			for i := 0; i < 20; i++ {
+				start, end := randKey(rng, 3), randKey(rng, 3)
+
+				want := []string{}
+				for _, k := range all {
+					if k >= start && (end == "" || k <= end) {
+						want = append(want, k)
+					}
+				}
+
+				got := []string{}
+				it := st.ScanRange(start, end)
+				for {
+					k, v, ok := it.Next()
+					if !ok {
+						break
+					}
+					ta.Equal(kvs[k], v)
+					got = append(got, k)
+				}
+				ta.Equal(want, got, "seed: %d, range: %q %q", seed, start, end)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+
+		_, _, ok := st.ScanRange("", "").Next()
+		ta.False(ok)
+	})
+}
+
+func TestSlimTrie_IterateReverse(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"",
+		"a",
+		"ab",
+		"abc",
+		"abcd",
+		"abd",
+		"b",
+		"bc",
+		"\xff",
+	}
+	values := makeI32s(len(keys))
+
+	reversed := func(ks []string) []string {
+		r := make([]string, 0, len(ks))
+		for i := len(ks) - 1; i >= 0; i-- {
+			r = append(r, ks[i])
+		}
+		return r
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ks, vs := collectIter(st.IterateReverse())
+		ta.Equal(reversed(keys), ks)
+		for i, k := range ks {
+			ta.Equal(values[sort.SearchStrings(keys, k)], vs[i])
+		}
+
+		// the N greatest keys
+		it := st.IterateReverse()
+		for _, want := range []string{"\xff", "bc", "b"} {
+			k, _, ok := it.Next()
+			ta.True(ok)
+			ta.Equal(want, k)
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+
+		fks, fvs := collectIter(st.Iterate())
+		ks, vs := collectIter(st.IterateReverse())
+		ta.Equal(reversed(fks), ks)
+		for i := range vs {
+			ta.Equal(fvs[len(fvs)-1-i], vs[i])
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, []string{"abc"}, []int32{5}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ks, vs := collectIter(st.IterateReverse())
+		ta.Equal([]string{"abc"}, ks)
+		ta.Equal([]interface{}{int32(5)}, vs)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+
+		ks, _ := collectIter(st.IterateReverse())
+		ta.Nil(ks)
+		ks, _ = collectIter(st.ScanRangeReverse("", ""))
+		ta.Nil(ks)
+	})
+
+	t.Run("scanRange", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		cases := []struct {
+			start, end string
+			want       []string
+		}{
+			{"", "", reversed(keys)},
+			{"", "a", []string{"a", ""}},
+			{"a", "abc", []string{"abc", "ab", "a"}},
+			{"aa", "abcz", []string{"abcd", "abc", "ab"}},
+			{"abc", "abc", []string{"abc"}},
+			{"abcda", "abcz", []string{}},
+			{"abd", "", []string{"\xff", "bc", "b", "abd"}},
+			{"b", "a", []string{}},
+			{"", "\x00", []string{""}},
+			{"\xff\x00", "", []string{}},
+		}
+
+		for i, c := range cases {
+			ks, _ := collectIter(st.ScanRangeReverse(c.start, c.end))
+			if ks == nil {
+				ks = []string{}
+			}
+			ta.Equal(c.want, ks, "%d-th: case: %+v", i+1, c)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		for seed := int64(0); seed < 10; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			st, kvs := RandomTrie(rng, 200, 5, Opt{Complete: Bool(true)})
+
+			all := make([]string, 0, len(kvs))
+			for k := range kvs {
+				all = append(all, k)
+			}
+			sort.Strings(all)
+
+			ks, vs := collectIter(st.IterateReverse())
+			ta.Equal(reversed(all), ks, "seed: %d", seed)
+			for i, k := range ks {
+				ta.Equal(kvs[k], vs[i], "seed: %d, key: %q", seed, k)
+			}
+
+			for i := 0; i < 20; i++ {
+				start, end := randKey(rng, 3), randKey(rng, 3)
+
+				want := []string{}
+				for j := len(all) - 1; j >= 0; j-- {
+					k := all[j]
+					if k >= start && (end == "" || k <= end) {
+						want = append(want, k)
+					}
+				}
+
+				got, _ := collectIter(st.ScanRangeReverse(start, end))
+				if got == nil {
+					got = []string{}
+				}
+				ta.Equal(want, got, "seed: %d, range: %q %q", seed, start, end)
+			}
+		}
+	})
+}
+
+func TestIterator_Seek(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"",
+		"a",
+		"ab",
+		"abc",
+		"abcd",
+		"abd",
+		"b",
+		"bc",
+		"\xff",
+	}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	nextKey := func(it *Iterator) string {
+		k, v, ok := it.Next()
+		if !ok {
+			return "<end>"
+		}
+		ta.Equal(values[sort.SearchStrings(keys, k)], v)
+		return k
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		it := st.Iterate()
+		ta.Equal("", nextKey(it))
+
+		it.Seek("abc")
+		ta.Equal("abc", nextKey(it))
+		ta.Equal("abcd", nextKey(it))
+
+		it.Seek("abce")
+		ta.Equal("abd", nextKey(it))
+
+		// backward
+		it.Seek("a")
+		ta.Equal("a", nextKey(it))
+
+		it.Seek("\xff\x00")
+		ta.Equal("<end>", nextKey(it))
+
+		// revive an exhausted iterator
+		it.Seek("bb")
+		ta.Equal("bc", nextKey(it))
+		ta.Equal("\xff", nextKey(it))
+		ta.Equal("<end>", nextKey(it))
+	})
+
+	t.Run("scanRange", func(t *testing.T) {
+		it := st.ScanRange("ab", "b")
+
+		it.Seek("")
+		ta.Equal("", nextKey(it), "the start is not kept")
+
+		it.Seek("abd")
+		ta.Equal("abd", nextKey(it))
+		ta.Equal("b", nextKey(it))
+		ta.Equal("<end>", nextKey(it))
+
+		it.Seek("bc")
+		ta.Equal("<end>", nextKey(it), "the end is kept")
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		it := st.IterateReverse()
+		ta.Equal("\xff", nextKey(it))
+
+		it.Seek("abcz")
+		ta.Equal("abcd", nextKey(it))
+		ta.Equal("abc", nextKey(it))
+
+		it.Seek("bc")
+		ta.Equal("bc", nextKey(it))
+
+		it.Seek("")
+		ta.Equal("", nextKey(it))
+		ta.Equal("<end>", nextKey(it))
+
+		it = st.ScanRangeReverse("ab", "")
+		it.Seek("abc")
+		ta.Equal("abc", nextKey(it))
+		ta.Equal("ab", nextKey(it))
+		ta.Equal("<end>", nextKey(it), "the start is kept")
+	})
+
+	t.Run("walkPrefix", func(t *testing.T) {
+		it := st.WalkPrefix("ab")
+
+		it.Seek("")
+		ta.Equal("ab", nextKey(it))
+
+		it.Seek("abcd")
+		ta.Equal("abcd", nextKey(it))
+		ta.Equal("abd", nextKey(it))
+		ta.Equal("<end>", nextKey(it))
+
+		it.Seek("abc")
+		ta.Equal("abc", nextKey(it))
+
+		it.Seek("b")
+		ta.Equal("<end>", nextKey(it))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		e, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+
+		it := e.Iterate()
+		it.Seek("a")
+		ta.Equal("<end>", nextKey(it))
+	})
+
+	t.Run("mergeJoin", func(t *testing.T) {
+		for seed := int64(0); seed < 10; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			a, akvs := RandomTrie(rng, 200, 3, Opt{Complete: Bool(true)})
+			b, bkvs := RandomTrie(rng, 200, 3, Opt{Complete: Bool(true)})
+
+			want := []string{}
+			for k := range akvs {
+				if _, ok := bkvs[k]; ok {
+					want = append(want, k)
+				}
+			}
+			sort.Strings(want)
+
+			// intersect by seeking each iterator to the key of the other.
+			got := []string{}
+			ia, ib := a.Iterate(), b.Iterate()
+			ka, _, oka := ia.Next()
+			kb, _, okb := ib.Next()
+			for oka && okb {
+				switch {
+				case ka == kb:
+					got = append(got, ka)
+					ka, _, oka = ia.Next()
+				case ka < kb:
+					ia.Seek(kb)
+					ka, _, oka = ia.Next()
+				default:
+					ib.Seek(ka)
+					kb, _, okb = ib.Next()
+				}
+			}
+			ta.Equal(want, got, "seed: %d", seed)
+		}
+	})
+}
+
+// collectIter returns all keys and values an Iterator yields.
+func collectIter(it *Iterator) ([]string, []interface{}) {
+	var ks []string
+	var vs []interface{}
+	for {
+		k, v, ok := it.Next()
+		if !ok {
+			return ks, vs
+		}
+		ks = append(ks, k)
+		vs = append(vs, v)
+	}
+}
+
+func TestSlimTrie_WalkPrefix(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"",
+		"a",
+		"ab",
+		"abc",
+		"abcd",
+		"abd",
+		"b",
+		"bc",
+		"\xff",
+	}
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", keys},
+		{"a", []string{"a", "ab", "abc", "abcd", "abd"}},
+		{"ab", []string{"ab", "abc", "abcd", "abd"}},
+		{"abc", []string{"abc", "abcd"}},
+		{"abcd", []string{"abcd"}},
+		{"abcde", nil},
+		{"abe", nil},
+		{"b", []string{"b", "bc"}},
+		{"bcd", nil},
+		{"c", nil},
+		{"\xff", []string{"\xff"}},
+		{"\xff\xff", nil},
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		for i, c := range cases {
+			ks, vs := collectIter(st.WalkPrefix(c.prefix))
+			ta.Equal(c.want, ks, "%d-th: prefix: %q", i+1, c.prefix)
+			for j, k := range ks {
+				v, _ := st.Get(k)
+				ta.Equal(v, vs[j], "%d-th: prefix: %q", i+1, c.prefix)
+			}
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+
+		// values of keys with prefix are always iterated.
+		for i, c := range cases {
+			_, vs := collectIter(st.WalkPrefix(c.prefix))
+			for _, k := range c.want {
+				v, _ := st.Get(k)
+				ta.Contains(vs, v, "%d-th: prefix: %q", i+1, c.prefix)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ks, _ := collectIter((&SlimTrie{}).WalkPrefix("a"))
+		ta.Nil(ks)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		for seed := int64(0); seed < 10; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			st, kvs := RandomTrie(rng, 200, 5)
+
+			keys := make([]string, 0, len(kvs))
+			for k := range kvs {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for i := 0; i < 50; i++ {
+				prefix := randKey(rng, 2)
+				if i%2 == 0 {
+					k := keys[rng.Intn(len(keys))]
+					prefix = k[:rng.Intn(len(k)+1)]
+				}
+
+				var want []string
+				for _, k := range keys {
+					if strings.HasPrefix(k, prefix) {
+						want = append(want, k)
+					}
+				}
+
+				ks, vs := collectIter(st.WalkPrefix(prefix))
+				ta.Equal(want, ks, "seed: %d, prefix: %q", seed, prefix)
+				for j, k := range ks {
+					ta.Equal(kvs[k], vs[j], "seed: %d, key: %q", seed, k)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkSlimTrie_Iterate_1m(b *testing.B) {
+
+	keys := getKeys("1mvl5_10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Logf("keys: %d, levels: %d", len(keys), len(st.levels))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var n int
+	for i := 0; i < b.N; i++ {
+		it := st.Iterate()
+		for k, _, ok := it.Next(); ok; k, _, ok = it.Next() {
+			n += len(k)
+		}
+	}
+	OutputIter = n
+}
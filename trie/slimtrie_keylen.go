@@ -0,0 +1,43 @@
+package trie
+
+// ExpectedKeyLen returns the length of every key and true, if all keys are of
+// the same length.
+// With Opt.KeyNormalizer or Opt.Collation, it is the length of a key in the
+// normalized or collated form.
+// Otherwise, or if it is loaded from data built before key length is
+// recorded, it returns 0 and false.
+//
+// With Opt.CheckKeyLen, a query key of another length is never found.
+//
+// Since 0.5.12
+func (st *SlimTrie) ExpectedKeyLen() (int32, bool) {
+	ns := st.inner
+	if !ns.GetHasKeyLen() {
+		return 0, false
+	}
+	return ns.KeyLen, true
+}
+
+// recordKeyLen writes into ns the length of keys if they are all of the same
+// length.
+//
+// Since 0.5.12
+func recordKeyLen(ns *Slim, keys []string) {
+
+	ns.HasKeyLen = false
+	ns.KeyLen = 0
+
+	if len(keys) == 0 {
+		return
+	}
+
+	l := len(keys[0])
+	for _, k := range keys[1:] {
+		if len(k) != l {
+			return
+		}
+	}
+
+	ns.HasKeyLen = true
+	ns.KeyLen = int32(l)
+}
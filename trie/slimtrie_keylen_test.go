@@ -0,0 +1,134 @@
+package trie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// trimNormalizer normalizes keys by removing leading and trailing spaces.
+type trimNormalizer struct{}
+
+func (n trimNormalizer) Name() string { return "test.trim.v1" }
+
+func (n trimNormalizer) Normalize(key string) string { return strings.TrimSpace(key) }
+
+func TestSlimTrie_ExpectedKeyLen(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		keys    []string
+		wantLen int32
+		wantOK  bool
+	}{
+		{[]string{}, 0, false},
+		{[]string{""}, 0, true},
+		{[]string{"abc"}, 3, true},
+		{[]string{"abc", "abd", "bcd"}, 3, true},
+		{[]string{"ab", "abd", "bcd"}, 0, false},
+	}
+
+	for i, c := range cases {
+		st, err := NewSlimTrie(encode.I32{}, c.keys, makeI32s(len(c.keys)))
+		ta.NoError(err)
+
+		l, ok := st.ExpectedKeyLen()
+		ta.Equal(c.wantLen, l, "%d-th: case: %+v", i+1, c)
+		ta.Equal(c.wantOK, ok, "%d-th: case: %+v", i+1, c)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+
+		l, ok = st2.ExpectedKeyLen()
+		ta.Equal(c.wantLen, l, "%d-th: case: %+v", i+1, c)
+		ta.Equal(c.wantOK, ok, "%d-th: case: %+v", i+1, c)
+	}
+}
+
+func TestSlimTrie_CheckKeyLen(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"abc", "abd", "bcd", "xyz"}
+	values := makeI32s(len(keys))
+
+	st0, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{CheckKeyLen: Bool(true)})
+	ta.NoError(err)
+
+	testPresentKeysGet(t, st, keys, values)
+
+	// a false positive without checking key length.
+	_, found := st0.Get("abcd")
+	ta.True(found)
+
+	for _, k := range []string{"", "a", "ab", "abcd", "xyzxyz"} {
+		_, found := st.Get(k)
+		ta.False(found, "key: %q", k)
+		ta.Equal(int32(-1), st.GetID(k), "key: %q", k)
+	}
+
+	t.Run("variableLength", func(t *testing.T) {
+		keys := []string{"ab", "abc", "b"}
+		values := makeI32s(len(keys))
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{CheckKeyLen: Bool(true)})
+		ta.NoError(err)
+
+		testPresentKeysGet(t, st, keys, values)
+	})
+
+	t.Run("normalizer", func(t *testing.T) {
+		ta := require.New(t)
+
+		keys := []string{" abc", "abd ", "bcd"}
+		values := makeI32s(len(keys))
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values,
+			Opt{CheckKeyLen: Bool(true), KeyNormalizer: trimNormalizer{}})
+		ta.NoError(err)
+
+		l, ok := st.ExpectedKeyLen()
+		ta.True(ok)
+		ta.Equal(int32(3), l)
+
+		testPresentKeysGet(t, st, keys, values)
+
+		for _, k := range []string{"abc", "abc  ", " bcd "} {
+			_, found := st.Get(k)
+			ta.True(found, "key: %q", k)
+		}
+
+		for _, k := range []string{"ab", " ab ", "abcd"} {
+			_, found := st.Get(k)
+			ta.False(found, "key: %q", k)
+		}
+	})
+
+	t.Run("terminator", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values,
+			Opt{CheckKeyLen: Bool(true), WithTerminator: Bool(true)})
+		ta.NoError(err)
+
+		l, ok := st.ExpectedKeyLen()
+		ta.True(ok)
+		ta.Equal(int32(3), l)
+
+		testPresentKeysGet(t, st, keys, values)
+
+		for _, k := range []string{"", "ab", "abcd"} {
+			ta.Equal(int32(-1), st.GetID(k), "key: %q", k)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func makeSmallStrs(n int) []string {
+	rst := make([]string, n)
+	for i := 0; i < n; i++ {
+		rst[i] = fmt.Sprintf("%x", i%1000)
+	}
+	return rst
+}
+
+func TestSlimTrie_LeafBlockSize(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeSmallStrs(len(keys))
+
+	st0, err := NewSlimTrie(encode.String16{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for _, blockSize := range []int32{1, 2, 7, 16, 64} {
+
+		opt := Opt{Complete: Bool(true), LeafBlockSize: blockSize}
+		st, err := NewSlimTrie(encode.String16{}, keys, values, opt)
+		ta.NoError(err)
+
+		ls := st.inner.Leaves
+		ta.Equal(blockSize, ls.BlockSize)
+		ta.Nil(ls.PositionBM)
+		ta.Equal((ls.EltCnt+blockSize-1)/blockSize, int32(len(ls.BlockOffsets)))
+
+		// values at block boundaries are checked too.
+		for i, k := range keys {
+			v, found := st.Get(k)
+			ta.True(found, "%d-th key %q", i, k)
+			ta.Equal(values[i], v, "%d-th key %q", i, k)
+		}
+
+		if blockSize >= 16 {
+			ta.True(st.MappedBytes() < st0.MappedBytes(),
+				"block: %d, without: %d", st.MappedBytes(), st0.MappedBytes())
+		}
+		t.Logf("LeafBlockSize=%d: %d bytes, without block: %d bytes",
+			blockSize, st.MappedBytes(), st0.MappedBytes())
+
+		buf, err := proto.Marshal(st)
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.String16{}, nil, nil)
+		ta.NoError(err)
+		err = proto.Unmarshal(buf, st2)
+		ta.NoError(err)
+		for i, k := range keys {
+			v, _ := st2.Get(k)
+			ta.Equal(values[i], v, "%d-th key %q", i, k)
+		}
+
+		// scanning retrieves packed values too.
+		i := 0
+		st2.ScanFrom("", true, true, func(k, v []byte) bool {
+			_, s := encode.String16{}.Decode(v)
+			ta.Equal(values[i], s, "%d-th key %q", i, k)
+			i++
+			return true
+		})
+		ta.Equal(len(keys), i)
+	}
+
+	t.Run("fixedSize", func(t *testing.T) {
+		values := makeI32s(len(keys))
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{LeafBlockSize: 16})
+		ta.NoError(err)
+
+		ta.Equal(int32(0), st.inner.Leaves.BlockSize)
+		testPresentKeysGet(t, st, keys, values)
+	})
+}
+
+func BenchmarkSlimTrie_LeafBlockSize(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeSmallStrs(len(keys))
+
+	for _, blockSize := range []int32{0, 16, 64} {
+		st, _ := NewSlimTrie(encode.String16{}, keys, values, Opt{LeafBlockSize: blockSize})
+
+		b.Run(fmt.Sprintf("LeafBlockSize=%d", blockSize), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = st.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package trie
+
+import "github.com/openacid/low/bitmap"
+
+// LeafCursor yields the node id and the value of every leaf that has a value,
+// in node id order, i.e., breadth-first order, NOT in key order.
+// It is created by SlimTrie.LeafCursor().
+//
+// A node id is what GetID() returns for a key, thus it is the key to join a
+// side table indexed by node id.
+//
+// A LeafCursor is not safe for concurrent use.
+//
+// Since 0.5.12
+type LeafCursor struct {
+	st     *SlimTrie
+	leaves *VLenArray
+
+	// total is the number of nodes.
+	total int32
+
+	// nodeID is the next node to visit.
+	nodeID int32
+
+	// ithLeaf is the index of the next leaf among all leaves.
+	ithLeaf int32
+
+	// ithElt is the index of the next present leaf among present leaves.
+	ithElt int32
+}
+
+// LeafCursor returns a LeafCursor over all leaves in node id order.
+//
+// It is the cheapest full scan when key order does not matter, e.g., to
+// rebuild an array of values in parallel with the trie: it visits nodes one
+// by one without descending from the root or rebuilding keys, skips absent
+// leaves with the presence bitmap of leaves, and reads present leaves
+// sequentially without rank queries.
+// To visit leaves in key order, use Iterate().
+//
+// A leaf without value and a leaf removed by Delete() is skipped.
+// If SlimTrie does not store values, it yields nothing.
+//
+// Since 0.5.12
+func (st *SlimTrie) LeafCursor() *LeafCursor {
+
+	c := &LeafCursor{st: st}
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return c
+	}
+
+	c.leaves = st.getLeaves()
+	if c.leaves == nil {
+		return c
+	}
+
+	c.total = st.levels[len(st.levels)-1].total
+	return c
+}
+
+// Next returns the node id and the value of the next leaf, and true.
+// It returns -1, nil and false if all leaves are visited.
+//
+// Since 0.5.12
+func (c *LeafCursor) Next() (nodeID int32, value interface{}, ok bool) {
+
+	innerBM := c.st.inner.GetNodeTypeBM().GetWords()
+	presenceBM := c.leaves.GetPresenceBM().GetWords()
+
+	for c.nodeID < c.total {
+
+		id := c.nodeID
+		c.nodeID++
+
+		// trailing leaves may be out of NodeTypeBM.
+		if int(id>>6) < len(innerBM) && innerBM[id>>6]&bitmap.Bit[id&63] != 0 {
+			continue
+		}
+
+		ithLeaf := c.ithLeaf
+		c.ithLeaf++
+
+		if presenceBM[ithLeaf>>6]&bitmap.Bit[ithLeaf&63] == 0 {
+			continue
+		}
+
+		ithElt := c.ithElt
+		c.ithElt++
+
+		if c.st.isDeleted(id) {
+			continue
+		}
+
+		return id, c.st.decodeLeaf(c.leaves.getElt(ithElt)), true
+	}
+
+	return -1, nil, false
+}
@@ -0,0 +1,148 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// collectLeafCursor returns the node ids and values a LeafCursor yields.
+func collectLeafCursor(c *LeafCursor) ([]int32, []interface{}) {
+	ids := []int32{}
+	vals := []interface{}{}
+	for {
+		id, v, ok := c.Next()
+		if !ok {
+			return ids, vals
+		}
+		ids = append(ids, id)
+		vals = append(vals, v)
+	}
+}
+
+// wantLeafCursor returns the node ids of keys in ascending order and their
+// values, skipping keys without value.
+func wantLeafCursor(st *SlimTrie, keys []string) ([]int32, []interface{}) {
+
+	byID := map[int32]interface{}{}
+	for _, k := range keys {
+		id := st.GetID(k)
+		if id == -1 {
+			continue
+		}
+		v, _ := st.Get(k)
+		if v == nil {
+			continue
+		}
+		byID[id] = v
+	}
+
+	ids := []int32{}
+	vals := []interface{}{}
+	for id := int32(0); id < st.levels[len(st.levels)-1].total; id++ {
+		if v, ok := byID[id]; ok {
+			ids = append(ids, id)
+			vals = append(vals, v)
+		}
+	}
+	return ids, vals
+}
+
+func TestSlimTrie_LeafCursor(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{
+		{},
+		{Complete: Bool(true)},
+		{LeafBlockSize: 16},
+		{DedupValue: Bool(false)},
+	} {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		wantIDs, wantVals := wantLeafCursor(st, keys)
+		ids, vals := collectLeafCursor(st.LeafCursor())
+		ta.Equal(wantIDs, ids, "opt: %+v", opt)
+		ta.Equal(wantVals, vals, "opt: %+v", opt)
+	}
+
+	t.Run("absent", func(t *testing.T) {
+		ta := require.New(t)
+
+		keys := []string{"a", "b", "c", "d"}
+		st, err := NewSlimTrie(encode.String16{}, keys, []interface{}{"x", nil, "y", nil})
+		ta.NoError(err)
+
+		_, vals := collectLeafCursor(st.LeafCursor())
+		ta.Equal([]interface{}{"x", "y"}, vals)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		ta := require.New(t)
+
+		keys := []string{"a", "b", "c"}
+		st, err := NewSlimTrie(encode.I32{}, keys, []int32{1, 2, 3}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.True(st.Delete("b"))
+
+		_, vals := collectLeafCursor(st.LeafCursor())
+		ta.Equal([]interface{}{int32(1), int32(3)}, vals)
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(nil, []string{"a", "b"}, nil)
+		ta.NoError(err)
+
+		ids, _ := collectLeafCursor(st.LeafCursor())
+		ta.Empty(ids)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ta := require.New(t)
+
+		empty, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		for _, st := range []*SlimTrie{empty, {}} {
+			id, v, ok := st.LeafCursor().Next()
+			ta.Equal(int32(-1), id)
+			ta.Nil(v)
+			ta.False(ok)
+		}
+	})
+}
+
+func BenchmarkSlimTrie_LeafCursor(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, err := NewSlimTrie(encode.I32{}, keys, values)
+	if err != nil {
+		panic(err)
+	}
+
+	b.Run("LeafCursor", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c := st.LeafCursor()
+			for _, _, ok := c.Next(); ok; _, _, ok = c.Next() {
+			}
+		}
+	})
+
+	b.Run("Iterate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			it := st.Iterate()
+			for _, _, ok := it.Next(); ok; _, _, ok = it.Next() {
+			}
+		}
+	})
+}
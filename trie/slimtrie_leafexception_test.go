@@ -0,0 +1,125 @@
+package trie
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// makeOutlierStrs makes n strings of 8 bytes, except every step-th one is a
+// large one.
+func makeOutlierStrs(n, step int) []string {
+	rst := make([]string, n)
+	for i := 0; i < n; i++ {
+		rst[i] = fmt.Sprintf("%08x", i)
+		if step > 0 && i%step == 0 {
+			rst[i] = strings.Repeat(rst[i], 1+i%7)
+		}
+	}
+	return rst
+}
+
+func TestSlimTrie_LeafExceptions(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeOutlierStrs(len(keys), 100)
+
+	st0, err := NewSlimTrie(encode.String16{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	opt := Opt{Complete: Bool(true), LeafExceptions: Bool(true), LeafBlockSize: 16}
+	st, err := NewSlimTrie(encode.String16{}, keys, values, opt)
+	ta.NoError(err)
+
+	ls := st.inner.Leaves
+	ta.Nil(ls.PositionBM)
+	ta.Equal(int32(0), ls.BlockSize)
+	ta.Equal(int32(10), ls.FixedSize)
+	ta.NotNil(ls.ExceptionBM)
+	ta.True(st.MappedBytes() < st0.MappedBytes(),
+		"exceptions: %d, without: %d", st.MappedBytes(), st0.MappedBytes())
+
+	for i, k := range keys {
+		v, found := st.Get(k)
+		ta.True(found, "%d-th key %q", i, k)
+		ta.Equal(values[i], v, "%d-th key %q", i, k)
+	}
+
+	buf, err := proto.Marshal(st)
+	ta.NoError(err)
+
+	st2, err := NewSlimTrie(encode.String16{}, nil, nil)
+	ta.NoError(err)
+	ta.NoError(proto.Unmarshal(buf, st2))
+	for i, k := range keys {
+		v, _ := st2.Get(k)
+		ta.Equal(values[i], v, "%d-th key %q", i, k)
+	}
+
+	// scanning retrieves values with exceptions too.
+	i := 0
+	st2.ScanFrom("", true, true, func(k, v []byte) bool {
+		_, s := encode.String16{}.Decode(v)
+		ta.Equal(values[i], s, "%d-th key %q", i, k)
+		i++
+		return true
+	})
+	ta.Equal(len(keys), i)
+
+	t.Run("absent", func(t *testing.T) {
+		keys := []string{"a", "b", "c", "d", "e"}
+		values := []interface{}{"12345678", nil, "123", nil, "87654321"}
+
+		st, err := NewSlimTrie(encode.String16{}, keys, values,
+			Opt{LeafExceptions: Bool(true), DedupValue: Bool(false)})
+		ta.NoError(err)
+
+		ta.NotNil(st.inner.Leaves.ExceptionBM)
+		ta.Equal(map[int]int{0: 2, 5: 1, 10: 2}, st.LeafSizeHistogram())
+		for i, k := range keys {
+			v, found := st.Get(k)
+			ta.True(found, "%d-th key %q", i, k)
+			ta.Equal(values[i], v, "%d-th key %q", i, k)
+		}
+	})
+
+	t.Run("fixedSize", func(t *testing.T) {
+		values := makeI32s(len(keys))
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{LeafExceptions: Bool(true)})
+		ta.NoError(err)
+
+		ta.Nil(st.inner.Leaves.ExceptionBM)
+		testPresentKeysGet(t, st, keys, values)
+	})
+}
+
+func BenchmarkSlimTrie_LeafExceptions(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+
+	cases := []struct {
+		name   string
+		values []string
+		opt    Opt
+	}{
+		{"fixed", makeOutlierStrs(len(keys), 0), Opt{}},
+		{"variable", makeOutlierStrs(len(keys), 100), Opt{}},
+		{"exceptions", makeOutlierStrs(len(keys), 100), Opt{LeafExceptions: Bool(true)}},
+	}
+
+	for _, c := range cases {
+		st, _ := NewSlimTrie(encode.String16{}, keys, c.values, c.opt)
+
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = st.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
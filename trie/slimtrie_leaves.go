@@ -0,0 +1,80 @@
+package trie
+
+// IterNonDefault iterates all leaves in leaf order and calls fn for every leaf
+// value that isDefault returns false for.
+// ith is the index of a leaf among all leaves in breadth-first order, not in
+// key order.
+// Every leaf of the trie is visited, including trailing leaves absent in the
+// leaves array, which have the value nil.
+//
+// Every value is decoded to test against isDefault, but values are never
+// collected into a slice.
+// The iteration stops if fn returns false.
+//
+// If SlimTrie does not store values, fn is never called.
+//
+// Since 0.5.12
+func (st *SlimTrie) IterNonDefault(isDefault func(interface{}) bool, fn func(ith int32, val interface{}) bool) {
+
+	ls := st.getLeaves()
+	if ls == nil {
+		return
+	}
+
+	n := st.leafCount()
+	for i := int32(0); i < n; i++ {
+		var v interface{}
+		if i < ls.N {
+			v = st.getIthLeaf(i)
+		}
+		if isDefault(v) {
+			continue
+		}
+		if !fn(i, v) {
+			return
+		}
+	}
+}
+
+// LeafSizeHistogram returns the number of leaves of every encoded value size
+// in byte.
+// A leaf without value is counted as size 0.
+//
+// If all non-empty leaves are of the same size, leaves are already stored in
+// a fixed size layout and there is only one non-zero size in the result.
+//
+// It returns an empty map if SlimTrie does not store values.
+//
+// Since 0.5.12
+func (st *SlimTrie) LeafSizeHistogram() map[int]int {
+
+	rst := map[int]int{}
+
+	ls := st.getLeaves()
+	if ls == nil {
+		return rst
+	}
+
+	n := st.leafCount()
+
+	if ls.PositionBM == nil && ls.BlockSize == 0 && ls.ExceptionBM == nil && ls.N <= n {
+		// fixed size
+		if ls.EltCnt > 0 {
+			rst[int(ls.FixedSize)] = int(ls.EltCnt)
+		}
+		if n > ls.EltCnt {
+			rst[0] = int(n - ls.EltCnt)
+		}
+		return rst
+	}
+
+	for i := int32(0); i < n; i++ {
+		if i < ls.N {
+			rst[len(ls.get(i))]++
+		} else {
+			rst[0]++
+		}
+	}
+
+	return rst
+}
@@ -0,0 +1,196 @@
+package trie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_IterNonDefault(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := []int{0, 3, 0, 0, 5, 0, 7, 0}
+
+	st, err := NewSlimTrie(encode.Int{}, keys, values, Opt{DedupValue: Bool(false)})
+	ta.NoError(err)
+
+	isZero := func(v interface{}) bool { return v.(int) == 0 }
+
+	got := map[int]bool{}
+	st.IterNonDefault(isZero, func(ith int32, v interface{}) bool {
+		ta.Equal(v, st.getIthLeaf(ith))
+		got[v.(int)] = true
+		return true
+	})
+	ta.Equal(map[int]bool{3: true, 5: true, 7: true}, got)
+
+	t.Run("stop", func(t *testing.T) {
+		n := 0
+		st.IterNonDefault(isZero, func(ith int32, v interface{}) bool {
+			n++
+			return n < 2
+		})
+		ta.Equal(2, n)
+	})
+
+	t.Run("allDefault", func(t *testing.T) {
+		st.IterNonDefault(func(interface{}) bool { return true }, func(ith int32, v interface{}) bool {
+			t.Fatalf("should not be called")
+			return true
+		})
+	})
+
+	t.Run("trailingAbsent", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)), Opt{DedupValue: Bool(false)})
+		ta.NoError(err)
+
+		// leaves array without the trailing absent leaves.
+		st.inner.Leaves = newVLenArray([][]byte{encode.I32{}.Encode(int32(1))})
+		ta.Equal(int32(1), st.inner.Leaves.N)
+
+		var got []interface{}
+		st.IterNonDefault(func(interface{}) bool { return false }, func(ith int32, v interface{}) bool {
+			ta.Equal(int32(len(got)), ith)
+			got = append(got, v)
+			return true
+		})
+		ta.Equal(len(keys), len(got))
+		ta.Equal(int32(1), got[0])
+		for _, v := range got[1:] {
+			ta.Nil(v)
+		}
+
+		ta.Equal(map[int]int{0: len(keys) - 1, 4: 1}, st.LeafSizeHistogram())
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil)
+		ta.NoError(err)
+
+		st.IterNonDefault(isZero, func(ith int32, v interface{}) bool {
+			t.Fatalf("should not be called")
+			return true
+		})
+	})
+}
+
+func TestSlimTrie_LeafSizeHistogram(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	n := len(keys)
+
+	t.Run("fixed", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(n), Opt{DedupValue: Bool(false)})
+		ta.NoError(err)
+		ta.Equal(map[int]int{4: n}, st.LeafSizeHistogram())
+	})
+
+	t.Run("varlen", func(t *testing.T) {
+		values := []string{"a", "bb", "cc", "d", "eee", "f", "g", "hh"}
+
+		for _, blockSize := range []int32{0, 3} {
+			st, err := NewSlimTrie(encode.String16{}, keys, values,
+				Opt{DedupValue: Bool(false), LeafBlockSize: blockSize})
+			ta.NoError(err)
+			ta.Equal(map[int]int{3: 4, 4: 3, 5: 1}, st.LeafSizeHistogram())
+		}
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil)
+		ta.NoError(err)
+		ta.Equal(map[int]int{}, st.LeafSizeHistogram())
+	})
+}
+
+func TestSlimTrie_getIthLeafBytes_varLen(t *testing.T) {
+
+	keys := []string{"a", "ab", "abc", "b", "bc", "c", "cd"}
+	values := []interface{}{
+		"s",
+		strings.Repeat("L", 2000),
+		nil,
+		"",
+		strings.Repeat("M", 300),
+		"tt",
+		nil,
+	}
+
+	for _, opt := range []Opt{
+		{DedupValue: Bool(false)},
+		{DedupValue: Bool(false), LeafBlockSize: 4},
+		{DedupValue: Bool(false), LeafExceptions: Bool(true)},
+	} {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.String16{}, keys, values, opt)
+		ta.NoError(err)
+
+		if opt.LeafBlockSize == 0 && opt.LeafExceptions == nil {
+			ta.NotNil(st.inner.Leaves.PositionBM)
+		}
+
+		for i, k := range keys {
+			id := st.GetID(k)
+			ith, _ := st.getLeafIndex(id)
+
+			bs, ok := st.getIthLeafBytes(ith)
+			ta.True(ok)
+			if values[i] == nil {
+				ta.Equal(0, len(bs), "opt: %+v, %d-th key %q", opt, i, k)
+			} else {
+				ta.Equal(encode.String16{}.Encode(values[i]), bs, "opt: %+v, %d-th key %q", opt, i, k)
+			}
+
+			v, found := st.Get(k)
+			ta.True(found)
+			ta.Equal(values[i], v, "opt: %+v, %d-th key %q", opt, i, k)
+		}
+	}
+}
+
+func TestSlimTrie_GetLeafIndex(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	// a side array indexed by leaf index
+	side := make([]int32, st.Len())
+	seen := make([]bool, st.Len())
+
+	for i, k := range keys {
+		ith, ok := st.GetLeafIndex(k)
+		ta.True(ok, "%d-th key %q", i, k)
+		ta.False(seen[ith], "%d-th key %q", i, k)
+		seen[ith] = true
+		side[ith] = values[i]
+	}
+
+	// the same index as IterNonDefault() yields
+	n := 0
+	st.IterNonDefault(func(interface{}) bool { return false }, func(ith int32, val interface{}) bool {
+		ta.Equal(side[ith], val)
+		n++
+		return true
+	})
+	ta.Equal(len(keys), n)
+
+	ith, ok := st.GetLeafIndex(keys[len(keys)-1] + "\x00")
+	ta.False(ok)
+	ta.Equal(int32(-1), ith)
+
+	ith, ok = (&SlimTrie{}).GetLeafIndex("a")
+	ta.False(ok)
+	ta.Equal(int32(-1), ith)
+}
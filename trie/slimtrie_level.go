@@ -3,6 +3,7 @@ package trie
 import (
 	"fmt"
 
+	"github.com/openacid/errors"
 	"github.com/openacid/low/bitmap"
 )
 
@@ -98,14 +99,16 @@ func levelsStr(l []levelInfo) []string {
 }
 
 // initLevels builds the levelInfo slice.
+// It returns an ErrCorrupt error if a level does not start after the previous
+// one.
 //
 // Since 0.5.12
-func (st *SlimTrie) initLevels() {
+func (st *SlimTrie) initLevels() error {
 	ns := st.inner
 
 	if ns.NodeTypeBM == nil {
 		st.levels = []levelInfo{{0, 0, 0, nil}}
-		return
+		return nil
 	}
 
 	st.levels = make([]levelInfo, 0)
@@ -143,8 +146,114 @@ func (st *SlimTrie) initLevels() {
 		st.getIthInnerFrom(nextInnerIdx, qr)
 
 		leftMostChild, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+		if leftMostChild+1 <= currId {
+			return errors.Wrapf(ErrCorrupt, "level starts at node %d after %d", leftMostChild+1, currId)
+		}
 		currId = leftMostChild + 1
 	}
 
 	st.levels = append(st.levels, levelInfo{total: total, inner: totalInner, leaf: total - totalInner})
+	return nil
+}
+
+// Len returns the number of leaves, i.e., the number of keys stored.
+// Keys removed by Opt.DedupValue when creating are not counted, thus it could
+// be less than the number of keys passed to NewSlimTrie().
+//
+// It is O(1): the count is recorded when a SlimTrie is created or loaded, from
+// any supported version.
+//
+// Since 0.5.12
+func (st *SlimTrie) Len() int {
+	return int(st.leafCount())
+}
+
+// IsEmpty returns true if it has no key, e.g., it is created with no key, or
+// it is Reset().
+// It is the same as Len() == 0.
+//
+// Since 0.5.12
+func (st *SlimTrie) IsEmpty() bool {
+	return st.inner.GetNodeTypeBM() == nil
+}
+
+// leafCount returns the number of leaves.
+// It is 0 for a zero value SlimTrie, which has no levels.
+//
+// Since 0.5.12
+func (st *SlimTrie) leafCount() int32 {
+	if len(st.levels) == 0 {
+		return 0
+	}
+	return st.levels[len(st.levels)-1].leaf
+}
+
+// keyOrdinal returns the index of a leaf in key order, i.e., the number of
+// leaves with a smaller key, while a leaf index is in breadth-first order.
+//
+// At every level, nodes before a boundary position are all on the left of the
+// leaf, and the leaves among them are counted.
+// Above the level of the leaf, the boundary is the ancestor of the leaf.
+// Below it, the boundary is the first child of the first inner node at or
+// after the boundary of the upper level.
+//
+// With a range index built by BuildRangeIndex(), it is a single lookup.
+//
+// Since 0.5.12
+func (st *SlimTrie) keyOrdinal(nodeID int32) int32 {
+
+	if st.rangeIndex != nil {
+		return st.rangeIndex.first[nodeID]
+	}
+
+	ns := st.inner
+	lvs := st.levels
+
+	// find out the level of nodeID
+	lv := 1
+	for lvs[lv].total <= nodeID {
+		lv++
+	}
+
+	ordinal := int32(0)
+
+	// leavesBefore counts leaves before position cur at level l.
+	// It also returns the number of inner nodes before cur.
+	leavesBefore := func(cur int32, l int) (int32, int32) {
+		if cur == lvs[l].total {
+			// the end of the level, it might be out of NodeTypeBM.
+			return lvs[l].leaf - lvs[l-1].leaf, lvs[l].inner
+		}
+		ithInner, _ := bitmap.Rank64(ns.NodeTypeBM.Words, ns.NodeTypeBM.RankIndex, cur)
+		return cur - ithInner - lvs[l-1].leaf, ithInner
+	}
+
+	for id, l := nodeID, lv; l > 1; l-- {
+		id = st.parentOf(id)
+		n, _ := leavesBefore(id, l-1)
+		ordinal += n
+	}
+
+	cur := nodeID
+	qr := &querySession{}
+
+	for ; lv < len(lvs); lv++ {
+
+		n, ithInner := leavesBefore(cur, lv)
+		ordinal += n
+
+		if lv == len(lvs)-1 {
+			break
+		}
+
+		if ithInner >= lvs[lv].inner {
+			cur = lvs[lv+1].total
+		} else {
+			st.getIthInnerFrom(ithInner, qr)
+			firstChild, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+			cur = firstChild + 1
+		}
+	}
+
+	return ordinal
 }
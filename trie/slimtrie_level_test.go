@@ -116,6 +116,8 @@ func TestSlimTrie_levels(t *testing.T) {
 				ta.Equal(lvl.inner, st.levels[i].inner, "inner: line %d", i)
 				ta.Equal(lvl.leaf, st.levels[i].leaf, "leaf: line %d", i)
 			}
+
+			ta.Equal(len(c.keys), st.Len())
 		})
 	}
 }
@@ -0,0 +1,83 @@
+package trie
+
+// Has returns true if key exists, no matter whether it has a value.
+// It is the presence check of Get() without locating and decoding the value,
+// thus it is clearer than Get() for a key with a nil value, and faster.
+//
+// Like Get(), a true does not mean the key absolutely exists, which is a
+// "false positive", unless the SlimTrie is created with
+// Opt{Complete: Bool(true)}.
+// A false always means the key does not exist.
+//
+// Since 0.5.12
+func (st *SlimTrie) Has(key string) bool {
+	return st.GetID(key) != -1
+}
+
+// HasMany checks existence of every key in keys and returns a slice of bool,
+// in which the i-th element indicates whether keys[i] exists.
+//
+// It is the same as calling GetID() for every key except that it reuses one
+// internal query context for all keys and never decodes values, which makes it
+// faster than calling Get() in a loop.
+//
+// Keys do not need to be sorted.
+// But sorted keys have better memory locality.
+//
+// Like Get(), a true does not mean the key absolutely exists, which is a
+// "false positive", unless the SlimTrie is created with Opt{Complete: Bool(true)}.
+//
+// Since 0.5.12
+func (st *SlimTrie) HasMany(keys []string) []bool {
+
+	rst := make([]bool, len(keys))
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return rst
+	}
+
+	qr := &querySession{}
+	for i, k := range keys {
+		rst[i] = st.getID(k, qr) != -1
+	}
+
+	return rst
+}
+
+// GetMany looks up every key in keys and returns two slices of the same
+// length as keys: the i-th value and whether keys[i] is found.
+//
+// It is the same as calling Get() for every key except that it reuses one
+// internal query context for all keys.
+// Expect about the same time as calling Get() in a loop: the query context of
+// Get() does not escape to heap, and the time is dominated by traversal and
+// decoding values, which allocates for a non-pointer value.
+// It allocates the two result slices once.
+//
+// Keys do not need to be sorted.
+// But sorted keys have better memory locality.
+//
+// Like Get(), a found key does not mean the key absolutely exists, which is a
+// "false positive", unless the SlimTrie is created with Opt{Complete: Bool(true)}.
+//
+// Since 0.5.12
+func (st *SlimTrie) GetMany(keys []string) ([]interface{}, []bool) {
+
+	vals := make([]interface{}, len(keys))
+	found := make([]bool, len(keys))
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return vals, found
+	}
+
+	qr := &querySession{}
+	for i, k := range keys {
+		id := st.getID(k, qr)
+		if id == -1 {
+			continue
+		}
+		vals[i], found[i] = st.lookupLeaf(id)
+	}
+
+	return vals, found
+}
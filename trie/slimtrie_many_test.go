@@ -0,0 +1,172 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_HasMany(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := marshalCase.values
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, nil, nil)
+		ta.NoError(err)
+
+		ta.Equal([]bool{false, false}, st.HasMany([]string{"a", ""}))
+		ta.Equal([]bool{}, st.HasMany(nil))
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ta.Equal([]bool{true, true, true, true, true, true, true, true}, st.HasMany(keys))
+
+		qs := []string{"cde", "ab", "abc", "", "abcde", "bcde", "zz"}
+		ta.Equal([]bool{true, false, true, false, false, true, false}, st.HasMany(qs))
+	})
+
+	t.Run("sameAsGetID", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, keys, values)
+		ta.NoError(err)
+
+		qs := append(testutil.RandStrSlice(100, 0, 10), keys...)
+		rst := st.HasMany(qs)
+		for i, k := range qs {
+			ta.Equal(st.GetID(k) != -1, rst[i], "key: %q", k)
+		}
+	})
+}
+
+func TestSlimTrie_Has(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"abc", "abd", "b"}
+	values := []interface{}{int32(1), nil, int32(2)}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{DedupValue: Bool(false)})
+	ta.NoError(err)
+
+	// a key with nil value exists.
+	v, found := st.Get("abd")
+	ta.Nil(v)
+	ta.True(found)
+	ta.True(st.Has("abd"))
+
+	// false positive, the same as Get().
+	_, found = st.Get("abcde")
+	ta.True(found)
+	ta.True(st.Has("abcde"))
+
+	ta.False(st.Has("c"))
+
+	st, err = NewSlimTrie(encode.I32{}, keys, values, Opt{DedupValue: Bool(false), Complete: Bool(true)})
+	ta.NoError(err)
+
+	for _, k := range keys {
+		ta.True(st.Has(k), "key: %q", k)
+	}
+	ta.False(st.Has("abcde"))
+	ta.False(st.Has("ab"))
+}
+
+func TestSlimTrie_GetMany(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := marshalCase.values
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, nil, nil)
+		ta.NoError(err)
+
+		vals, found := st.GetMany([]string{"a", ""})
+		ta.Equal([]interface{}{nil, nil}, vals)
+		ta.Equal([]bool{false, false}, found)
+
+		vals, found = st.GetMany(nil)
+		ta.Equal([]interface{}{}, vals)
+		ta.Equal([]bool{}, found)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		qs := []string{"cde", "ab", "abc", "", "abcde", "bcde", "zz"}
+		vals, found := st.GetMany(qs)
+		ta.Equal([]bool{true, false, true, false, false, true, false}, found)
+		for i, k := range qs {
+			v, _ := st.Get(k)
+			ta.Equal(v, vals[i], "key: %q", k)
+		}
+	})
+
+	t.Run("sameAsGet", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, keys, values)
+		ta.NoError(err)
+
+		qs := append(testutil.RandStrSlice(100, 0, 10), keys...)
+		vals, found := st.GetMany(qs)
+		for i, k := range qs {
+			v, f := st.Get(k)
+			ta.Equal(f, found[i], "key: %q", k)
+			ta.Equal(v, vals[i], "key: %q", k)
+		}
+	})
+}
+
+func BenchmarkSlimTrie_HasMany(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+
+	b.Run("HasMany", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = st.HasMany(keys[:1024])
+		}
+	})
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, k := range keys[:1024] {
+				_, _ = st.Get(k)
+			}
+		}
+	})
+}
+
+func BenchmarkSlimTrie_GetMany(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+
+	b.Run("GetMany", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = st.GetMany(keys[:1024])
+		}
+	})
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, k := range keys[:1024] {
+				_, _ = st.Get(k)
+			}
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package trie
+
+import (
+	"sort"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// NewFromMap creates a SlimTrie from a map of keys to values.
+// Keys are sorted by NewFromMap, in byte order, or by Opt.Collation if it is
+// specified, after normalized by Opt.KeyNormalizer if it is specified.
+// A nil value marks a key without value, the same as a nil element in values
+// passed to NewSlimTrie().
+//
+// Every value is encoded by e before building, and it returns an
+// ErrInvalidValue error if e is nil, or a value can not be encoded by e, such
+// as a value of a type e does not support, or an encoded value e can not
+// decode the size of.
+//
+// Since 0.5.12
+func NewFromMap(m map[string]interface{}, e encode.Encoder, opts ...Opt) (*SlimTrie, error) {
+
+	if e == nil {
+		return nil, errors.Wrap(ErrInvalidValue, "nil encoder")
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	var coll Collation
+	if len(opts) > 0 {
+		coll = withNormalizer(opts[0].KeyNormalizer, opts[0].Collation)
+	}
+
+	if coll != nil {
+		sort.Slice(keys, func(i, j int) bool {
+			return coll.Compare(keys[i], keys[j]) < 0
+		})
+	} else {
+		sort.Strings(keys)
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		v := m[k]
+		if err := checkEncode(e, v); err != nil {
+			return nil, errors.WithMessagef(err, "key %q", k)
+		}
+		values[i] = v
+	}
+
+	return NewSlimTrie(e, keys, values, opts...)
+}
+
+// checkEncode returns an ErrInvalidValue error if v can not be encoded by e,
+// or the size of the encoded v is not what e decodes.
+// A nil v is always valid.
+//
+// Since 0.5.12
+func checkEncode(e encode.Encoder, v interface{}) (err error) {
+
+	if v == nil {
+		return nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Wrapf(ErrInvalidValue, "%T: %v", v, r)
+		}
+	}()
+
+	b := e.Encode(v)
+	if n := e.GetEncodedSize(b); n != len(b) {
+		return errors.Wrapf(ErrInvalidValue, "%T: encoded size %d, decoded size %d",
+			v, len(b), n)
+	}
+	return nil
+}
+
+// ToMap returns all keys and values in a map.
+// Values are nil if SlimTrie does not store values.
+//
+// Reconstructing keys requires complete key information, i.e., a SlimTrie
+// created with Opt{Complete: Bool(true)}.
+// Otherwise it returns an ErrIncomplete error.
+// Keys removed by Opt.DedupValue when creating are not in the map.
+//
+// Every key and value is copied into the map, thus it is meant for a small
+// SlimTrie, such as in a test.
+//
+// Since 0.5.12
+func (st *SlimTrie) ToMap() (map[string]interface{}, error) {
+
+	rst := map[string]interface{}{}
+
+	err := st.IterKV(func(key string, val interface{}) bool {
+		rst[key] = val
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rst, nil
+}
+
+// hasCompleteKeys returns true if both inner prefixes and leaf prefixes are
+// stored, i.e., every key can be rebuilt from the trie.
+//
+// Since 0.5.12
+func (st *SlimTrie) hasCompleteKeys() bool {
+	ns := st.inner
+	// An inner prefix of length-only mode is a fixed size element.
+	return ns.GetLeafPrefixes() != nil && ns.GetInnerPrefixes().GetFixedSize() == 0
+}
@@ -0,0 +1,127 @@
+package trie
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_ToMap(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 10; seed++ {
+
+		st, kvs := RandomTrie(rand.New(rand.NewSource(seed)), 300, 8)
+
+		want := map[string]interface{}{}
+		for k, v := range kvs {
+			want[k] = v
+		}
+
+		got, err := st.ToMap()
+		ta.NoError(err)
+		ta.Equal(want, got, "seed: %d", seed)
+	}
+
+	keys := marshalCase.keys
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		got, err := st.ToMap()
+		ta.NoError(err)
+		ta.Equal(len(keys), len(got))
+		for _, k := range keys {
+			v, ok := got[k]
+			ta.True(ok)
+			ta.Nil(v)
+		}
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		for _, opt := range []Opt{
+			{},
+			{InnerPrefix: Bool(true)},
+			{LeafPrefix: Bool(true)},
+		} {
+			st, err := NewSlimTrie(encode.Int{}, keys, marshalCase.values, opt)
+			ta.NoError(err)
+
+			_, err = st.ToMap()
+			ta.Equal(ErrIncomplete, errors.Cause(err), "opt: %+v", opt)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, nil, nil)
+		ta.NoError(err)
+
+		got, err := st.ToMap()
+		ta.NoError(err)
+		ta.Equal(map[string]interface{}{}, got)
+	})
+}
+
+func TestNewFromMap(t *testing.T) {
+
+	ta := require.New(t)
+
+	m := map[string]interface{}{
+		"cherry": int32(3),
+		"apple":  int32(0),
+		"Banana": int32(1),
+		"banana": nil,
+	}
+
+	st, err := NewFromMap(m, encode.I32{}, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for k, v := range m {
+		got, found := st.Get(k)
+		ta.True(found, "key: %q", k)
+		ta.Equal(v, got, "key: %q", k)
+	}
+
+	got, err := st.ToMap()
+	ta.NoError(err)
+	ta.Equal(m, got)
+
+	t.Run("collation", func(t *testing.T) {
+		st, err := NewFromMap(m, encode.I32{},
+			Opt{Complete: Bool(true), Collation: caseCollation{"test.case.v1"}})
+		ta.NoError(err)
+
+		for k, v := range m {
+			got, found := st.Get(k)
+			ta.True(found, "key: %q", k)
+			ta.Equal(v, got, "key: %q", k)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewFromMap(nil, encode.I32{})
+		ta.NoError(err)
+		ta.Equal(0, st.Len())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewFromMap(m, nil)
+		ta.Equal(ErrInvalidValue, errors.Cause(err))
+
+		// I32 panics encoding an int64.
+		_, err = NewFromMap(map[string]interface{}{"a": int32(1), "b": int64(2)}, encode.I32{})
+		ta.Equal(ErrInvalidValue, errors.Cause(err))
+		ta.Contains(err.Error(), `key "b"`)
+
+		// String16 encodes at most 65535 bytes, and the length is truncated.
+		long := strings.Repeat("x", 1<<16)
+		_, err = NewFromMap(map[string]interface{}{"a": long}, encode.String16{})
+		ta.Equal(ErrInvalidValue, errors.Cause(err))
+	})
+}
@@ -4,39 +4,226 @@ import (
 	"bytes"
 	"encoding/binary"
 	fmt "fmt"
+	"hash/crc32"
+	"io"
 	"math/bits"
 	"strings"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/openacid/errors"
 	"github.com/openacid/low/bitmap"
 	"github.com/openacid/low/bitstr"
 	"github.com/openacid/low/pbcmpl"
 	"github.com/openacid/low/vers"
-	"github.com/openacid/must"
 	"github.com/openacid/slim/array"
+	"github.com/openacid/slim/encode"
 )
 
 // Marshal serializes it to byte stream.
 //
+// The output is deterministic: two SlimTrie created with the same keys, values
+// and options produce identical bytes, unless it is built with
+// Opt.WithBuildTime.
+//
 // Since 0.4.3
 func (st *SlimTrie) Marshal() ([]byte, error) {
 	var buf []byte
 	writer := bytes.NewBuffer(buf)
 
-	_, err := pbcmpl.Marshal(writer, st.inner)
+	_, err := st.WriteTo(writer)
+	if err != nil {
+		return nil, err
+	}
+
+	return writer.Bytes(), nil
+}
+
+// WriteTo implements io.WriterTo and writes the same bytes as Marshal() to w.
+// It returns the number of bytes written.
+//
+// The bytes are written directly to w without being copied into a buffer
+// first, which reduces peak memory when persisting a large SlimTrie.
+// The encoded payload is still built in memory once by protobuf before being
+// written.
+//
+// Since 0.5.12
+func (st *SlimTrie) WriteTo(w io.Writer) (int64, error) {
+
+	ns, err := st.fullInner()
+	if err != nil {
+		return 0, err
+	}
+
+	return writeSlim(w, ns)
+}
+
+// writeSlim writes ns to w in the format of Marshal(), followed by a
+// checksum footer if ns is built with Opt.WithChecksum.
+//
+// Since 0.5.12
+func writeSlim(w io.Writer, ns *Slim) (int64, error) {
+
+	if !ns.OptWithChecksum {
+		n, err := pbcmpl.Marshal(w, ns)
+		if err != nil {
+			return n, errors.WithMessage(err, "failed to marshal st.inner")
+		}
+		return n, nil
+	}
+
+	crc := crc32.New(crc32cTable)
+
+	n, err := pbcmpl.Marshal(io.MultiWriter(w, crc), ns)
+	if err != nil {
+		return n, errors.WithMessage(err, "failed to marshal st.inner")
+	}
+
+	m, err := writeChecksum(w, crc.Sum32())
+	return n + m, err
+}
+
+// MarshalCanonical serializes it to the smallest deterministic byte stream:
+// Indexes of bitmaps, such as rank index and select index, are not written,
+// since they can be rebuilt from the bitmaps.
+// Unmarshal() rebuilds them when loading.
+// Neither are the build time and the recorded build options that do not
+// change how queries behave, such as Opt.SelfCheck, see BuildOptions().
+//
+// Two SlimTrie created with the same keys, values and options produce
+// identical canonical bytes, and so do two SlimTrie that differ only in build
+// options such as Opt.SelfCheck.
+// The canonical bytes are stable for a format version, i.e., FormatVersion(),
+// which is written in the header.
+// A different format version may produce different canonical bytes.
+//
+// Since 0.5.12
+func (st *SlimTrie) MarshalCanonical() ([]byte, error) {
+	var buf []byte
+	writer := bytes.NewBuffer(buf)
+
+	ns, err := st.fullInner()
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to marshal st.inner")
+		return nil, err
+	}
+
+	_, err = pbcmpl.Marshal(writer, canonicalSlim(ns))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to marshal canonical st.inner")
+	}
+
+	if ns.OptWithChecksum {
+		_, err = writeChecksum(writer, crc32.Checksum(writer.Bytes(), crc32cTable))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return writer.Bytes(), nil
 }
 
+// canonicalSlim returns a shallow copy of ns without bitmap indexes, the
+// build time and the build options that do not change how queries behave.
+func canonicalSlim(ns *Slim) *Slim {
+	c := *ns
+	// the build time differs in every build of the same keys and values.
+	c.BuiltAt = 0
+
+	// Recorded build options that do not change how queries behave are not
+	// written. Opt.WithTerminator, Opt.CheckKeyLen and Opt.WithChecksum are
+	// kept.
+	c.HasBuildOpt = false
+	c.OptDedupValue = false
+	c.OptInnerPrefix = false
+	c.OptLeafPrefix = false
+	c.OptComplete = false
+	c.OptSelfCheck = false
+	c.OptNoShortTable = false
+	c.OptLeafBlockSize = 0
+	c.OptWithFoldedIndex = false
+	c.OptLeafExceptions = false
+	c.OptBigThreshold = 0
+	c.OptNoInnerPrefix = false
+
+	c.NodeTypeBM = canonicalBitmap(ns.NodeTypeBM)
+	c.Inners = canonicalBitmap(ns.Inners)
+	c.ShortBM = canonicalBitmap(ns.ShortBM)
+	c.InnerPrefixes = canonicalVLenArray(ns.InnerPrefixes)
+	c.LeafPrefixes = canonicalVLenArray(ns.LeafPrefixes)
+	c.Leaves = canonicalVLenArray(ns.Leaves)
+	if ns.Folded != nil {
+		c.Folded = canonicalSlim(ns.Folded)
+	}
+	return &c
+}
+
+func canonicalBitmap(b *Bitmap) *Bitmap {
+	if b == nil {
+		return nil
+	}
+	return &Bitmap{Words: b.Words}
+}
+
+func canonicalVLenArray(va *VLenArray) *VLenArray {
+	if va == nil {
+		return nil
+	}
+	c := *va
+	c.PresenceBM = canonicalBitmap(va.PresenceBM)
+	c.PositionBM = canonicalBitmap(va.PositionBM)
+	c.ExceptionBM = canonicalBitmap(va.ExceptionBM)
+	return &c
+}
+
+// rebuildIndexes rebuilds bitmap indexes that are not written by
+// MarshalCanonical().
+// The kind of index of every bitmap is the same as creator.build() uses.
+func rebuildIndexes(ns *Slim) {
+	indexIfAbsent(ns.NodeTypeBM, "r64")
+	indexIfAbsent(ns.Inners, "r128")
+	indexIfAbsent(ns.ShortBM, "r64")
+	if ns.InnerPrefixes != nil {
+		indexIfAbsent(ns.InnerPrefixes.PresenceBM, "r128")
+		indexIfAbsent(ns.InnerPrefixes.PositionBM, "s32")
+	}
+	if ns.LeafPrefixes != nil {
+		indexIfAbsent(ns.LeafPrefixes.PresenceBM, "r64")
+		indexIfAbsent(ns.LeafPrefixes.PositionBM, "s32")
+	}
+	if ns.Leaves != nil {
+		indexIfAbsent(ns.Leaves.PresenceBM, "r64")
+		indexIfAbsent(ns.Leaves.PositionBM, "s32")
+		indexIfAbsent(ns.Leaves.ExceptionBM, "r64")
+	}
+	if ns.Folded != nil {
+		rebuildIndexes(ns.Folded)
+	}
+}
+
+func indexIfAbsent(b *Bitmap, opt string) {
+	if b == nil || len(b.RankIndex) > 0 {
+		return
+	}
+	b.indexit(opt)
+}
+
 // Unmarshal a SlimTrie from a byte stream.
 //
+// If the data has a checksum footer written with Opt.WithChecksum, it is
+// verified and an ErrCorrupt error is returned if it does not match.
+//
+// The structure of the loaded data is checked too, thus malformed data makes
+// it return an ErrCorrupt error instead of a SlimTrie that panics or reads out
+// of range when queried.
+// Values are not checked: decoding a malformed value is up to the encoder.
+//
+// Data of a version newer than FormatVersion(), which may have fields this
+// version does not know about, returns an ErrIncompatible error.
+//
 // Since 0.4.3
 func (st *SlimTrie) Unmarshal(buf []byte) error {
 
 	st.inner = &Slim{}
+	st.lazyLeaves = nil
 
 	reader := bytes.NewReader(buf)
 
@@ -55,23 +242,59 @@ func (st *SlimTrie) Unmarshal(buf []byte) error {
 				strings.Join(compatible, " || ")))
 	}
 
+	end := h.GetHeaderSize() + h.GetBodySize()
+	if h.GetBodySize() < 0 || end > int64(len(buf)) {
+		return errors.Wrapf(ErrCorrupt, "body ends at %d, beyond size %d", end, len(buf))
+	}
+
+	hasChecksum, err := verifyChecksum(buf, end)
+	if err != nil {
+		return err
+	}
+
 	reader = bytes.NewReader(buf)
 
-	// 0.5.10 and 0.5.11 share the same protobuf format:
+	// 0.5.10 and later share the same protobuf format.
+	// Fields added since 0.5.13 are absent in data of an older version.
 
-	if vers.Check(ver, slimtrieVersion, "==0.5.10", "==0.5.11") {
+	if vers.Check(ver, slimtrieVersion, "==0.5.10", "==0.5.11", "==0.5.12") {
 		_, _, err := pbcmpl.Unmarshal(reader, st.inner)
 		if err != nil {
 			return errors.WithMessage(err, "failed to unmarshal inner")
 		}
 
+		if st.inner.OptWithChecksum && !hasChecksum {
+			return errors.Wrap(ErrCorrupt, "checksum footer is missing")
+		}
+
 		if vers.Check(ver, "<0.5.12") {
-			before000512InnerPrefixTobitstr(st)
-			before000512FixLeafSize(st)
+			err = before000512InnerPrefixTobitstr(st)
+			if err != nil {
+				return errors.WithMessagef(err, "failed to convert inner prefixes of version %s", ver)
+			}
+			err = before000512FixLeafSize(st)
+			if err != nil {
+				return errors.WithMessagef(err, "failed to convert leaves of version %s", ver)
+			}
+		} else {
+			rebuildIndexes(st.inner)
 		}
 
-		st.init()
-		return nil
+		err = validateSlim(st.inner)
+		if err != nil {
+			return errors.WithMessage(err, "invalid data")
+		}
+
+		if st.encoder == nil && st.inner.ValueType != "" {
+			st.encoder, _ = encode.Lookup(st.inner.ValueType)
+		}
+
+		err = st.loadCollation()
+		if err != nil {
+			return err
+		}
+
+		return st.init()
 	}
 
 	// ver: "==1.0.0 || <0.5.10"
@@ -81,29 +304,59 @@ func (st *SlimTrie) Unmarshal(buf []byte) error {
 	leaves := &array.Array{}
 	leaves.EltEncoder = st.encoder
 
-	_, _, err = pbcmpl.Unmarshal(reader, children)
+	err = unmarshalMsg(reader, children)
 	if err != nil {
 		return errors.WithMessage(err, "failed to unmarshal children")
 	}
 
-	_, _, err = pbcmpl.Unmarshal(reader, steps)
+	err = unmarshalMsg(reader, steps)
 	if err != nil {
 		return errors.WithMessage(err, "failed to unmarshal steps")
 	}
 
-	_, _, err = pbcmpl.Unmarshal(reader, leaves)
+	err = unmarshalMsg(reader, leaves)
 	if err != nil {
 		return errors.WithMessage(err, "failed to unmarshal leaves")
 	}
 
 	// backward compatible:
 
-	before000510(st, ver, children, steps, leaves)
+	err = before000510(st, ver, children, steps, leaves)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to convert data of version %s", ver)
+	}
 
 	return nil
 }
 
-func before000512InnerPrefixTobitstr(st *SlimTrie) {
+// unmarshalMsg unmarshals a header and the following message from r.
+// It returns an ErrCorrupt error if the body size in the header is beyond the
+// end of r, instead of allocating a buffer of a broken size.
+//
+// Since 0.5.12
+func unmarshalMsg(r *bytes.Reader, msg proto.Message) error {
+
+	off := r.Size() - int64(r.Len())
+
+	_, h, err := pbcmpl.ReadHeader(r)
+	if err != nil {
+		return errors.WithMessage(err, "failed to read header")
+	}
+
+	if h.GetBodySize() < 0 || h.GetBodySize() > int64(r.Len()) {
+		return errors.Wrapf(ErrCorrupt, "body size %d at %d exceeds %d", h.GetBodySize(), off, r.Size())
+	}
+
+	_, err = r.Seek(off, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = pbcmpl.Unmarshal(r, msg)
+	return err
+}
+
+func before000512InnerPrefixTobitstr(st *SlimTrie) error {
 
 	ips := st.inner.InnerPrefixes
 
@@ -112,11 +365,17 @@ func before000512InnerPrefixTobitstr(st *SlimTrie) {
 		// convert control-byte followed by text format to text followed by
 		// trailing byte format.
 
-		pbm := ips.PositionBM
+		// positions of the start of every prefix, and the end of the last.
+		// They are read from the bitmap words, thus a broken select index
+		// does not matter.
+		poss := bitmap.ToArray(ips.PositionBM.Words)
 
-		for i := int32(0); ; i++ {
+		for i := 0; i+1 < len(poss); i++ {
 
-			from, to := bitmap.Select32R64(pbm.Words, pbm.SelectIndex, pbm.RankIndex, i)
+			from, to := poss[i], poss[i+1]
+			if to > int32(len(ips.Bytes)) {
+				return errors.Wrapf(ErrCorrupt, "inner prefix position %d exceeds %d", to, len(ips.Bytes))
+			}
 
 			old := ips.Bytes[from:to]
 
@@ -128,19 +387,26 @@ func before000512InnerPrefixTobitstr(st *SlimTrie) {
 				last := old[pl]
 				nZero := int32(bits.TrailingZeros8(last))
 				bitLen = pl<<3 - nZero - 1
+				if bitLen < 0 {
+					return errors.Wrapf(ErrCorrupt, "invalid inner prefix at %d", from)
+				}
 			}
 
 			newPref := bitstr.New(string(old[1:]), 0, bitLen)
 			copy(old, newPref)
 
 			if to == int32(len(ips.Bytes)) {
-				break
+				return nil
 			}
 		}
+
+		return errors.Wrapf(ErrCorrupt, "inner prefixes do not end at %d", len(ips.Bytes))
 	}
+
+	return nil
 }
 
-func before000512FixLeafSize(st *SlimTrie) {
+func before000512FixLeafSize(st *SlimTrie) error {
 
 	// Before d27f7e9 2021-04-29, no fixed size or var-len size are written to Leaves.
 	// Only Leaves.Bytes are written.
@@ -148,16 +414,23 @@ func before000512FixLeafSize(st *SlimTrie) {
 	leaves := st.inner.Leaves
 
 	if leaves == nil {
-		return
+		return nil
 	}
 
 	// PresenceBM is non-nil after d27f7e9 2021-04-29, or it has to be fixed.
 	if leaves.PresenceBM == nil {
 		if leaves.FixedSize != 0 {
-			panic("impossible FixedSize is non-zero while PresenceBM is nil")
+			return errors.Wrapf(ErrCorrupt, "FixedSize is %d while PresenceBM is nil", leaves.FixedSize)
+		}
+
+		if st.encoder == nil {
+			return errors.Wrap(ErrInvalidValue, "an encoder is required to load leaves without size")
 		}
 
 		leaves.FixedSize = int32(st.encoder.GetEncodedSize(nil))
+		if leaves.FixedSize <= 0 {
+			return errors.Wrapf(ErrInvalidValue, "encoder of size %d can not load leaves without size", leaves.FixedSize)
+		}
 
 		n := int32(len(leaves.Bytes)) / leaves.FixedSize
 		leaves.N = n
@@ -170,6 +443,7 @@ func before000512FixLeafSize(st *SlimTrie) {
 		leaves.PresenceBM = newBM(indexes, n, "r64")
 	}
 
+	return nil
 }
 
 // ProtoMessage implements proto.Message
@@ -179,21 +453,26 @@ func (st *SlimTrie) ProtoMessage() {}
 
 // Reset implements proto.Message
 //
+// A SlimTrie after Reset() behaves the same as one created with no key.
+// The encoder is kept.
+//
 // Since 0.4.3
 func (st *SlimTrie) Reset() {
 	st.inner = &Slim{}
-	st.vars = nil
-	st.levels = []levelInfo{{0, 0, 0, nil}}
+	st.lazyLeaves = nil
+	st.collation = nil
+	// an empty SlimTrie is always valid.
+	_ = st.init()
 }
 
-func before000510(st *SlimTrie, ver string, ch *array.Array32, steps *array.U16, lvs *array.Array) {
+func before000510(st *SlimTrie, ver string, ch *array.Array32, steps *array.U16, lvs *array.Array) error {
 	if !vers.Check(ver, "==1.0.0", "<0.5.10") {
-		return
+		return nil
 	}
-	before000510ToNewChildrenArray(st, ver, ch, steps, lvs)
+	return before000510ToNewChildrenArray(st, ver, ch, steps, lvs)
 }
 
-func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32, steps *array.U16, lvs *array.Array) {
+func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32, steps *array.U16, lvs *array.Array) error {
 
 	// 1.0.0 is the initial version.
 	// From 0.5.8 it starts writing version to marshaled data.
@@ -202,6 +481,30 @@ func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32,
 
 	if vers.Check(ver, "==1.0.0", "<0.5.10") {
 
+		if st.encoder == nil {
+			return errors.Wrap(ErrInvalidValue, "an encoder is required to load leaves without size")
+		}
+		leafSize := st.encoder.GetEncodedSize(nil)
+
+		// Check the arrays so that reading an element does not go out of
+		// range.
+		chEltSize := int32(4)
+		if ch.Flags&array.ArrayFlagIsBitmap != 0 {
+			chEltSize = 0
+		}
+		err := checkArrayBefore000510(ch, chEltSize)
+		if err != nil {
+			return errors.WithMessage(err, "invalid children")
+		}
+		err = checkArrayBefore000510(&steps.Array32, 2)
+		if err != nil {
+			return errors.WithMessage(err, "invalid steps")
+		}
+		err = checkArrayBefore000510(&lvs.Array32, int32(leafSize))
+		if err != nil {
+			return errors.WithMessage(err, "invalid leaves")
+		}
+
 		// rebuild inner
 
 		type eltType struct {
@@ -234,13 +537,16 @@ func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32,
 			hasLeaf := bmhas(lvs.Bitmaps, oldid)
 
 			// it could be an empty slimtrie.
-			must.Be.True(hasInner || hasLeaf || (len(ch.Bitmaps) == 0 && len(lvs.Bitmaps) == 0))
+			if !hasInner && !hasLeaf && (len(ch.Bitmaps) != 0 || len(lvs.Bitmaps) != 0) {
+				return errors.Wrapf(ErrCorrupt, "node %d is neither inner nor leaf", oldid)
+			}
 
 			if qelt.leafOnly || (!hasInner && hasLeaf) {
-				must.Be.OK(func() { bmhas(lvs.Bitmaps, oldid) })
 
-				lv, found := lvs.GetBytes(oldid, st.encoder.GetEncodedSize(nil))
-				must.Be.True(found)
+				lv, found := lvs.GetBytes(oldid, leafSize)
+				if !found {
+					return errors.Wrapf(ErrCorrupt, "leaf of node %d is not found", oldid)
+				}
 
 				c.addLeaf(newid, lv)
 				continue
@@ -251,7 +557,10 @@ func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32,
 			}
 
 			// 16-bit bitmap is same with bmtree bitmap of size =16
-			bm := getBM16Child(ch, oldid)
+			bm, err := getBM16Child(ch, oldid)
+			if err != nil {
+				return err
+			}
 
 			if hasLeaf {
 				// "" is a explicit branch in 0.5.10
@@ -280,6 +589,10 @@ func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32,
 				bm |= 1
 			}
 
+			if bm == 0 {
+				return errors.Wrapf(ErrCorrupt, "inner node %d has no child", oldid)
+			}
+
 			bmidx := bitmap.ToArray([]uint64{bm})
 
 			c.addInner(newid, bmidx, innerSize, 0, qelt.step, "")
@@ -289,14 +602,17 @@ func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32,
 		ns.Leaves = c.buildLeaves(nil)
 
 		st.inner = ns
-		st.init()
+		return st.init()
 	}
+
+	return nil
 }
 
+// getStepBefore000510 returns the step of a node in bit.
+// steps must be checked by checkArrayBefore000510().
 func getStepBefore000510(steps *array.U16, nid int32) int32 {
 	if bmhas(steps.Bitmaps, nid) {
-		stp, found := steps.Get(nid)
-		must.Be.True(found)
+		stp, _ := steps.Get(nid)
 
 		// From 0.5.10 step does not include the count of the label word.
 		stp--
@@ -312,7 +628,9 @@ func bmhas(bm []uint64, i int32) bool {
 	return bitmap.SafeGet1(bm, i) == 1
 }
 
-func getBM16Child(ch *array.Array32, idx int32) uint64 {
+// getBM16Child returns the 17-bit bitmap of the children of a node.
+// ch must be checked by checkArrayBefore000510().
+func getBM16Child(ch *array.Array32, idx int32) (uint64, error) {
 
 	// There are two format with version 1.0.0:
 	// Before 0.5.4 Child elements are in Elts, every elt is uint32:
@@ -322,8 +640,11 @@ func getBM16Child(ch *array.Array32, idx int32) uint64 {
 
 	endian := binary.LittleEndian
 
-	eltIdx, bitset := bitmap.Rank64(ch.Bitmaps, ch.Offsets, idx)
-	must.Be.True(bitset == 1, "node must be in children array")
+	if !bmhas(ch.Bitmaps, idx) {
+		return 0, errors.Wrapf(ErrCorrupt, "node %d is not in children array", idx)
+	}
+
+	eltIdx, _ := bitmap.Rank64(ch.Bitmaps, ch.Offsets, idx)
 
 	var bm uint64
 
@@ -342,5 +663,39 @@ func getBM16Child(ch *array.Array32, idx int32) uint64 {
 	}
 
 	// add leaf bit, thus the size is 17
-	return bm << 1
+	return bm << 1, nil
+}
+
+// checkArrayBefore000510 checks an array of the format before 0.5.10, so that
+// reading an element does not go out of range.
+// eltSize is the size in byte of an element in Elts, or 0 if elements are
+// 16-bit bitmaps in BMElts.
+//
+// Since 0.5.12
+func checkArrayBefore000510(a *array.Array32, eltSize int32) error {
+
+	if len(a.Offsets) < len(a.Bitmaps) {
+		return errors.Wrapf(ErrCorrupt, "%d offsets for %d bitmap words", len(a.Offsets), len(a.Bitmaps))
+	}
+
+	// Offsets are the ranks of bitmap words, except it is 0 for an empty word.
+	cnt := int64(0)
+	for i, w := range a.Bitmaps {
+		if w != 0 && int64(a.Offsets[i]) != cnt {
+			return errors.Wrapf(ErrCorrupt, "offset of %d-th word is %d, expected %d", i, a.Offsets[i], cnt)
+		}
+		cnt += int64(bits.OnesCount64(w))
+	}
+
+	if eltSize == 0 {
+		if cnt > 0 && (a.BMElts == nil || int64(len(a.BMElts.Words))*64 < cnt*16) {
+			return errors.Wrapf(ErrCorrupt, "BMElts is too short for %d elements", cnt)
+		}
+		return nil
+	}
+
+	if int64(len(a.Elts)) < cnt*int64(eltSize) {
+		return errors.Wrapf(ErrCorrupt, "%d bytes for %d elements of size %d", len(a.Elts), cnt, eltSize)
+	}
+	return nil
 }
@@ -2,6 +2,9 @@ package trie
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
@@ -58,7 +61,8 @@ func TestSlimTrie_Unmarshal_incompatible(t *testing.T) {
 		want  error
 	}{
 		{slimtrieVersion, nil},
-		{"0.5.13", ErrIncompatible},
+		{"0.5.12", nil},
+		{"0.5.14", ErrIncompatible},
 		{"0.6.0", ErrIncompatible},
 		{"0.9.9", ErrIncompatible},
 		{"1.0.1", ErrIncompatible},
@@ -140,17 +144,109 @@ func TestSlimTrie_MarshalUnmarshal(t *testing.T) {
 	st2.Reset()
 	empty := &SlimTrie{
 		encoder: encode.Int{},
-		vars:    nil,
-		levels:  []levelInfo{{0, 0, 0, nil}},
 		inner:   &Slim{},
 	}
+	empty.init()
 	ta.Equal(empty, st2, "reset")
+	ta.True(st2.IsEmpty())
 
 	// ensure slimtrie.String()
 
 	_ = st1.String()
 }
 
+func TestSlimTrie_ValueType(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := makeI32s(len(keys))
+
+	st0, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+	ta.Equal("", st0.ValueType())
+
+	encode.Register("github.com/openacid/slim/trie.testValueType", encode.I32{})
+
+	// nil encoder: use the registered one.
+	st1, err := NewSlimTrie(nil, keys, values,
+		Opt{ValueType: "github.com/openacid/slim/trie.testValueType"})
+	ta.NoError(err)
+	ta.Equal("github.com/openacid/slim/trie.testValueType", st1.ValueType())
+	testPresentKeysGet(t, st1, keys, values)
+
+	buf, err := st1.Marshal()
+	ta.NoError(err)
+
+	// load without encoder: use the registered one.
+	st2, err := NewSlimTrie(nil, nil, nil)
+	ta.NoError(err)
+
+	err = proto.Unmarshal(buf, st2)
+	ta.NoError(err)
+	ta.Equal("github.com/openacid/slim/trie.testValueType", st2.ValueType())
+	testPresentKeysGet(t, st2, keys, values)
+
+	// an explicitly specified encoder is not overridden.
+	st3, err := NewSlimTrie(encode.Dummy{}, nil, nil)
+	ta.NoError(err)
+
+	err = proto.Unmarshal(buf, st3)
+	ta.NoError(err)
+	v, found := st3.Get(keys[1])
+	ta.True(found)
+	ta.Nil(v)
+}
+
+func TestSlimTrie_SetEncoder(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+	ta.Equal(encode.I32{}, st.Encoder())
+
+	buf, err := st.Marshal()
+	ta.NoError(err)
+
+	st2, err := NewSlimTrie(nil, nil, nil)
+	ta.NoError(err)
+	ta.Nil(st2.Encoder())
+
+	st2.SetEncoder(encode.I32{})
+	ta.NoError(st2.Unmarshal(buf))
+	ta.Equal(encode.I32{}, st2.Encoder())
+	testPresentKeysGet(t, st2, keys, values)
+
+	// Replacing it after loading changes how values are decoded.
+	st2.SetEncoder(encode.Dummy{})
+	v, found := st2.Get(keys[1])
+	ta.True(found)
+	ta.Nil(v)
+
+	t.Run("beforeUnmarshalOldData", func(t *testing.T) {
+		ta := require.New(t)
+
+		// data of 0.5.10 does not record the size of a value.
+		buf, err := ioutil.ReadFile("testdata/slimtrie-data-10vl5-nopref-0.5.10")
+		ta.NoError(err)
+
+		st, err := NewSlimTrie(nil, nil, nil)
+		ta.NoError(err)
+		err = st.Unmarshal(buf)
+		ta.Equal(ErrInvalidValue, errors.Cause(err))
+
+		st.SetEncoder(encode.I32{})
+		ta.NoError(st.Unmarshal(buf))
+		v, found := st.Get(getKeys("10vl5")[3])
+		ta.True(found)
+		ta.Equal(int32(3), v)
+	})
+}
+
 func TestSlimTrie_Marshal_allkeys(t *testing.T) {
 
 	testBigKeySet(t, func(t *testing.T, typ string, keys []string) {
@@ -202,6 +298,246 @@ func TestSlimTrie_Marshal_allkeys(t *testing.T) {
 	})
 }
 
+// errWriter fails after n bytes are written.
+type errWriter struct {
+	n int
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("errWriter: full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestSlimTrie_WriteTo(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	want, err := st.Marshal()
+	ta.NoError(err)
+
+	var _ io.WriterTo = st
+
+	w := &bytes.Buffer{}
+	n, err := st.WriteTo(w)
+	ta.NoError(err)
+	ta.Equal(int64(len(want)), n)
+	ta.Equal(want, w.Bytes())
+
+	st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st2.Unmarshal(w.Bytes()))
+	testPresentKeysGet(t, st2, keys, values)
+
+	t.Run("writeError", func(t *testing.T) {
+		ta := require.New(t)
+
+		n, err := st.WriteTo(&errWriter{n: 100})
+		ta.Error(err)
+		ta.Equal(int64(100), n)
+	})
+}
+
+func TestSlimTrie_MarshalCanonical(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{{}, {Complete: Bool(true)}} {
+
+		st1, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		can1, err := st1.MarshalCanonical()
+		ta.NoError(err)
+		can2, err := st2.MarshalCanonical()
+		ta.NoError(err)
+		ta.Equal(can1, can2)
+
+		buf, err := st1.Marshal()
+		ta.NoError(err)
+		ta.True(len(can1) < len(buf), "canonical: %d, marshal: %d", len(can1), len(buf))
+
+		// header version is the format version
+		_, h, err := pbcmpl.ReadHeader(bytes.NewReader(can1))
+		ta.NoError(err)
+		ta.Equal(st1.FormatVersion(), h.GetVersion())
+
+		// load canonical bytes and indexes are rebuilt
+		st3, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		err = st3.Unmarshal(can1)
+		ta.NoError(err)
+		// build options are not written
+		ta.True(st1.Equal(st3))
+		ta.Equal(st1.levels, st3.levels)
+		testPresentKeysGet(t, st3, keys, values)
+
+		can3, err := st3.MarshalCanonical()
+		ta.NoError(err)
+		ta.Equal(can1, can3)
+
+		// canonical bytes from a loaded SlimTrie are the same
+		st4, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		err = st4.Unmarshal(buf)
+		ta.NoError(err)
+
+		can4, err := st4.MarshalCanonical()
+		ta.NoError(err)
+		ta.Equal(can1, can4)
+	}
+
+	t.Run("buildOnlyOpt", func(t *testing.T) {
+		ta := require.New(t)
+
+		st1, err := NewSlimTrie(encode.I32{}, keys, values, Opt{SelfCheck: Bool(true)})
+		ta.NoError(err)
+		st2, err := NewSlimTrie(encode.I32{}, keys, values, Opt{SelfCheck: Bool(false)})
+		ta.NoError(err)
+
+		ta.Equal(mustMarshalCanonical(st1), mustMarshalCanonical(st2))
+
+		// options that change how queries behave are kept
+		st3, err := NewSlimTrie(encode.I32{}, keys, values,
+			Opt{Complete: Bool(true), WithTerminator: Bool(true), CheckKeyLen: Bool(true)})
+		ta.NoError(err)
+
+		st4, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st4.Unmarshal(mustMarshalCanonical(st3)))
+
+		opt := st4.BuildOptions()
+		ta.Equal(Bool(true), opt.WithTerminator)
+		ta.Equal(Bool(true), opt.CheckKeyLen)
+		ta.Nil(opt.SelfCheck)
+		testPresentKeysGet(t, st4, keys, values)
+	})
+
+	t.Run("buildTime", func(t *testing.T) {
+		ta := require.New(t)
+
+		opt := Opt{WithBuildTime: Bool(true)}
+		st1, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+		st2, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+		ta.NotEqual(st1.inner.BuiltAt, st2.inner.BuiltAt)
+
+		ta.Equal(mustMarshalCanonical(st1), mustMarshalCanonical(st2))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		can, err := st.MarshalCanonical()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		err = st2.Unmarshal(can)
+		ta.NoError(err)
+		ta.True(st.Equal(st2))
+	})
+}
+
+func TestSlimTrie_Marshal_deterministic(t *testing.T) {
+
+	keys := getKeys("20kvl10")
+	strs := makeOutlierStrs(len(keys), 100)
+	values := make([]interface{}, len(keys))
+	for i := range keys {
+		values[i] = strs[i]
+	}
+
+	cases := []struct {
+		name string
+		enc  encode.Encoder
+		vals interface{}
+		opt  Opt
+	}{
+		{"default", encode.I32{}, makeI32s(len(keys)), Opt{}},
+		{"complete", encode.I32{}, makeI32s(len(keys)), Opt{Complete: Bool(true)}},
+		{"noShortTable", encode.I32{}, makeI32s(len(keys)), Opt{NoShortTable: Bool(true)}},
+		{"bigInner", encode.I32{}, makeI32s(len(keys)), Opt{BigThreshold: 1}},
+		{"varlen", encode.String16{}, values, Opt{}},
+		{"block", encode.String16{}, values, Opt{LeafBlockSize: 16}},
+		{"exceptions", encode.String16{}, values, Opt{LeafExceptions: Bool(true)}},
+		{"folded", encode.I32{}, makeI32s(len(keys)), Opt{WithFoldedIndex: Bool(true)}},
+		{"noValue", nil, nil, Opt{DedupValue: Bool(false)}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			ta := require.New(t)
+
+			st1, err := NewSlimTrie(c.enc, keys, c.vals, c.opt)
+			ta.NoError(err)
+			st2, err := NewSlimTrie(c.enc, keys, c.vals, c.opt)
+			ta.NoError(err)
+
+			b1, err := st1.Marshal()
+			ta.NoError(err)
+			b2, err := st2.Marshal()
+			ta.NoError(err)
+			ta.Equal(b1, b2)
+
+			// a loaded SlimTrie produces the same bytes
+			st3, err := NewSlimTrie(c.enc, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st3.Unmarshal(b1))
+
+			b3, err := st3.Marshal()
+			ta.NoError(err)
+			ta.Equal(b1, b3)
+		})
+	}
+
+	t.Run("fromMap", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		// map iteration order does not affect the output
+		m := map[string]interface{}{}
+		for i, k := range keys {
+			m[k] = int32(i)
+		}
+
+		var first []byte
+		for i := 0; i < 5; i++ {
+			st, err := NewFromMap(m, encode.I32{})
+			ta.NoError(err)
+
+			b, err := st.Marshal()
+			ta.NoError(err)
+			if first == nil {
+				first = b
+			}
+			ta.Equal(first, b)
+		}
+	})
+}
+
 func TestSlimTrie_Unmarshal_old_data(t *testing.T) {
 
 	testOldData(t,
@@ -217,6 +553,8 @@ func TestSlimTrie_Unmarshal_old_data(t *testing.T) {
 			err = proto.Unmarshal(buf, st)
 			ta.NoError(err)
 
+			ta.Equal(len(keys), st.Len())
+
 			// < 0.5.10: slimtrie-data-10ll16k-0.5.9
 			// => 0.5.10: slimtrie-data-10ll16k-allpref-0.5.10
 
@@ -254,6 +592,42 @@ func TestSlimTrie_Unmarshal_old_data(t *testing.T) {
 		})
 }
 
+func TestSlimTrie_Marshal_upgrade_old_data(t *testing.T) {
+
+	testOldData(t,
+		func(t *testing.T,
+			dataSetName, dataOpt, ver string,
+			keys []string,
+			buf []byte) {
+
+			ta := require.New(t)
+
+			st, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+
+			err = proto.Unmarshal(buf, st)
+			ta.NoError(err)
+
+			ta.Equal(slimtrieVersion, st.FormatVersion())
+
+			upgraded, err := st.Marshal()
+			ta.NoError(err)
+
+			_, h, err := pbcmpl.ReadHeader(bytes.NewBuffer(upgraded))
+			ta.NoError(err)
+			ta.Equal(slimtrieVersion, h.GetVersion(), "upgraded from %s", ver)
+
+			st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+
+			err = proto.Unmarshal(upgraded, st2)
+			ta.NoError(err)
+
+			slimtrieEqual(st, st2, t)
+			testPresentKeysGRS(t, st2, keys, makeI32s(len(keys)))
+		})
+}
+
 // Just keeps old test.
 func TestSlimTrie_Unmarshal_0_5_0(t *testing.T) {
 
@@ -357,3 +731,81 @@ func TestSlimTrie_Unmarshal_0_5_3(t *testing.T) {
 		ta.Equal(ex.want, rst)
 	}
 }
+
+func TestSlimTrie_Unmarshal_malformed(t *testing.T) {
+
+	ta := require.New(t)
+
+	bufs := map[string][]byte{}
+
+	finfos, err := ioutil.ReadDir("testdata/")
+	ta.NoError(err)
+	for _, finfo := range finfos {
+		fn := finfo.Name()
+		if strings.HasPrefix(fn, "slimtrie-data-10vl5-") || strings.HasPrefix(fn, "slimtrie-data-empty-") {
+			b, err := ioutil.ReadFile("testdata/" + fn)
+			ta.NoError(err)
+			bufs[fn] = b
+		}
+	}
+
+	st, err := NewSlimTrie(encode.I32{}, marshalCase.keys, makeI32s(len(marshalCase.keys)))
+	ta.NoError(err)
+	bufs["current"], err = st.Marshal()
+	ta.NoError(err)
+
+	for name, buf := range bufs {
+		t.Run(name, func(t *testing.T) {
+
+			ta := require.New(t)
+
+			unmarshal := func(b []byte) {
+				st, err := NewSlimTrie(encode.I32{}, nil, nil)
+				ta.NoError(err)
+				if st.Unmarshal(b) == nil {
+					queryLoaded(st)
+				}
+			}
+
+			for i := 0; i < len(buf); i++ {
+				ta.NotPanics(func() { unmarshal(buf[:i]) }, "truncated at %d", i)
+			}
+
+			for i := 0; i < len(buf); i++ {
+				for _, x := range []byte{0x01, 0x10, 0x80, 0xff} {
+					bad := append([]byte{}, buf...)
+					bad[i] ^= x
+					ta.NotPanics(func() { unmarshal(bad) }, "%d-th byte ^ %02x", i, x)
+				}
+			}
+		})
+	}
+}
+
+// queryLoaded runs queries on a SlimTrie loaded from malformed data, which
+// must not panic.
+func queryLoaded(st *SlimTrie) {
+
+	// values could be anything
+	st.encoder = encode.Dummy{}
+
+	for _, k := range []string{"", "a", "abc", "bcd", "\xff\xff", "zzzzzzzzzzzzzzzz"} {
+		st.Get(k)
+		st.GetID(k)
+		st.Search(k)
+		st.GetFolded(k)
+	}
+
+	it := st.Iterate()
+	for _, _, ok := it.Next(); ok; _, _, ok = it.Next() {
+	}
+
+	st.Walk(func(nodeID int32, isInner bool, depthBits int32) bool { return true })
+	st.Stats()
+
+	if st.hasCompleteKeys() {
+		it = st.ScanRange("b", "")
+		for _, _, ok := it.Next(); ok; _, _, ok = it.Next() {
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package trie
+
+import (
+	"bytes"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/low/bitmap"
+	"github.com/openacid/low/bitstr"
+	"github.com/openacid/low/pbcmpl"
+)
+
+// versionedSlim is a Slim that writes another version in the marshaled
+// header.
+type versionedSlim struct {
+	*Slim
+	version string
+}
+
+func (v versionedSlim) GetVersion() string {
+	return v.version
+}
+
+// MarshalVersion serializes it to the format of version v, for a reader of
+// an older version.
+// Supported versions are "0.5.10" and "0.5.11", which share the same format,
+// and the current version, with which it is the same as Marshal().
+//
+// The format before 0.5.12 stores only fixed size values, the size of which
+// is decided by the encoder of the reader, and it can not represent some
+// features added since 0.5.12.
+// It returns an ErrIncompatible error if SlimTrie has var-length values,
+// values packed with Opt.LeafBlockSize or Opt.LeafExceptions, keys converted
+// with Opt.Collation, Opt.KeyNormalizer or Opt.WithTerminator, or keys removed by Delete().
+//
+// Data not used by a reader of an older version, such as the build options,
+// the folded index or the checksum, is not written.
+//
+// Since 0.5.12
+func (st *SlimTrie) MarshalVersion(v string) ([]byte, error) {
+
+	if v == slimtrieVersion {
+		return st.Marshal()
+	}
+
+	if v != "0.5.10" && v != "0.5.11" {
+		return nil, errors.Wrapf(ErrIncompatible, "can not marshal to version %q", v)
+	}
+
+	ns, err := st.fullInner()
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := toBefore000512(ns)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to convert to version %s", v)
+	}
+
+	var buf bytes.Buffer
+	_, err = pbcmpl.Marshal(&buf, versionedSlim{old, v})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to marshal st.inner")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// toBefore000512 converts ns to the format before 0.5.12, the reverse of what
+// Unmarshal() does with before000512InnerPrefixTobitstr() and
+// before000512FixLeafSize().
+func toBefore000512(ns *Slim) (*Slim, error) {
+
+	if ns.Collation != "" {
+		return nil, errors.Wrapf(ErrIncompatible, "collation %q", ns.Collation)
+	}
+
+	if ns.KeyNormalizer != "" {
+		return nil, errors.Wrapf(ErrIncompatible, "key normalizer %q", ns.KeyNormalizer)
+	}
+
+	if ns.OptWithTerminator {
+		return nil, errors.Wrap(ErrIncompatible, "keys with terminator")
+	}
+
+	if len(bitmap.ToArray(ns.DeletedBM.GetWords())) > 0 {
+		return nil, errors.Wrap(ErrIncompatible, "deleted keys")
+	}
+
+	rst := &Slim{
+		BigInnerCnt:  ns.BigInnerCnt,
+		ShortSize:    ns.ShortSize,
+		NodeTypeBM:   ns.NodeTypeBM,
+		Inners:       ns.Inners,
+		ShortBM:      ns.ShortBM,
+		ShortTable:   ns.ShortTable,
+		LeafPrefixes: ns.LeafPrefixes,
+	}
+
+	var err error
+	rst.InnerPrefixes, err = innerPrefixesBefore000512(ns.InnerPrefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := ns.Leaves
+	if leaves != nil {
+		if leaves.BlockSize != 0 || leaves.ExceptionBM != nil {
+			return nil, errors.Wrap(ErrIncompatible, "values in blocks or with exceptions")
+		}
+		if leaves.FixedSize == 0 {
+			return nil, errors.Wrap(ErrIncompatible, "var-length values")
+		}
+		if leaves.EltCnt != leaves.N {
+			return nil, errors.Wrap(ErrIncompatible, "absent values")
+		}
+
+		// Only the values are written, the size of a value is decided by
+		// the encoder.
+		rst.Leaves = &VLenArray{Bytes: leaves.Bytes}
+	}
+
+	return rst, nil
+}
+
+// innerPrefixesBefore000512 converts stored inner prefixes from bitstr
+// format, i.e., text followed by a trailing byte, to the format before
+// 0.5.12: a control byte followed by text.
+// An odd control byte indicates the last byte of text is not full, and the
+// bit after the last effective bit is set.
+// A prefix takes the same number of bytes in both formats, thus the positions
+// are not changed.
+func innerPrefixesBefore000512(ips *VLenArray) (*VLenArray, error) {
+
+	if ips == nil || ips.PositionBM == nil || len(ips.Bytes) == 0 {
+		return ips, nil
+	}
+
+	rst := *ips
+	rst.Bytes = make([]byte, len(ips.Bytes))
+
+	poss := bitmap.ToArray(ips.PositionBM.Words)
+
+	for i := 0; i+1 < len(poss); i++ {
+
+		from, to := poss[i], poss[i+1]
+		if to > int32(len(ips.Bytes)) {
+			return nil, errors.Wrapf(ErrCorrupt, "inner prefix position %d exceeds %d", to, len(ips.Bytes))
+		}
+
+		pref := ips.Bytes[from:to]
+		bitLen := bitstr.Len(pref)
+
+		old := rst.Bytes[from:to]
+		copy(old[1:], pref[:len(pref)-1])
+
+		if r := bitLen & 7; r != 0 {
+			old[0] = 1
+			old[len(old)-1] |= 0x80 >> uint(r)
+		}
+	}
+
+	return &rst, nil
+}
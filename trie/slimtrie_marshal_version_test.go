@@ -0,0 +1,149 @@
+package trie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/low/pbcmpl"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_MarshalVersion(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{Complete: Bool(true)},
+		{BigThreshold: 1},
+		{NoInnerPrefix: Bool(true)},
+		{Complete: Bool(true), WithFoldedIndex: Bool(true), WithChecksum: Bool(true)},
+	} {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		b, err := st.MarshalVersion(slimtrieVersion)
+		ta.NoError(err)
+		ta.Equal(mustMarshal(st), b)
+
+		for _, ver := range []string{"0.5.10", "0.5.11"} {
+			b, err := st.MarshalVersion(ver)
+			ta.NoError(err, "opt: %+v", opt)
+
+			_, h, err := pbcmpl.ReadHeader(bytes.NewReader(b))
+			ta.NoError(err)
+			ta.Equal(ver, h.GetVersion())
+
+			st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st2.Unmarshal(b))
+
+			// The folded index is not written.
+			want := st
+			if opt.WithFoldedIndex != nil {
+				ta.False(st2.HasFoldedIndex())
+				want, err = NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+				ta.NoError(err)
+			}
+			ta.True(want.Equal(st2), "opt: %+v, ver: %s", opt, ver)
+
+			testPresentKeysGet(t, st2, keys, values)
+		}
+	}
+
+	t.Run("incompatible", func(t *testing.T) {
+		ta := require.New(t)
+
+		svalues := make([]string, len(keys))
+		for i := range svalues {
+			svalues[i] = strings.Repeat("x", i%3)
+		}
+
+		deleted, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+		ta.True(deleted.Delete(keys[1]))
+
+		cases := []struct {
+			name string
+			e    encode.Encoder
+			vals interface{}
+			opt  Opt
+		}{
+			{"collation", encode.I32{}, values, Opt{Collation: caseCollation{"test.case.v1"}}},
+			{"terminator", encode.I32{}, values, Opt{WithTerminator: Bool(true)}},
+			{"varlen", encode.String16{}, svalues, Opt{}},
+			{"block", encode.String16{}, svalues, Opt{LeafBlockSize: 16}},
+		}
+
+		for _, c := range cases {
+			ks := keys
+			if c.name == "collation" {
+				ks = []string{"apple", "Banana", "banana", "cherry"}
+				c.vals = values[:len(ks)]
+			}
+			st, err := NewSlimTrie(c.e, ks, c.vals, c.opt)
+			ta.NoError(err, c.name)
+
+			_, err = st.MarshalVersion("0.5.10")
+			ta.Equal(ErrIncompatible, errors.Cause(err), c.name)
+		}
+
+		_, err = deleted.MarshalVersion("0.5.11")
+		ta.Equal(ErrIncompatible, errors.Cause(err))
+
+		for _, ver := range []string{"", "0.5.9", "1.0.0", "0.5.14"} {
+			_, err = deleted.MarshalVersion(ver)
+			ta.Equal(ErrIncompatible, errors.Cause(err), ver)
+		}
+	})
+}
+
+func TestSlimTrie_MarshalVersion_old_data(t *testing.T) {
+
+	testOldData(t,
+		func(t *testing.T,
+			dataSetName, dataOpt, ver string,
+			keys []string,
+			buf []byte) {
+
+			if ver != "0.5.10" {
+				return
+			}
+
+			ta := require.New(t)
+
+			st, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st.Unmarshal(buf))
+
+			b, err := st.MarshalVersion(ver)
+			ta.NoError(err)
+
+			// The converted data is the same as the one written by 0.5.10
+			want, got := &Slim{}, &Slim{}
+			_, _, err = pbcmpl.Unmarshal(bytes.NewReader(buf), want)
+			ta.NoError(err)
+			_, _, err = pbcmpl.Unmarshal(bytes.NewReader(b), got)
+			ta.NoError(err)
+
+			ta.Equal(want.GetInnerPrefixes().GetBytes(), got.GetInnerPrefixes().GetBytes())
+			ta.Equal(want.GetLeafPrefixes().GetBytes(), got.GetLeafPrefixes().GetBytes())
+			ta.Equal(want.GetLeaves(), got.GetLeaves())
+			ta.Equal(want.GetNodeTypeBM().GetWords(), got.GetNodeTypeBM().GetWords())
+			ta.Equal(want.GetInners().GetWords(), got.GetInners().GetWords())
+
+			st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st2.Unmarshal(b))
+			ta.True(st.Equal(st2))
+
+			testPresentKeysGet(t, st2, keys, makeI32s(len(keys)))
+		})
+}
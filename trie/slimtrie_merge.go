@@ -0,0 +1,67 @@
+package trie
+
+import (
+	"reflect"
+
+	"github.com/openacid/errors"
+)
+
+// Merge returns a new SlimTrie with the union of keys and values in a and b,
+// built once with the options and the encoder of a, e.g., to merge sorted
+// segments of an index like levels of an LSM tree.
+// Neither a nor b is changed.
+//
+// A key in both a and b with the same value is merged into one.
+// By default a key in both with different values makes it return an
+// ErrDuplicateKey error.
+// Specify policy to keep the value in a or to use the value in b.
+//
+// Keys are rebuilt from both tries, which requires SlimTries created with
+// Opt{Complete: Bool(true)}, otherwise it returns an ErrIncomplete error.
+// Keys removed by Opt.DedupValue when creating and keys deleted by Delete()
+// are lost, as WithBatch() does.
+//
+// Since 0.5.12
+func Merge(a, b *SlimTrie, policy ...MergePolicy) (*SlimTrie, error) {
+
+	pol := MergeReject
+	if len(policy) > 0 {
+		pol = policy[0]
+	}
+
+	if _, c := splitNormalizer(b.collation); c != nil {
+		return nil, errors.Wrapf(ErrCollation, "original keys can not be rebuilt from sort keys")
+	}
+
+	keys := make([]string, 0, b.Len())
+	var values []interface{}
+	if b.getLeaves() != nil {
+		values = make([]interface{}, 0, b.Len())
+	}
+
+	err := b.IterKV(func(key string, val interface{}) bool {
+
+		if b.inner.DeletedBM != nil && b.GetID(key) == -1 {
+			// deleted by Delete()
+			return true
+		}
+
+		if pol == MergeReject {
+			if v, found := a.Get(key); found && reflect.DeepEqual(v, val) {
+				// not a conflict
+				return true
+			}
+		}
+
+		keys = append(keys, key)
+		if values != nil {
+			values = append(values, val)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to iterate b")
+	}
+
+	return a.WithBatch(keys, values, pol)
+}
@@ -0,0 +1,121 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+
+	ta := require.New(t)
+
+	opt := Opt{Complete: Bool(true), DedupValue: Bool(false)}
+
+	a, err := NewSlimTrie(encode.I32{}, []string{"b", "d", "f"}, []int32{1, 2, 3}, opt)
+	ta.NoError(err)
+
+	b, err := NewSlimTrie(encode.I32{}, []string{"a", "d", "g"}, []int32{10, 2, 12}, opt)
+	ta.NoError(err)
+
+	m, err := Merge(a, b)
+	ta.NoError(err)
+
+	got, err := m.ToMap()
+	ta.NoError(err)
+	ta.Equal(map[string]interface{}{
+		"a": int32(10),
+		"b": int32(1),
+		"d": int32(2),
+		"f": int32(3),
+		"g": int32(12),
+	}, got)
+	ta.Equal(a.BuildOptions(), m.BuildOptions())
+
+	// a and b are not changed
+	ta.Equal(3, a.Len())
+	ta.Equal(3, b.Len())
+
+	t.Run("conflict", func(t *testing.T) {
+		ta := require.New(t)
+
+		c, err := NewSlimTrie(encode.I32{}, []string{"c", "d"}, []int32{11, 20}, opt)
+		ta.NoError(err)
+
+		_, err = Merge(a, c)
+		ta.Equal(ErrDuplicateKey, errors.Cause(err))
+
+		m, err := Merge(a, c, MergeKeepOld)
+		ta.NoError(err)
+		v, _ := m.Get("d")
+		ta.Equal(int32(2), v)
+
+		m, err = Merge(a, c, MergeOverwrite)
+		ta.NoError(err)
+		v, _ = m.Get("d")
+		ta.Equal(int32(20), v)
+		v, _ = m.Get("c")
+		ta.Equal(int32(11), v)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		ta := require.New(t)
+
+		c, err := NewSlimTrie(encode.I32{}, []string{"c", "e"}, []int32{11, 13}, opt)
+		ta.NoError(err)
+		ta.True(c.Delete("c"))
+
+		m, err := Merge(a, c)
+		ta.NoError(err)
+		ta.Equal(4, m.Len())
+
+		_, found := m.Get("c")
+		ta.False(found)
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		ta := require.New(t)
+
+		c, err := NewSlimTrie(nil, []string{"c"}, nil, opt)
+		ta.NoError(err)
+
+		m, err := Merge(a, c)
+		ta.NoError(err)
+
+		v, found := m.Get("c")
+		ta.True(found)
+		ta.Nil(v)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ta := require.New(t)
+
+		empty, err := NewSlimTrie(encode.I32{}, nil, []int32{}, opt)
+		ta.NoError(err)
+
+		m, err := Merge(a, empty)
+		ta.NoError(err)
+		ta.True(a.Equal(m))
+
+		m, err = Merge(empty, a)
+		ta.NoError(err)
+		got, err := m.ToMap()
+		ta.NoError(err)
+		ta.Equal(3, len(got))
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		ta := require.New(t)
+
+		c, err := NewSlimTrie(encode.I32{}, []string{"c"}, []int32{11})
+		ta.NoError(err)
+
+		_, err = Merge(a, c)
+		ta.Equal(ErrIncomplete, errors.Cause(err))
+
+		_, err = Merge(c, a)
+		ta.Equal(ErrIncomplete, errors.Cause(err))
+	})
+}
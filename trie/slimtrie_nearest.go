@@ -0,0 +1,71 @@
+package trie
+
+// NearestN returns values of up to n leaves that are the closest to key, in
+// key order.
+//
+// If key matches a leaf, its value is the first to be chosen.
+// Then leaves on the left side and on the right side of key are chosen
+// alternately, starting from the left side, until n leaves are chosen.
+// If one side runs out of leaves, the rest are all chosen from the other
+// side.
+// Thus fewer than n values are returned only if there are fewer than n
+// leaves in total.
+//
+// The position of key is the same as Search() finds, thus it is affected by
+// false positive in the same way, unless SlimTrie is built with Opt.Complete.
+//
+// Since 0.5.12
+func (st *SlimTrie) NearestN(key string, n int) []interface{} {
+
+	rst := make([]interface{}, 0)
+
+	if n <= 0 {
+		return rst
+	}
+
+	lID, eqID, rID := st.searchID(key)
+
+	var lefts, rights []int32
+
+	var l, r *cursor
+	if lID != -1 {
+		l = st.newCursor(lID)
+	}
+	if eqID != -1 {
+		n--
+	}
+	if rID != -1 {
+		r = st.newCursor(rID)
+	}
+
+	for n > 0 && (l != nil || r != nil) {
+
+		if l != nil {
+			lefts = append(lefts, l.leaf())
+			n--
+			if !l.prev() {
+				l = nil
+			}
+		}
+
+		if n > 0 && r != nil {
+			rights = append(rights, r.leaf())
+			n--
+			if !r.next() {
+				r = nil
+			}
+		}
+	}
+
+	for i := len(lefts) - 1; i >= 0; i-- {
+		rst = append(rst, st.getLeaf(lefts[i]))
+	}
+	if eqID != -1 {
+		rst = append(rst, st.getLeaf(eqID))
+	}
+	for _, id := range rights {
+		rst = append(rst, st.getLeaf(id))
+	}
+
+	return rst
+}
@@ -0,0 +1,87 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_NearestN(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"b", "d", "f", "h", "j"}
+	values := []int32{0, 1, 2, 3, 4}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	cases := []struct {
+		key  string
+		n    int
+		want []interface{}
+	}{
+		{"f", 0, []interface{}{}},
+		{"f", -1, []interface{}{}},
+		{"f", 1, []interface{}{int32(2)}},
+		{"f", 2, []interface{}{int32(1), int32(2)}},
+		{"f", 3, []interface{}{int32(1), int32(2), int32(3)}},
+		{"e", 1, []interface{}{int32(1)}},
+		{"e", 2, []interface{}{int32(1), int32(2)}},
+		{"e", 3, []interface{}{int32(0), int32(1), int32(2)}},
+
+		// ends of the key space
+		{"a", 2, []interface{}{int32(0), int32(1)}},
+		{"b", 3, []interface{}{int32(0), int32(1), int32(2)}},
+		{"z", 2, []interface{}{int32(3), int32(4)}},
+		{"j", 3, []interface{}{int32(2), int32(3), int32(4)}},
+		{"i", 4, []interface{}{int32(1), int32(2), int32(3), int32(4)}},
+		{"c", 100, []interface{}{int32(0), int32(1), int32(2), int32(3), int32(4)}},
+	}
+
+	for i, c := range cases {
+		ta.Equal(c.want, st.NearestN(c.key, c.n), "%d-th: case: %+v", i+1, c)
+	}
+
+	t.Run("random", func(t *testing.T) {
+		for seed := int64(0); seed < 10; seed++ {
+
+			rng := rand.New(rand.NewSource(seed))
+			st, kvs := RandomTrie(rng, 100, 6)
+			keys, values := sortedKVs(kvs)
+
+			for i := 0; i < 50; i++ {
+				q := randKey(rng, 6)
+				n := rng.Intn(10)
+
+				// the position of the first key >= q
+				p := sort.SearchStrings(keys, q)
+				eq := p < len(keys) && keys[p] == q
+
+				// expand from the left side first
+				lo, hi := p, p
+				if eq && n > 0 {
+					hi = p + 1
+				}
+				for turn := 0; hi-lo < n && (lo > 0 || hi < len(keys)); turn++ {
+					if (turn%2 == 0 && lo > 0) || hi == len(keys) {
+						lo--
+					} else {
+						hi++
+					}
+				}
+
+				ta.Equal(values[lo:hi], st.NearestN(q, n), "seed %d key %q n %d", seed, q, n)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.Equal([]interface{}{}, st.NearestN("a", 3))
+	})
+}
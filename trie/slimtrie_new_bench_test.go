@@ -1,6 +1,7 @@
 package trie
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/openacid/slim/encode"
@@ -28,3 +29,58 @@ func BenchmarkNewSlimTrie(b *testing.B) {
 		OutputNewSlimTrie = s
 	})
 }
+
+func BenchmarkNewSlimTrie_NoShortTable(b *testing.B) {
+
+	keys := getKeys("200kweb2")
+	values := makeI32s(len(keys))
+
+	for _, noShort := range []bool{false, true} {
+
+		opt := Opt{NoShortTable: Bool(noShort)}
+		st, _ := NewSlimTrie(encode.I32{}, keys, values, opt)
+		b.Logf("NoShortTable=%v: %d bytes", noShort, st.MappedBytes())
+
+		b.Run(fmt.Sprintf("NoShortTable=%v", noShort), func(b *testing.B) {
+			var s int
+			for i := 0; i < b.N; i++ {
+				st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+				if err != nil {
+					panic(err)
+				}
+				s += int(st.inner.NodeTypeBM.Words[0])
+			}
+			OutputNewSlimTrie = s
+		})
+	}
+}
+
+func BenchmarkNewFromArrow(b *testing.B) {
+
+	keys := getKeys("200kweb2")
+	values := make([]interface{}, len(keys))
+	for i := range keys {
+		values[i] = int32(i)
+	}
+
+	data := make([]byte, 0)
+	offsets := []int32{0}
+	for _, k := range keys {
+		data = append(data, k...)
+		offsets = append(offsets, int32(len(data)))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var s int
+	for i := 0; i < b.N; i++ {
+		st, err := NewFromArrow(data, offsets, values, encode.I32{})
+		if err != nil {
+			panic(err)
+		}
+		s += int(st.inner.NodeTypeBM.Words[0])
+	}
+
+	OutputNewSlimTrie = s
+}
@@ -0,0 +1,109 @@
+package trie
+
+import (
+	"math/bits"
+
+	"github.com/openacid/low/bitmap"
+	"github.com/openacid/low/bmtree"
+)
+
+// Children returns the ids of the direct children of a node, in label order.
+// It returns nil if nodeID is a leaf or out of range.
+//
+// Node ids are the same as the ones returned by GetID() and PartialGetID().
+// The root node id is 0.
+//
+// Since 0.5.12
+func (st *SlimTrie) Children(nodeID int32) []int32 {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return nil
+	}
+
+	if nodeID < 0 || nodeID >= st.levels[len(st.levels)-1].total {
+		return nil
+	}
+
+	qr := &querySession{}
+	st.getNode(nodeID, qr)
+	if qr.isInner == 0 {
+		return nil
+	}
+
+	ns := st.inner
+
+	// Children of a node are contiguous. The first child id is the number of
+	// "1" before this node plus 1, since every node except the root has a "1"
+	// pointing to it.
+	r0, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+
+	var n int32
+	if qr.to-qr.from == ns.ShortSize {
+		n = int32(bits.OnesCount64(qr.bm))
+	} else {
+		for _, w := range bitmap.Slice(ns.Inners.Words, qr.from, qr.to) {
+			n += int32(bits.OnesCount64(w))
+		}
+	}
+
+	rst := make([]int32, n)
+	for i := int32(0); i < n; i++ {
+		rst[i] = r0 + 1 + i
+	}
+
+	return rst
+}
+
+// Walk visits every node depth-first from the root, children in label order,
+// and calls fn with the node id, whether it is an inner node, and depthBits,
+// the position in a key in bits where the node starts, i.e., the number of key
+// bits consumed by the inner prefixes and labels from the root to it.
+// If fn returns false for an inner node, its subtree is skipped.
+//
+// It is a primitive to compute statistics, e.g., the max depth or the number
+// of nodes at every depth, without a method for every metric.
+//
+// Since 0.5.12
+func (st *SlimTrie) Walk(fn func(nodeID int32, isInner bool, depthBits int32) bool) {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return
+	}
+
+	type walkNode struct {
+		id    int32
+		depth int32
+	}
+
+	qr := &querySession{}
+	stack := []walkNode{{0, 0}}
+	var labels []uint64
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		st.getNode(n.id, qr)
+		isInner := qr.isInner != 0
+
+		if !fn(n.id, isInner, n.depth) || !isInner {
+			continue
+		}
+
+		i := n.depth
+		if qr.hasInnerPrefix {
+			i = i&(^7) + qr.innerPrefixLen
+		} else {
+			i += qr.innerPrefixLen
+		}
+
+		// A label is shorter than a word if a key ends in this node.
+		labels = st.appendLabels(labels[:0], qr)
+
+		// push in reverse order to visit the first child first.
+		first, last := st.childRange(n.id)
+		for ch := last; ch >= first; ch-- {
+			stack = append(stack, walkNode{ch, i + bmtree.PathLen(labels[ch-first])})
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package trie
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/low/bitmap"
+	"github.com/openacid/low/bmtree"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Children(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 10; seed++ {
+
+		st, kvs := RandomTrie(rand.New(rand.NewSource(seed)), 200, 8)
+		if len(kvs) == 0 {
+			continue
+		}
+
+		// A breadth-first walk from the root with Children() visits every
+		// node exactly once, in node id order.
+		total := st.levels[len(st.levels)-1].total
+		next := int32(0)
+		queue := []int32{0}
+		leaves := 0
+
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+
+			ta.Equal(next, id)
+			next++
+
+			children := st.Children(id)
+			if children == nil {
+				leaves++
+			}
+			queue = append(queue, children...)
+		}
+
+		ta.Equal(total, next)
+		ta.Equal(int(st.inner.Leaves.N), leaves)
+
+		ta.Nil(st.Children(-1))
+		ta.Nil(st.Children(total))
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		st, _ := RandomTrie(rand.New(rand.NewSource(0)), 0, 8)
+		ta.Nil(st.Children(0))
+	})
+}
+
+func TestSlimTrie_Walk(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	st, err := NewSlimTrie(nil, keys, nil)
+	ta.NoError(err)
+
+	stat := st.Stats()
+
+	var inners, leaves, maxDepth int32
+	depths := map[int32]int32{}
+	visited := []int32{}
+
+	st.Walk(func(nodeID int32, isInner bool, depthBits int32) bool {
+		visited = append(visited, nodeID)
+		if isInner {
+			inners++
+		} else {
+			leaves++
+			depths[nodeID] = depthBits
+		}
+		if depthBits > maxDepth {
+			maxDepth = depthBits
+		}
+		return true
+	})
+
+	ta.Equal(stat.InnerCnt, inners)
+	ta.Equal(stat.LeafCnt, leaves)
+	ta.Equal(0, int(visited[0]))
+
+	// the depth of a leaf is where the search of its key stops.
+	for i, k := range keys {
+		id := st.GetID(k)
+		_, matched, found := st.GetWithDepth(k)
+		ta.True(found)
+		ta.Equal(int32(matched), depths[id], "%d-th key %q", i, k)
+		ta.True(depths[id] <= maxDepth)
+	}
+
+	// leaves are visited in key order.
+	ords := []int32{}
+	for _, id := range visited {
+		if _, ok := depths[id]; ok {
+			ords = append(ords, st.keyOrdinal(id))
+		}
+	}
+	for i, o := range ords {
+		ta.Equal(int32(i), o)
+	}
+
+	t.Run("prune", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		// visit only the root and its children.
+		n := 0
+		st.Walk(func(nodeID int32, isInner bool, depthBits int32) bool {
+			n++
+			return nodeID == 0
+		})
+		ta.Equal(1+len(st.Children(0)), n)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(nil, nil, nil)
+		ta.NoError(err)
+
+		st.Walk(func(nodeID int32, isInner bool, depthBits int32) bool {
+			ta.Fail("no node to visit")
+			return true
+		})
+	})
+}
+
+func TestSlimTrie_appendLabels(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{{}, {BigThreshold: 1}, {NoShortTable: Bool(true)}} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		ns := st.inner
+		qr := &querySession{}
+		buf := []uint64{1, 2, 3}
+
+		st.Walk(func(nodeID int32, isInner bool, depthBits int32) bool {
+			if !isInner {
+				return true
+			}
+			st.getNode(nodeID, qr)
+
+			var want []uint64
+			if qr.to-qr.from == ns.ShortSize {
+				want = bmtree.Decode(innerSize, []uint64{qr.bm})
+			} else {
+				want = bmtree.Decode(qr.to-qr.from, bitmap.Slice(ns.Inners.Words, qr.from, qr.to))
+			}
+
+			ta.Equal(want, st.getLabels(qr), "node: %d", nodeID)
+
+			buf = st.appendLabels(buf[:1], qr)
+			ta.Equal(append([]uint64{1}, want...), buf, "node: %d", nodeID)
+			return true
+		})
+	}
+}
+
+func BenchmarkSlimTrie_Walk(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{{}, {BigThreshold: 1}} {
+
+		st, _ := NewSlimTrie(encode.I32{}, keys, values, opt)
+
+		b.Run(fmt.Sprintf("BigThreshold=%d", opt.BigThreshold), func(b *testing.B) {
+			b.ReportAllocs()
+			var n int32
+			for i := 0; i < b.N; i++ {
+				st.Walk(func(nodeID int32, isInner bool, depthBits int32) bool {
+					n += depthBits
+					return true
+				})
+			}
+			Outputxxx = n
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package trie
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/openacid/errors"
+)
+
+// KeyNormalizer converts keys to a normal form, such as case-folded or
+// Unicode-normalized keys, so that keys of the same normal form are the same
+// key to a SlimTrie.
+//
+// With Opt.KeyNormalizer, SlimTrie stores the normalized keys, and normalizes
+// a query key before searching, e.g., with a lower-case normalizer Get("Foo")
+// finds the key "foo".
+//
+// Since 0.5.12
+type KeyNormalizer interface {
+
+	// Name identifies a normalizer.
+	// It is stored in the marshaled data and is used to find the registered
+	// normalizer when loading.
+	// A normalizer must change its name if its normal form changes.
+	Name() string
+
+	// Normalize returns the normal form of key.
+	Normalize(key string) string
+}
+
+var (
+	normalizersMu sync.RWMutex
+	normalizers   = map[string]KeyNormalizer{}
+)
+
+// RegisterKeyNormalizer registers a KeyNormalizer by its name, so that a
+// SlimTrie built with it can be loaded.
+// A later registration with the same name replaces the previous one.
+//
+// Since 0.5.12
+func RegisterKeyNormalizer(n KeyNormalizer) {
+	normalizersMu.Lock()
+	defer normalizersMu.Unlock()
+
+	normalizers[n.Name()] = n
+}
+
+// LookupKeyNormalizer returns the KeyNormalizer registered with name.
+//
+// Since 0.5.12
+func LookupKeyNormalizer(name string) (KeyNormalizer, bool) {
+	normalizersMu.RLock()
+	defer normalizersMu.RUnlock()
+
+	n, ok := normalizers[name]
+	return n, ok
+}
+
+// KeyNormalizer returns the name of the KeyNormalizer the SlimTrie is built
+// with, or "" if keys are not normalized.
+//
+// Since 0.5.12
+func (st *SlimTrie) KeyNormalizer() string {
+	return st.inner.GetKeyNormalizer()
+}
+
+// normalizedCollation is a Collation that normalizes keys before comparing
+// them or converting them to sort keys.
+// It is how a SlimTrie applies a KeyNormalizer: a normalized key is found
+// wherever a collated key is, at building, querying and loading.
+//
+// Since 0.5.12
+type normalizedCollation struct {
+	normalizer KeyNormalizer
+
+	// collation orders normalized keys, or nil for byte order.
+	collation Collation
+}
+
+// withNormalizer returns c with keys normalized by n, or c itself if n is nil.
+//
+// Since 0.5.12
+func withNormalizer(n KeyNormalizer, c Collation) Collation {
+	if n == nil {
+		return c
+	}
+	return normalizedCollation{normalizer: n, collation: c}
+}
+
+// splitNormalizer returns the KeyNormalizer and the Collation c is made of.
+//
+// Since 0.5.12
+func splitNormalizer(c Collation) (KeyNormalizer, Collation) {
+	if nc, ok := c.(normalizedCollation); ok {
+		return nc.normalizer, nc.collation
+	}
+	return nil, c
+}
+
+func (c normalizedCollation) Name() string {
+	if c.collation == nil {
+		return c.normalizer.Name()
+	}
+	return c.normalizer.Name() + "/" + c.collation.Name()
+}
+
+func (c normalizedCollation) Compare(a, b string) int {
+	a = c.normalizer.Normalize(a)
+	b = c.normalizer.Normalize(b)
+	if c.collation == nil {
+		return strings.Compare(a, b)
+	}
+	return c.collation.Compare(a, b)
+}
+
+func (c normalizedCollation) SortKey(key string) string {
+	key = c.normalizer.Normalize(key)
+	if c.collation == nil {
+		return key
+	}
+	return c.collation.SortKey(key)
+}
+
+// loadKeyNormalizer finds the registered KeyNormalizer for a loaded SlimTrie,
+// and applies it to the loaded collation.
+// It returns an ErrCollation error if it is built with a KeyNormalizer that is
+// not registered.
+//
+// Since 0.5.12
+func (st *SlimTrie) loadKeyNormalizer() error {
+
+	name := st.inner.GetKeyNormalizer()
+	if name == "" {
+		return nil
+	}
+
+	n, ok := LookupKeyNormalizer(name)
+	if !ok {
+		return errors.Wrapf(ErrCollation, "key normalizer %s is not registered", name)
+	}
+
+	st.collation = withNormalizer(n, st.collation)
+	return nil
+}
@@ -0,0 +1,158 @@
+package trie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// lowerNormalizer normalizes keys to lower case.
+type lowerNormalizer struct {
+	name string
+}
+
+func (n lowerNormalizer) Name() string { return n.name }
+
+func (n lowerNormalizer) Normalize(key string) string { return strings.ToLower(key) }
+
+func TestSlimTrie_KeyNormalizer(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"Apple", "banana", "CHERRY"}
+	values := []int32{0, 1, 2}
+	norm := lowerNormalizer{"test.lower.v1"}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values,
+		Opt{Complete: Bool(true), KeyNormalizer: norm, SelfCheck: Bool(true)})
+	ta.NoError(err)
+	ta.Equal("test.lower.v1", st.KeyNormalizer())
+	ta.Equal("", st.Collation())
+
+	opt := st.BuildOptions()
+	ta.Equal(norm, opt.KeyNormalizer)
+	ta.Nil(opt.Collation)
+
+	testPresentKeysGet(t, st, keys, values)
+
+	for i, k := range []string{"apple", "APPLE", "Banana", "cherry", "cHeRrY"} {
+		v, found := st.Get(k)
+		ta.True(found, "key: %q", k)
+		ta.Equal(int32([]int{0, 0, 1, 2, 2}[i]), v, "key: %q", k)
+	}
+
+	_, found := st.Get("Bananas")
+	ta.False(found)
+
+	l, eq, r := st.Search("B")
+	ta.Equal(int32(0), l)
+	ta.Nil(eq)
+	ta.Equal(int32(1), r)
+
+	got, _ := collectIter(st.Iterate())
+	ta.Equal([]string{"apple", "banana", "cherry"}, got)
+
+	t.Run("prefix", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, []string{"a", "ab"}, []int32{1, 2},
+			Opt{Complete: Bool(true), KeyNormalizer: norm})
+		ta.NoError(err)
+
+		exact, exactOK, prefix, prefixOK := st.Route("AB")
+		ta.True(exactOK)
+		ta.Equal(int32(2), exact)
+		ta.True(prefixOK)
+		ta.Equal(int32(2), prefix)
+
+		l, v, ok := st.LongestPrefix("ABX")
+		ta.True(ok)
+		ta.Equal(2, l)
+		ta.Equal(int32(2), v)
+
+		ta.Equal([]interface{}{int32(1), int32(2)}, st.PathValues("ABC"))
+	})
+
+	t.Run("outOfOrder", func(t *testing.T) {
+		ta := require.New(t)
+
+		// sorted in byte order but not after normalizing
+		_, err := NewSlimTrie(encode.I32{}, []string{"B", "a"}, []int32{0, 1},
+			Opt{KeyNormalizer: norm})
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+
+		// the same normal form
+		_, err = NewSlimTrie(encode.I32{}, []string{"A", "a"}, []int32{0, 1},
+			Opt{KeyNormalizer: norm})
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+	})
+
+	t.Run("collation", func(t *testing.T) {
+		ta := require.New(t)
+
+		coll := caseCollation{"test.case.v1"}
+		st, err := NewSlimTrie(encode.I32{}, keys, values,
+			Opt{KeyNormalizer: norm, Collation: coll})
+		ta.NoError(err)
+		ta.Equal("test.lower.v1", st.KeyNormalizer())
+		ta.Equal("test.case.v1", st.Collation())
+
+		opt := st.BuildOptions()
+		ta.Equal(norm, opt.KeyNormalizer)
+		ta.Equal(coll, opt.Collation)
+
+		v, found := st.Get("BANANA")
+		ta.True(found)
+		ta.Equal(int32(1), v)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewFromMap(map[string]interface{}{"b": int32(1), "A": int32(0), "C": int32(2)},
+			encode.I32{}, Opt{KeyNormalizer: norm})
+		ta.NoError(err)
+		testPresentKeysGet(t, st, []string{"a", "B", "c"}, []int32{0, 1, 2})
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ta := require.New(t)
+
+		kch, vch := sendStreams(keys, values)
+		st, err := NewFromStreams(kch, vch, encode.I32{}, &Opt{KeyNormalizer: norm})
+		ta.NoError(err)
+		testPresentKeysGet(t, st, keys, values)
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		ta := require.New(t)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		err = st2.Unmarshal(buf)
+		ta.Equal(ErrCollation, errors.Cause(err))
+
+		RegisterKeyNormalizer(norm)
+		n, ok := LookupKeyNormalizer("test.lower.v1")
+		ta.True(ok)
+		ta.Equal(norm, n)
+
+		ta.NoError(st2.Unmarshal(buf))
+		ta.True(st.Equal(st2))
+		testPresentKeysGet(t, st2, []string{"APPLE", "Banana", "cherry"}, values)
+
+		_, err = st.MarshalVersion("0.5.10")
+		ta.Equal(ErrIncompatible, errors.Cause(err))
+
+		st2.Reset()
+		ta.Nil(st2.collation)
+		ta.Equal("", st2.KeyNormalizer())
+	})
+}
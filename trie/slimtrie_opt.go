@@ -0,0 +1,130 @@
+package trie
+
+import "time"
+
+// BuildOptions returns the effective options the SlimTrie is built with, i.e.,
+// the options passed to NewSlimTrie() after filling in default values.
+//
+// For a SlimTrie loaded from data built before the options are recorded, only
+// ValueType is set, and the other fields are nil or 0.
+// For a SlimTrie loaded from the bytes of MarshalCanonical(), WithTerminator,
+// CheckKeyLen and WithChecksum are also set if they are enabled.
+//
+// Since 0.5.12
+func (st *SlimTrie) BuildOptions() Opt {
+
+	ns := st.inner
+
+	n, c := splitNormalizer(st.collation)
+
+	opt := Opt{
+		ValueType:     ns.ValueType,
+		Collation:     c,
+		KeyNormalizer: n,
+	}
+
+	if st.valueFunc != nil {
+		opt.ValueFunc = st.valueFunc.fn
+	}
+
+	if !ns.HasBuildOpt {
+		// options kept by MarshalCanonical()
+		if ns.OptWithTerminator {
+			opt.WithTerminator = Bool(true)
+		}
+		if ns.OptCheckKeyLen {
+			opt.CheckKeyLen = Bool(true)
+		}
+		if ns.OptWithChecksum {
+			opt.WithChecksum = Bool(true)
+		}
+		return opt
+	}
+
+	opt.DedupValue = Bool(ns.OptDedupValue)
+	opt.InnerPrefix = Bool(ns.OptInnerPrefix)
+	opt.LeafPrefix = Bool(ns.OptLeafPrefix)
+	opt.Complete = Bool(ns.OptComplete)
+	opt.SelfCheck = Bool(ns.OptSelfCheck)
+	opt.NoShortTable = Bool(ns.OptNoShortTable)
+	opt.LeafBlockSize = ns.OptLeafBlockSize
+	opt.WithFoldedIndex = Bool(ns.OptWithFoldedIndex)
+	opt.WithBuildTime = Bool(ns.BuiltAt != 0)
+	opt.WithTerminator = Bool(ns.OptWithTerminator)
+	opt.LeafExceptions = Bool(ns.OptLeafExceptions)
+	opt.CheckKeyLen = Bool(ns.OptCheckKeyLen)
+	opt.BigThreshold = ns.OptBigThreshold
+	opt.WithChecksum = Bool(ns.OptWithChecksum)
+	opt.NoInnerPrefix = Bool(ns.OptNoInnerPrefix)
+
+	return opt
+}
+
+// recordOpt writes a normalized opt into ns.
+//
+// Since 0.5.12
+func recordOpt(ns *Slim, opt *Opt) {
+
+	ns.ValueType = opt.ValueType
+
+	n, c := splitNormalizer(opt.Collation)
+	if c != nil {
+		ns.Collation = c.Name()
+	}
+	if n != nil {
+		ns.KeyNormalizer = n.Name()
+	}
+
+	ns.HasBuildOpt = true
+	ns.OptDedupValue = *opt.DedupValue
+	ns.OptInnerPrefix = *opt.InnerPrefix
+	ns.OptLeafPrefix = *opt.LeafPrefix
+	ns.OptComplete = opt.Complete != nil && *opt.Complete
+	ns.OptSelfCheck = *opt.SelfCheck
+	ns.OptNoShortTable = *opt.NoShortTable
+	ns.OptLeafBlockSize = opt.LeafBlockSize
+	ns.OptWithFoldedIndex = *opt.WithFoldedIndex
+	ns.OptWithTerminator = *opt.WithTerminator
+	ns.OptLeafExceptions = *opt.LeafExceptions
+	ns.OptCheckKeyLen = *opt.CheckKeyLen
+	ns.OptBigThreshold = opt.BigThreshold
+	ns.OptWithChecksum = *opt.WithChecksum
+	ns.OptNoInnerPrefix = *opt.NoInnerPrefix
+	if *opt.WithBuildTime {
+		ns.BuiltAt = time.Now().UnixNano()
+	}
+}
+
+// SetValueFunc sets the function to compute values from leaf ordinals, such as
+// for a SlimTrie built with Opt.ValueFunc and loaded by Unmarshal().
+// A nil fn makes it read stored values again.
+// See Opt.ValueFunc.
+//
+// Since 0.5.12
+func (st *SlimTrie) SetValueFunc(fn func(leafOrdinal int32) interface{}) {
+	st.valueFunc = newValueFunc(fn)
+}
+
+// BuiltAt returns the time the SlimTrie is built, if it is built with
+// Opt.WithBuildTime.
+// Otherwise it returns a zero time.Time.
+//
+// Since 0.5.12
+func (st *SlimTrie) BuiltAt() time.Time {
+	if st.inner.BuiltAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, st.inner.BuiltAt)
+}
+
+// Age returns the duration since the SlimTrie is built, if it is built with
+// Opt.WithBuildTime.
+// Otherwise it returns 0.
+//
+// Since 0.5.12
+func (st *SlimTrie) Age() time.Duration {
+	if st.inner.BuiltAt == 0 {
+		return 0
+	}
+	return time.Since(st.BuiltAt())
+}
@@ -0,0 +1,116 @@
+package trie
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_BuildOptions(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := marshalCase.values
+
+	cases := []struct {
+		input Opt
+		want  Opt
+	}{
+		{
+			Opt{},
+			Opt{
+				DedupValue:      Bool(true),
+				InnerPrefix:     Bool(false),
+				LeafPrefix:      Bool(false),
+				Complete:        Bool(false),
+				SelfCheck:       Bool(false),
+				NoShortTable:    Bool(false),
+				WithFoldedIndex: Bool(false),
+				WithBuildTime:   Bool(false),
+				WithTerminator:  Bool(false),
+				LeafExceptions:  Bool(false),
+				CheckKeyLen:     Bool(false),
+				WithChecksum:    Bool(false),
+				NoInnerPrefix:   Bool(false),
+			},
+		},
+		{
+			Opt{Complete: Bool(true), LeafBlockSize: 4, ValueType: "foo", NoShortTable: Bool(true), BigThreshold: -1, WithChecksum: Bool(true), NoInnerPrefix: Bool(true)},
+			Opt{
+				DedupValue:      Bool(true),
+				InnerPrefix:     Bool(true),
+				LeafPrefix:      Bool(true),
+				Complete:        Bool(true),
+				SelfCheck:       Bool(false),
+				NoShortTable:    Bool(true),
+				LeafBlockSize:   4,
+				ValueType:       "foo",
+				WithFoldedIndex: Bool(false),
+				WithBuildTime:   Bool(false),
+				WithTerminator:  Bool(false),
+				LeafExceptions:  Bool(false),
+				CheckKeyLen:     Bool(false),
+				BigThreshold:    -1,
+				WithChecksum:    Bool(true),
+				NoInnerPrefix:   Bool(true),
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		st, err := NewSlimTrie(encode.Int{}, keys, values, c.input)
+		ta.NoError(err)
+		ta.Equal(c.want, st.BuildOptions(), "%d-th: case: %+v", i+1, c)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.Int{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+		ta.Equal(c.want, st2.BuildOptions(), "%d-th: case: %+v", i+1, c)
+	}
+
+	t.Run("notRecorded", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, keys, values, Opt{ValueType: "foo"})
+		ta.NoError(err)
+
+		st.inner.HasBuildOpt = false
+		ta.Equal(Opt{ValueType: "foo"}, st.BuildOptions())
+	})
+}
+
+func TestSlimTrie_BuiltAt(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := marshalCase.keys
+	values := marshalCase.values
+
+	st, err := NewSlimTrie(encode.Int{}, keys, values)
+	ta.NoError(err)
+	ta.True(st.BuiltAt().IsZero())
+	ta.Equal(time.Duration(0), st.Age())
+
+	before := time.Now()
+	st, err = NewSlimTrie(encode.Int{}, keys, values, Opt{WithBuildTime: Bool(true)})
+	ta.NoError(err)
+	after := time.Now()
+
+	ta.False(st.BuiltAt().Before(before))
+	ta.False(st.BuiltAt().After(after))
+	ta.True(st.Age() >= 0)
+	ta.True(*st.BuildOptions().WithBuildTime)
+
+	buf, err := st.Marshal()
+	ta.NoError(err)
+
+	st2, err := NewSlimTrie(encode.Int{}, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st2.Unmarshal(buf))
+	ta.True(st.BuiltAt().Equal(st2.BuiltAt()))
+}
@@ -0,0 +1,59 @@
+package trie
+
+import (
+	"bytes"
+)
+
+// PrefixPage returns a page of at most limit values of keys with the specified
+// prefix, in key order, e.g., for loading autocomplete results page by page.
+//
+// The page starts after the continuation key `after`, which is the nextAfter
+// returned by the previous call.
+// An empty `after` starts from the first key with the prefix.
+// The continuation key does not need to be present in SlimTrie, thus a page
+// always starts from the first key greater than `after`.
+//
+// It returns the values, the key of the last value as the continuation key for
+// the next page, and a bool indicating whether there are no more keys with the
+// prefix.
+// If the page is empty, the continuation key is `after` itself.
+// A limit <= 0 means no limit.
+//
+// If SlimTrie does not store values, the returned values are all nil.
+//
+// PrefixPage requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (st *SlimTrie) PrefixPage(prefix string, after string, limit int) (values []interface{}, nextAfter string, done bool) {
+
+	values = make([]interface{}, 0)
+	nextAfter = after
+	p := []byte(prefix)
+
+	start, includeStart := prefix, true
+	if after >= prefix {
+		start, includeStart = after, false
+	}
+
+	withValue := st.getLeaves() != nil
+	nxt := st.NewIter(start, includeStart, withValue)
+
+	for {
+		key, val := nxt()
+		if key == nil || !bytes.HasPrefix(key, p) {
+			return values, nextAfter, true
+		}
+
+		if limit > 0 && len(values) == limit {
+			// there is at least one more key with the prefix.
+			return values, nextAfter, false
+		}
+
+		var v interface{}
+		if withValue {
+			v = st.decodeLeaf(val)
+		}
+		values = append(values, v)
+		nextAfter = string(key)
+	}
+}
@@ -0,0 +1,98 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_PrefixPage(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"a",
+		"ab",
+		"abc",
+		"abcd",
+		"abd",
+		"ac",
+		"b",
+		"bc",
+	}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	cases := []struct {
+		prefix    string
+		after     string
+		limit     int
+		wantVals  []interface{}
+		wantAfter string
+		wantDone  bool
+	}{
+		{"ab", "", 0, []interface{}{int32(1), int32(2), int32(3), int32(4)}, "abd", true},
+		{"ab", "", 2, []interface{}{int32(1), int32(2)}, "abc", false},
+		{"ab", "abc", 2, []interface{}{int32(3), int32(4)}, "abd", true},
+		{"ab", "abd", 2, []interface{}{}, "abd", true},
+		{"ab", "", 4, []interface{}{int32(1), int32(2), int32(3), int32(4)}, "abd", true},
+
+		// continuation key that does not exist
+		{"ab", "abcc", 2, []interface{}{int32(3), int32(4)}, "abd", true},
+		{"ab", "abz", 2, []interface{}{}, "abz", true},
+
+		// continuation key out of prefix
+		{"ab", "a", 1, []interface{}{int32(1)}, "ab", false},
+		{"ab", "b", 1, []interface{}{}, "b", true},
+
+		{"", "", 3, []interface{}{int32(0), int32(1), int32(2)}, "abc", false},
+		{"", "ac", 3, []interface{}{int32(6), int32(7)}, "bc", true},
+		{"b", "", 0, []interface{}{int32(6), int32(7)}, "bc", true},
+		{"c", "", 0, []interface{}{}, "", true},
+		{"abcde", "", 0, []interface{}{}, "", true},
+	}
+
+	for i, c := range cases {
+		vals, nxt, done := st.PrefixPage(c.prefix, c.after, c.limit)
+		ta.Equal(c.wantVals, vals, "%d-th: case: %+v", i+1, c)
+		ta.Equal(c.wantAfter, nxt, "%d-th: case: %+v", i+1, c)
+		ta.Equal(c.wantDone, done, "%d-th: case: %+v", i+1, c)
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		var all []interface{}
+		after := ""
+		for {
+			vals, nxt, done := st.PrefixPage("a", after, 2)
+			all = append(all, vals...)
+			after = nxt
+			if done {
+				break
+			}
+		}
+		ta.Equal([]interface{}{int32(0), int32(1), int32(2), int32(3), int32(4), int32(5)}, all)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		vals, nxt, done := st.PrefixPage("a", "", 2)
+		ta.Equal([]interface{}{}, vals)
+		ta.Equal("", nxt)
+		ta.True(done)
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		vals, nxt, done := st.PrefixPage("b", "", 0)
+		ta.Equal([]interface{}{nil, nil}, vals)
+		ta.Equal("bc", nxt)
+		ta.True(done)
+	})
+}
@@ -0,0 +1,275 @@
+package trie
+
+import (
+	"bytes"
+
+	"github.com/openacid/low/bitmap"
+)
+
+// Route looks up key and the longest stored key that is a prefix of key, in
+// one descent.
+//
+// It returns the value of key and a bool indicating if key is found, the same
+// as Get().
+// And it returns the value of the longest stored key that is a prefix of key,
+// including key itself, and a bool indicating if there is such a key.
+//
+// Without Opt{Complete: Bool(true)}, like Get(), there could be false
+// positives for both of them.
+//
+// Since 0.5.12
+func (st *SlimTrie) Route(key string) (exact interface{}, exactOK bool, prefix interface{}, prefixOK bool) {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return nil, false, nil, false
+	}
+
+	pID := int32(-1)
+	eqID := st.prefixWalk(key, func(leafID int32, keyLen int32) {
+		pID = leafID
+	})
+
+	if eqID != -1 {
+		exact, exactOK = st.lookupLeaf(eqID)
+	}
+	if pID != -1 {
+		prefix, prefixOK = st.lookupLeaf(pID)
+	}
+	return
+}
+
+// PathValues returns the values of every stored key that is a prefix of key,
+// including key itself, from the shortest to the longest, in one descent.
+// E.g., with stored keys "a", "a/b" and "a/b/c", PathValues("a/b/x") returns
+// the values of "a" and "a/b".
+// It returns an empty slice if there is no such key.
+//
+// A stored key that is a prefix of other keys ends at an inner node with an
+// empty label, thus it is found in any configuration.
+// But only with Opt{Complete: Bool(true)} every returned key is absolutely a
+// prefix of key.
+// Otherwise, like Get(), the unstored part of a key is not compared and there
+// could be false positives.
+// With Opt.DedupValue, which is the default, a key removed for having the
+// same value as the previous key is not returned.
+//
+// Since 0.5.12
+func (st *SlimTrie) PathValues(key string) []interface{} {
+
+	rst := make([]interface{}, 0)
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return rst
+	}
+
+	st.prefixWalk(key, func(leafID int32, keyLen int32) {
+		rst = append(rst, st.getLeaf(leafID))
+	})
+
+	return rst
+}
+
+// LongestPrefix returns the value of the longest stored key that is a prefix
+// of key, including key itself, and the length in byte of it, e.g., for
+// longest-prefix-match routing.
+// An exact match reports len(key).
+// It returns 0, nil and false if there is no such key.
+//
+// It is the prefix part of Route(), and like Route(), only with
+// Opt{Complete: Bool(true)} the matched key is absolutely a prefix of key.
+// With Opt.KeyNormalizer or Opt.Collation, matchedLen is the length of the
+// matched key in the normalized or collated form of key.
+//
+// Since 0.5.12
+func (st *SlimTrie) LongestPrefix(key string) (matchedLen int, value interface{}, ok bool) {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return 0, nil, false
+	}
+
+	pID := int32(-1)
+	st.prefixWalk(key, func(nodeID int32, keyLen int32) {
+		pID = nodeID
+		matchedLen = int(keyLen)
+	})
+
+	if pID == -1 {
+		return 0, nil, false
+	}
+
+	// A terminated key reports the length including the terminator.
+	if matchedLen > len(key) {
+		matchedLen = len(key)
+	}
+
+	v, ok := st.lookupLeaf(pID)
+	if !ok {
+		return 0, nil, false
+	}
+	return matchedLen, v, true
+}
+
+// prefixWalk descends along key and calls fn with the node id and the length
+// in byte of every stored key that is a prefix of key, from the shortest to
+// the longest, including key itself.
+// It returns the node id of key, which is the same as GetID(), or -1.
+// A key deleted by Delete() is neither reported nor returned.
+//
+// A stored key that is a prefix of key is either the leaf of an empty label, on
+// the path of key, or the last leaf key leads to.
+//
+// It requires a non-empty slim.
+//
+// Since 0.5.12
+func (st *SlimTrie) prefixWalk(key string, fn func(nodeID int32, keyLen int32)) int32 {
+
+	// a deleted key is not a stored key.
+	report := func(nodeID int32, keyLen int32) {
+		if !st.isDeleted(nodeID) {
+			fn(nodeID, keyLen)
+		}
+	}
+
+	key = st.sortKey(key)
+
+	eqID := int32(0)
+	l := int32(8 * len(key))
+
+	qr := &querySession{
+		keyBitLen: l,
+		key:       key,
+	}
+
+	i := int32(0)
+
+	for {
+
+		st.getNode(eqID, qr)
+		if qr.isInner == 0 {
+			// leaf
+			break
+		}
+
+		if qr.hasInnerPrefix {
+			r := strCmpUpto(key[i>>3:], qr.innerPrefix)
+			if r != 0 {
+				return -1
+			}
+			i = i&(^7) + qr.innerPrefixLen
+		} else {
+			i += qr.innerPrefixLen
+		}
+
+		if i > l {
+			return -1
+		}
+
+		if i < l {
+			// A stored key ends at this node. If key ends here too, it is
+			// reported as the leaf key leads to.
+			emptyID, has := st.getEmptyLabelChildID(qr)
+			if has == 1 {
+				report(emptyID, i>>3)
+			}
+		}
+
+		lchID, has := st.getLeftChildID(qr, i)
+		if has == 0 {
+			return -1
+		}
+		eqID = lchID + 1
+
+		if i == l {
+			// the key finished and matches the 0-th bit in the bitmap.
+			break
+		}
+
+		i += qr.wordSize
+	}
+
+	if st.inner.LeafPrefixes == nil {
+		// no way to tell, assume it matches.
+		report(eqID, l>>3)
+		return st.undeletedID(eqID)
+	}
+
+	var leafPrefix []byte
+	if qr.hasLeafPrefix {
+		leafPrefix = qr.leafPrefix
+	}
+
+	if i == l {
+		if qr.hasLeafPrefix {
+			return -1
+		}
+		report(eqID, i>>3)
+		return st.undeletedID(eqID)
+	}
+
+	tail := []byte(key[i>>3:])
+	if !bytes.HasPrefix(tail, leafPrefix) {
+		return -1
+	}
+
+	report(eqID, i>>3+int32(len(leafPrefix)))
+	if len(tail) != len(leafPrefix) {
+		return -1
+	}
+	return st.undeletedID(eqID)
+}
+
+// undeletedID returns nodeID, or -1 if it is deleted by Delete().
+//
+// Since 0.5.12
+func (st *SlimTrie) undeletedID(nodeID int32) int32 {
+	if st.isDeleted(nodeID) {
+		return -1
+	}
+	return nodeID
+}
+
+// getEmptyLabelChildID returns the id of the child of the 0-bit label of an
+// inner node, and if the 0-bit label is set.
+// The 0-bit label indicates a key ends at this node.
+//
+// Since 0.5.12
+func (st *SlimTrie) getEmptyLabelChildID(qr *querySession) (int32, int32) {
+
+	ns := st.inner
+
+	r0, bit := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+	if qr.to-qr.from == ns.ShortSize {
+		bit = int32(qr.bm & 1)
+	}
+	return r0 + 1, bit
+}
+
+// IsPrefixFree returns true if no stored key is a prefix of another stored
+// key.
+//
+// A key that is a prefix of others ends at an inner node, where it is
+// separated from others by a branch of the empty label.
+// IsPrefixFree checks if there is such a branch in any inner node.
+//
+// Since 0.5.12
+func (st *SlimTrie) IsPrefixFree() bool {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return true
+	}
+
+	nodeCnt := st.levels[len(st.levels)-1].total
+	qr := &querySession{}
+
+	for id := int32(0); id < nodeCnt; id++ {
+		st.getNode(id, qr)
+		if qr.isInner == 0 {
+			continue
+		}
+		if _, has := st.getEmptyLabelChildID(qr); has == 1 {
+			return false
+		}
+	}
+
+	return true
+}
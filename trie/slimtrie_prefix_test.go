@@ -0,0 +1,312 @@
+package trie
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// prefixCaseKeys returns sorted unique keys in which many keys are prefix of
+// others.
+func prefixCaseKeys() []string {
+
+	keys := []string{
+		"",
+		"a",
+		"ab",
+		"abc",
+		"abcdefgh",
+		"abd",
+		"b",
+		"ba",
+		"bbbbbbbbbbb",
+		"bbbbbbbbbbbbbbbb",
+		"c",
+	}
+
+	for _, k := range testutil.RandStrSlice(1000, 0, 8) {
+		for j := 0; j <= len(k); j += 3 {
+			keys = append(keys, k[:j])
+		}
+	}
+
+	sort.Strings(keys)
+
+	uniq := keys[:1]
+	for _, k := range keys[1:] {
+		if k != uniq[len(uniq)-1] {
+			uniq = append(uniq, k)
+		}
+	}
+	return uniq
+}
+
+func TestSlimTrie_Route(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := prefixCaseKeys()
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	qs := append(testutil.RandStrSlice(1000, 0, 10), keys...)
+	qs = append(qs, "abcdefghi", "abcdefg", "abx", "bbbbbbbbbbbbb", "d", "cc")
+
+	for _, q := range qs {
+		wantPrefix := -1
+		wantExact := -1
+		for i, k := range keys {
+			if strings.HasPrefix(q, k) {
+				wantPrefix = i
+			}
+			if k == q {
+				wantExact = i
+			}
+		}
+
+		exact, exactOK, prefix, prefixOK := st.Route(q)
+
+		ta.Equal(wantExact != -1, exactOK, "q: %q", q)
+		ta.Equal(wantPrefix != -1, prefixOK, "q: %q", q)
+		if wantExact != -1 {
+			ta.Equal(values[wantExact], exact, "q: %q", q)
+		}
+		if wantPrefix != -1 {
+			ta.Equal(values[wantPrefix], prefix, "q: %q", q)
+		}
+
+		v, found := st.Get(q)
+		ta.Equal(found, exactOK, "q: %q", q)
+		ta.Equal(v, exact, "q: %q", q)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		_, exactOK, _, prefixOK := st.Route("a")
+		ta.False(exactOK)
+		ta.False(prefixOK)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, []string{"a", "ab", "abc"}, []int32{1, 2, 3}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.True(st.Delete("ab"))
+
+		exact, exactOK, prefix, prefixOK := st.Route("ab")
+		ta.False(exactOK)
+		ta.Nil(exact)
+		ta.True(prefixOK)
+		ta.Equal(int32(1), prefix)
+
+		_, exactOK, prefix, prefixOK = st.Route("abx")
+		ta.False(exactOK)
+		ta.True(prefixOK)
+		ta.Equal(int32(1), prefix)
+	})
+
+	t.Run("singleKey", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, []string{"ab"}, []int32{5}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		exact, exactOK, prefix, prefixOK := st.Route("abc")
+		ta.False(exactOK)
+		ta.Nil(exact)
+		ta.True(prefixOK)
+		ta.Equal(int32(5), prefix)
+
+		exact, exactOK, prefix, prefixOK = st.Route("ab")
+		ta.True(exactOK)
+		ta.Equal(int32(5), exact)
+		ta.True(prefixOK)
+		ta.Equal(int32(5), prefix)
+
+		_, exactOK, _, prefixOK = st.Route("a")
+		ta.False(exactOK)
+		ta.False(prefixOK)
+	})
+}
+
+func TestSlimTrie_LongestPrefix(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := prefixCaseKeys()
+	values := makeI32s(len(keys))
+
+	for _, terminated := range []bool{false, true} {
+		opt := Opt{Complete: Bool(true), WithTerminator: Bool(terminated)}
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		qs := append(testutil.RandStrSlice(1000, 0, 10), keys...)
+		qs = append(qs, "abcdefghi", "abcdefg", "abx", "bbbbbbbbbbbbb", "d", "cc")
+
+		for _, q := range qs {
+			want := -1
+			for i, k := range keys {
+				if strings.HasPrefix(q, k) && (!terminated || k == q) {
+					want = i
+				}
+			}
+
+			l, v, ok := st.LongestPrefix(q)
+			ta.Equal(want != -1, ok, "q: %q", q)
+			if want != -1 {
+				ta.Equal(len(keys[want]), l, "q: %q", q)
+				ta.Equal(values[want], v, "q: %q", q)
+			} else {
+				ta.Equal(0, l, "q: %q", q)
+				ta.Nil(v, "q: %q", q)
+			}
+		}
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		_, _, ok := st.LongestPrefix("a")
+		ta.False(ok)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, []string{"a", "ab", "abc"}, []int32{1, 2, 3}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.True(st.Delete("ab"))
+
+		l, v, ok := st.LongestPrefix("abx")
+		ta.True(ok)
+		ta.Equal(1, l)
+		ta.Equal(int32(1), v)
+
+		l, v, ok = st.LongestPrefix("abc")
+		ta.True(ok)
+		ta.Equal(3, l)
+		ta.Equal(int32(3), v)
+
+		ta.True(st.Delete("a"))
+		_, _, ok = st.LongestPrefix("abx")
+		ta.False(ok)
+	})
+}
+
+func TestSlimTrie_PathValues(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := prefixCaseKeys()
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	qs := append(testutil.RandStrSlice(1000, 0, 10), keys...)
+	qs = append(qs, "abcdefghi", "abx", "bbbbbbbbbbbbb", "d")
+
+	for _, q := range qs {
+		want := []interface{}{}
+		for i, k := range keys {
+			if strings.HasPrefix(q, k) {
+				want = append(want, values[i])
+			}
+		}
+		ta.Equal(want, st.PathValues(q), "q: %q", q)
+	}
+
+	t.Run("hierarchy", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.String16{},
+			[]string{"/", "/etc", "/etc/nginx", "/usr"},
+			[]string{"root", "etc", "nginx", "usr"},
+			Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ta.Equal([]interface{}{"root", "etc", "nginx"}, st.PathValues("/etc/nginx/nginx.conf"))
+		ta.Equal([]interface{}{"root"}, st.PathValues("/var"))
+		ta.Equal([]interface{}{}, st.PathValues("etc"))
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, []string{"a", "ab", "abc"}, []int32{1, 2, 3}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.True(st.Delete("ab"))
+
+		ta.Equal([]interface{}{int32(1), int32(3)}, st.PathValues("abcd"))
+		ta.Equal([]interface{}{int32(1)}, st.PathValues("ab"))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.Equal([]interface{}{}, st.PathValues("a"))
+	})
+}
+
+func BenchmarkSlimTrie_Route(b *testing.B) {
+
+	keys := prefixCaseKeys()
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+
+	b.Run("Route", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _, _ = st.Route(keys[i%len(keys)])
+		}
+	})
+
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = st.Get(keys[i%len(keys)])
+		}
+	})
+}
+
+func TestSlimTrie_IsPrefixFree(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		keys []string
+		want bool
+	}{
+		{[]string{}, true},
+		{[]string{"a"}, true},
+		{[]string{"", "a"}, false},
+		{[]string{"a", "b", "c"}, true},
+		{[]string{"abc", "abcd", "abd"}, false},
+		{[]string{"abc", "abd", "b", "bc"}, false},
+		{[]string{"abc", "abd", "b\x00", "bc"}, true},
+		{marshalCase.keys, false},
+		{getKeys("20kvl10"), false},
+		{prefixCaseKeys(), false},
+	}
+
+	for i, c := range cases {
+		want := true
+		for j := 1; j < len(c.keys); j++ {
+			if strings.HasPrefix(c.keys[j], c.keys[j-1]) {
+				want = false
+			}
+		}
+		ta.Equal(c.want, want, "%d-th: brute force", i+1)
+
+		for _, opt := range []Opt{{}, {Complete: Bool(true)}} {
+			st, err := NewSlimTrie(encode.I32{}, c.keys, makeI32s(len(c.keys)), opt)
+			ta.NoError(err)
+			ta.Equal(c.want, st.IsPrefixFree(), "%d-th: keys: %q", i+1, c.keys)
+		}
+	}
+}
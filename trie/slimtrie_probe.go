@@ -0,0 +1,175 @@
+package trie
+
+import "bytes"
+
+// ProbeStep describes how a node is visited when querying a key.
+// Bit indexes are counted from the most significant bit of the first byte of
+// the key.
+//
+// Since 0.5.12
+type ProbeStep struct {
+	// NodeID is the id of the visited node.
+	NodeID int32
+
+	// IsLeaf is true if this node is a leaf.
+	IsLeaf bool
+
+	// From and To is the bit range [From, To) of the key consumed by this
+	// node, including prefix and label.
+	From, To int32
+
+	// PrefixLen is the length in bit of the prefix of this node.
+	// For a leaf, it is the length of the leaf prefix.
+	PrefixLen int32
+
+	// PrefixChecked is true if the prefix content is stored and compared with
+	// the key.
+	// Otherwise, only the prefix length is stored, the prefix bits of the key
+	// are skipped.
+	PrefixChecked bool
+
+	// PrefixMatched is true if the prefix content matches the key.
+	// It is always true if PrefixChecked is false.
+	PrefixMatched bool
+
+	// LabelBits is the size in bit of the label taken from the key, 4 or 8.
+	// It is 0 if the key is used up at this node, which takes the empty
+	// label.
+	// It is 0 for a leaf.
+	LabelBits int32
+
+	// Label is the value of the label taken from the key.
+	Label int32
+
+	// Matched is true if the query goes on: for an inner node, it has a
+	// branch of Label. For a leaf, the query finds it.
+	Matched bool
+}
+
+// KeyProbe traces how key flows through SlimTrie and returns one ProbeStep for
+// every visited node, from the root to where the query ends.
+// It is the same walk as GetID() does, and the last step has Matched being
+// true if and only if GetID() finds a leaf.
+//
+// It is meant for debugging and it is much slower than GetID().
+//
+// Since 0.5.12
+func (st *SlimTrie) KeyProbe(key string) []ProbeStep {
+
+	steps := make([]ProbeStep, 0)
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return steps
+	}
+
+	l := int32(8 * len(key))
+
+	qr := &querySession{
+		keyBitLen: l,
+		key:       key,
+	}
+
+	id, i := int32(0), int32(0)
+
+	for {
+
+		st.getNode(id, qr)
+
+		s := ProbeStep{
+			NodeID:        id,
+			From:          i,
+			PrefixMatched: true,
+		}
+
+		if qr.isInner == 0 {
+			s.IsLeaf = true
+			st.probeLeaf(key, i, qr, &s)
+			steps = append(steps, s)
+			return steps
+		}
+
+		s.PrefixLen = qr.innerPrefixLen
+
+		if qr.hasInnerPrefix {
+			s.PrefixChecked = true
+			// the prefix is stored from the byte i is in.
+			s.PrefixLen = qr.innerPrefixLen - i&7
+			if strCmpUpto(key[i>>3:], qr.innerPrefix) != 0 {
+				s.PrefixMatched = false
+				s.To = i
+				steps = append(steps, s)
+				return steps
+			}
+			i = i&(^7) + qr.innerPrefixLen
+		} else {
+			i += qr.innerPrefixLen
+		}
+
+		if i > l {
+			s.To = l
+			steps = append(steps, s)
+			return steps
+		}
+
+		lchID, has := st.getLeftChildID(qr, i)
+		if i < l {
+			s.LabelBits = qr.wordSize
+			s.Label = st.getLabelIdxOfKey(qr, i) - 1
+			i += qr.wordSize
+		}
+		s.To = i
+		s.Matched = has != 0
+		steps = append(steps, s)
+
+		if has == 0 {
+			return steps
+		}
+
+		id = lchID + 1
+
+		if s.LabelBits == 0 {
+			// The key is used up and it takes the empty label, which leads
+			// to a leaf without prefix.
+			st.getNode(id, qr)
+			leaf := ProbeStep{
+				NodeID:        id,
+				IsLeaf:        true,
+				From:          i,
+				To:            i,
+				PrefixMatched: true,
+				Matched:       true,
+			}
+			steps = append(steps, leaf)
+			return steps
+		}
+	}
+}
+
+// probeLeaf fills in the leaf step s, the same as the leaf prefix check in
+// getIDFrom().
+func (st *SlimTrie) probeLeaf(key string, i int32, qr *querySession, s *ProbeStep) {
+
+	l := int32(8 * len(key))
+
+	s.To = i
+	s.Matched = true
+
+	if st.inner.LeafPrefixes == nil {
+		return
+	}
+
+	s.PrefixChecked = true
+	if qr.hasLeafPrefix {
+		s.PrefixLen = int32(len(qr.leafPrefix) * 8)
+	}
+
+	if i == l {
+		s.PrefixMatched = !qr.hasLeafPrefix
+	} else {
+		s.PrefixMatched = qr.hasLeafPrefix && bytes.Equal(qr.leafPrefix, []byte(key[i>>3:]))
+		if s.PrefixMatched {
+			s.To = l
+		}
+	}
+	s.Matched = s.PrefixMatched
+}
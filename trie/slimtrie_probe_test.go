@@ -0,0 +1,93 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_KeyProbe(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 10; seed++ {
+
+		rng := rand.New(rand.NewSource(seed))
+		opts := []Opt{
+			{Complete: Bool(true), DedupValue: Bool(false)},
+			{DedupValue: Bool(false)},
+		}
+		st, kvs := RandomTrie(rng, 300, 8, opts[seed%2])
+		if len(kvs) == 0 {
+			continue
+		}
+
+		qs := make([]string, 0)
+		for k := range kvs {
+			qs = append(qs, k)
+		}
+		for i := 0; i < 300; i++ {
+			qs = append(qs, randKey(rng, 9))
+		}
+
+		for _, q := range qs {
+
+			var steps []ProbeStep
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("seed %d key %q %v", seed, q, r)
+					}
+				}()
+				steps = st.KeyProbe(q)
+			}()
+			ta.True(len(steps) > 0)
+
+			id := st.GetID(q)
+			last := steps[len(steps)-1]
+
+			ta.Equal(id != -1, last.Matched, "key: %q", q)
+			if id != -1 {
+				ta.True(last.IsLeaf)
+				ta.Equal(id, last.NodeID)
+			}
+
+			ta.Equal(int32(0), steps[0].NodeID)
+			for j := 1; j < len(steps); j++ {
+				prev, s := steps[j-1], steps[j]
+				ta.True(prev.Matched)
+				ta.False(prev.IsLeaf)
+				ta.Equal(prev.To, s.From)
+				ta.Contains(st.Children(prev.NodeID), s.NodeID, "seed %d key %q prev %+v children %v", seed, q, prev, st.Children(prev.NodeID))
+			}
+		}
+	}
+
+	t.Run("tiny", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, []string{"ab", "ac"}, []int32{1, 2},
+			Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		// 'b' = 0x62, 'c' = 0x63: the root node has a prefix of "a" and "6"
+		// and branches at the last 4 bits.
+		ta.Equal([]ProbeStep{
+			{NodeID: 0, From: 0, To: 16, PrefixLen: 12, PrefixChecked: true, PrefixMatched: true,
+				LabelBits: 4, Label: 3, Matched: true},
+			{NodeID: 2, IsLeaf: true, From: 16, To: 16, PrefixChecked: true, PrefixMatched: true,
+				Matched: true},
+		}, st.KeyProbe("ac"))
+
+		steps := st.KeyProbe("b")
+		ta.Equal(1, len(steps))
+		ta.False(steps[0].PrefixMatched)
+		ta.False(steps[0].Matched)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.Equal([]ProbeStep{}, st.KeyProbe("a"))
+	})
+}
@@ -3,10 +3,13 @@ package trie
 import (
 	"bytes"
 	"math/bits"
+	"reflect"
+	"unsafe"
 
 	"github.com/openacid/low/bitmap"
 	"github.com/openacid/low/bitstr"
 	"github.com/openacid/low/bmtree"
+	"github.com/openacid/must"
 )
 
 type querySession struct {
@@ -37,6 +40,10 @@ type querySession struct {
 	ithLeaf       int32
 	hasLeafPrefix bool
 	leafPrefix    []byte
+
+	// keyBit is the number of leading bits of key getIDFrom() walks through
+	// to find a leaf.
+	keyBit int32
 }
 
 // Get the value of the specified key from SlimTrie.
@@ -48,6 +55,10 @@ type querySession struct {
 // info of all keys.
 // SlimTrie tell you "WHERE IT POSSIBLY BE", rather than "IT IS JUST THERE".
 //
+// With Opt{Complete: Bool(true)}, it returns a value only if key is exactly a
+// stored key.
+// E.g., with stored keys {"ab"}, Get("abc") and Get("a") are both not found.
+//
 // Since 0.2.0
 func (st *SlimTrie) Get(key string) (interface{}, bool) {
 
@@ -57,8 +68,61 @@ func (st *SlimTrie) Get(key string) (interface{}, bool) {
 		return nil, false
 	}
 
-	v := st.getLeaf(eqID)
-	return v, true
+	return st.lookupLeaf(eqID)
+}
+
+// ExactGet returns the value of key and true only if key is verified to be
+// exactly a stored key.
+//
+// Opt{Complete: Bool(true)} stores the complete content of all keys, with
+// inner prefixes and leaf prefixes, and Get() already compares every byte of
+// key with them.
+// ExactGet is Get() on such a SlimTrie.
+// On a SlimTrie built without it, a key can not be verified, and ExactGet
+// always returns nil and false, rather than a possibly false positive.
+//
+// Since 0.5.12
+func (st *SlimTrie) ExactGet(key string) (interface{}, bool) {
+
+	if !st.hasCompleteKeys() {
+		return nil, false
+	}
+
+	return st.Get(key)
+}
+
+// GetWithDepth is the same as Get() except it also returns the number of
+// leading bits of key SlimTrie walks through to find the leaf, i.e., the bits
+// consumed by inner node prefixes and labels, or all bits of key if leaf
+// prefixes are stored and compared.
+// The bits after matchedBits are not looked at, and a key that differs from a
+// stored key only in these bits is a false positive.
+//
+// Without Opt.InnerPrefix, only the lengths of inner node prefixes are
+// stored, and the prefix bits are walked through without being compared.
+//
+// If Opt.Collation or Opt.WithTerminator is used, bits are counted in the
+// converted key.
+// It returns nil, 0 and false if key is not found.
+//
+// Since 0.5.12
+func (st *SlimTrie) GetWithDepth(key string) (value interface{}, matchedBits int, ok bool) {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return nil, 0, false
+	}
+
+	qr := &querySession{}
+	eqID := st.getID(key, qr)
+	if eqID == -1 {
+		return nil, 0, false
+	}
+
+	v, ok := st.lookupLeaf(eqID)
+	if !ok {
+		return nil, 0, false
+	}
+	return v, int(qr.keyBit), true
 }
 
 // RangeGet look for a range that contains a key in SlimTrie.
@@ -79,7 +143,7 @@ func (st *SlimTrie) RangeGet(key string) (interface{}, bool) {
 	// an "equal" match means key is a prefix of either start or end of a range.
 	if eqID != -1 {
 		// TODO eqID must be a leaf if it is not -1
-		return st.getLeaf(eqID), true
+		return st.lookupLeaf(eqID)
 	}
 
 	// key is smaller than any range-start or range-end.
@@ -90,7 +154,40 @@ func (st *SlimTrie) RangeGet(key string) (interface{}, bool) {
 	// Preceding value is the start of this range.
 	// It might be a false-positive
 
-	return st.getLeaf(lID), true
+	return st.lookupLeaf(lID)
+}
+
+// RangeGetWithBounds is the same as RangeGet() except it also returns the
+// leaf ids bounding the range that contains key:
+// startLeaf is the leaf of range-start, i.e., the greatest key <= key, and
+// endLeaf is the leaf right after it in key order, or -1 if range-start is
+// the greatest key.
+// Leaf ids are node ids the same as GetID() returns.
+//
+// A caller that stores the range bounds elsewhere, e.g., in an array indexed
+// by the leaf ordinal of LeafOrdinalRange(), is able to check a hit against
+// them and eliminate false positives.
+//
+// It returns -1, -1, nil and false if no range contains key.
+//
+// Since 0.5.12
+func (st *SlimTrie) RangeGetWithBounds(key string) (startLeaf, endLeaf int32, value interface{}, ok bool) {
+
+	lID, eqID, rID := st.searchID(key)
+
+	if eqID != -1 {
+		lID = eqID
+	}
+
+	if lID == -1 {
+		return -1, -1, nil, false
+	}
+
+	v, ok := st.lookupLeaf(lID)
+	if !ok {
+		return -1, -1, nil, false
+	}
+	return lID, rID, v, true
 }
 
 // Search for a key in SlimTrie.
@@ -127,18 +224,83 @@ func (st *SlimTrie) Search(key string) (lVal, eqVal, rVal interface{}) {
 // Since 0.5.10
 func (st *SlimTrie) GetID(key string) int32 {
 
-	eqID := int32(0)
+	if st.inner.GetNodeTypeBM() == nil {
+		return -1
+	}
+
+	qr := &querySession{}
+	return st.getID(key, qr)
+}
+
+// GetLeafIndex returns the index of the leaf of key among all leaves, and
+// true, or -1 and false if key is not found.
+// The index is in [0, Len()), in breadth-first order of leaves, the same as
+// the ith passed to the callback of IterNonDefault(), not in key order.
+//
+// It lets a caller store values in its own slice of Len() elements, indexed
+// by leaf index, instead of in SlimTrie.
+//
+// Like Get(), without Opt{Complete: Bool(true)}, there could be false
+// positives.
+//
+// Since 0.5.12
+func (st *SlimTrie) GetLeafIndex(key string) (int32, bool) {
+
+	id := st.GetID(key)
+	if id == -1 {
+		return -1, false
+	}
+
+	ith, _ := st.getLeafIndex(id)
+	return ith, true
+}
+
+// getID is the implementation of GetID with a caller provided querySession,
+// thus batch queries could reuse one querySession.
+//
+// Since 0.5.12
+func (st *SlimTrie) getID(key string, qr *querySession) int32 {
+
+	key = st.sortKey(key)
 
-	if st.inner.NodeTypeBM == nil {
+	if st.vars.KeyLen != -1 && int32(len(key)) != st.vars.KeyLen {
 		return -1
 	}
 
-	l := int32(8 * len(key))
-	qr := &querySession{
-		keyBitLen: l,
-		key:       key,
+	// no stored key is longer than MaxKeyLen, and the bit length of such a
+	// key overflows.
+	if len(key) > MaxKeyLen {
+		return -1
+	}
+
+	// fast reject a key by its first byte without a traversal.
+	if len(key) > 0 {
+		b := key[0]
+		if st.vars.RootBytes[b>>6]&(1<<(b&63)) == 0 {
+			return -1
+		}
 	}
 
+	return st.getIDFrom(0, key, qr)
+}
+
+// getIDFrom is the same as getID except it starts from node nodeID, at the
+// 0-th bit of key.
+//
+// Since 0.5.12
+func (st *SlimTrie) getIDFrom(nodeID int32, key string, qr *querySession) int32 {
+
+	eqID := nodeID
+
+	l := int32(8 * len(key))
+
+	qr.keyBitLen = l
+	qr.key = key
+
+	// The loop below does not always visit a leaf, which resets
+	// hasLeafPrefix. Clear it for a reused querySession.
+	qr.hasLeafPrefix = false
+
 	i := int32(0)
 
 	for {
@@ -150,7 +312,7 @@ func (st *SlimTrie) GetID(key string) int32 {
 		}
 
 		if qr.hasInnerPrefix {
-			r := bitstr.StrCmpUpto(key[i>>3:], qr.innerPrefix)
+			r := strCmpUpto(key[i>>3:], qr.innerPrefix)
 			if r != 0 {
 				return -1
 			}
@@ -185,12 +347,13 @@ func (st *SlimTrie) GetID(key string) int32 {
 
 	// eqID must not be -1
 
+	qr.keyBit = i
+
 	if st.inner.LeafPrefixes != nil {
+		qr.keyBit = l
 		if i == l {
 			if qr.hasLeafPrefix {
 				return -1
-			} else {
-				return eqID
 			}
 		} else {
 			if !qr.hasLeafPrefix {
@@ -203,6 +366,10 @@ func (st *SlimTrie) GetID(key string) int32 {
 		}
 	}
 
+	if st.isDeleted(eqID) {
+		return -1
+	}
+
 	return eqID
 }
 
@@ -226,13 +393,38 @@ func (st *SlimTrie) cmpLeafPrefix(tail string, qr *querySession) int32 {
 // The id of greatest key < `key`. It is -1 if `key` is the smallest.
 // The id of `key`. It is -1 if there is not a matching.
 // The id of smallest key > `key`. It is -1 if `key` is the greatest.
+//
+// All of them are -1 if an invalid child id is found, which happens only with
+// corrupted data.
 func (st *SlimTrie) searchID(key string) (lID, eqID, rID int32) {
-	ns := st.inner
 
-	if st.inner.NodeTypeBM == nil {
+	if st.inner.GetNodeTypeBM() == nil {
 		return -1, -1, -1
 	}
 
+	key = st.sortKey(key)
+
+	// A key longer than MaxKeyLen is not stored and its bit length overflows.
+	// It is right after its first MaxKeyLen bytes, because no stored key is
+	// longer.
+	if len(key) > MaxKeyLen {
+		lID, eqID, rID = st.searchSortKey(key[:MaxKeyLen])
+		if eqID != -1 {
+			lID = eqID
+		}
+		return lID, -1, rID
+	}
+
+	return st.searchSortKey(key)
+}
+
+// searchSortKey is the implementation of searchID with a key already
+// converted by sortKey().
+//
+// Since 0.5.12
+func (st *SlimTrie) searchSortKey(key string) (lID, eqID, rID int32) {
+	ns := st.inner
+
 	lID, eqID, rID = -1, 0, -1
 
 	l := int32(8 * len(key))
@@ -252,7 +444,7 @@ func (st *SlimTrie) searchID(key string) (lID, eqID, rID int32) {
 		}
 
 		if qr.hasInnerPrefix {
-			r := bitstr.StrCmpUpto(key[i>>3:], qr.innerPrefix)
+			r := strCmpUpto(key[i>>3:], qr.innerPrefix)
 			if r == 0 {
 				i = i&(^7) + qr.innerPrefixLen
 			} else if r < 0 {
@@ -297,6 +489,9 @@ func (st *SlimTrie) searchID(key string) (lID, eqID, rID int32) {
 			eqID = -1
 			break
 		}
+		if !st.checkChild(eqID, chID) {
+			return -1, -1, -1
+		}
 		eqID = chID
 
 		if i == l {
@@ -324,15 +519,25 @@ func (st *SlimTrie) searchID(key string) (lID, eqID, rID int32) {
 	}
 
 	if lID != -1 {
-		lID = st.rightMost(lID)
+		lID = st.rightMost(lID, nil)
+		if lID == -1 {
+			return -1, -1, -1
+		}
 	}
 	if rID != -1 {
 		rID = st.leftMost(rID, nil)
+		if rID == -1 {
+			return -1, -1, -1
+		}
 	}
 
 	return
 }
 
+// leftMost returns the id of the left-most leaf in the subtree of node idx,
+// and appends node ids along the walk to path if it is not nil.
+// It returns -1 if an invalid child id is found.
+
 func (st *SlimTrie) leftMost(idx int32, path *[]int32) int32 {
 
 	ns := st.inner
@@ -350,16 +555,24 @@ func (st *SlimTrie) leftMost(idx int32, path *[]int32) int32 {
 
 		// follow the first child
 		r0, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+		if !st.checkChild(idx, r0+1) {
+			return -1
+		}
 		idx = r0 + 1
 	}
 	return idx
 }
 
-func (st *SlimTrie) rightMost(idx int32) int32 {
+// rightMost is the same as leftMost() except it returns the right-most leaf.
+func (st *SlimTrie) rightMost(idx int32, path *[]int32) int32 {
 
 	ns := st.inner
 
 	for {
+		if path != nil {
+			*path = append(*path, idx)
+		}
+
 		qr := &querySession{}
 		st.getNode(idx, qr)
 		if qr.isInner == 0 {
@@ -367,6 +580,9 @@ func (st *SlimTrie) rightMost(idx int32) int32 {
 		}
 
 		r0, bit := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.to-1)
+		if !st.checkChild(idx, r0+bit) {
+			return -1
+		}
 		idx = r0 + bit
 		// index out of range with this:
 		// r0, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, n.to)
@@ -375,6 +591,19 @@ func (st *SlimTrie) rightMost(idx int32) int32 {
 	return idx
 }
 
+// checkChild returns false if child is not a valid child id of parent.
+//
+// In valid data a child id is always greater than the parent id and is less
+// than the node count, thus a walk from parent to child ends in at most node
+// count steps.
+// A child id that breaks it could only be from corrupted data, which could make
+// a walk loop forever, thus a walk stops and reports not found instead.
+//
+// Since 0.5.12
+func (st *SlimTrie) checkChild(parent, child int32) bool {
+	return child > parent && child < st.levels[len(st.levels)-1].total
+}
+
 func (st *SlimTrie) getLeafPrefix(nodeid int32, qr *querySession) {
 
 	qr.ithLeaf, _ = st.getLeafIndex(nodeid)
@@ -402,6 +631,16 @@ func (st *SlimTrie) getLeafPrefix(nodeid int32, qr *querySession) {
 	}
 }
 
+// getNode loads the info of a node into qr.
+//
+// In a large trie every step here is likely a cache miss, and the loads
+// depend on each other: the rank in NodeTypeBM gives ithInner, the rank in
+// ShortBM gives the label bitmap position, and the rank of the label in
+// Inners gives the child id.
+// The word of the next node is not known until the last rank is done, and it
+// is read right after that, thus a software prefetch of it does not help.
+// See BenchmarkSlimTrie_GetID_10m: about 70 ns per query in key order and
+// 500 ns in random order.
 func (st *SlimTrie) getNode(nodeId int32, qr *querySession) {
 
 	ns := st.inner
@@ -512,6 +751,15 @@ func (st *SlimTrie) getLabelIdxOfKey(qr *querySession, keyBitIdx int32) int32 {
 //          v
 //     010011
 //  A   B  C
+//
+// For a short node, the rank is the rank at the start of the node plus the
+// popcount of the decoded bitmap.
+// Looking up a table of ranks precomputed for every ShortTable entry is not
+// faster, since a popcount costs no more than a table load.
+// The extra cost of a short node is the rank in ShortBM and the ShortTable
+// lookup in getNode().
+// See BenchmarkSlimTrie_GetID_ShortNodes: with 73% short nodes, a query is
+// about 8% slower than without ShortTable, for 13% less memory.
 func (st *SlimTrie) getLeftChildID(qr *querySession, keyBitIdx int32) (int32, int32) {
 
 	ns := st.inner
@@ -537,59 +785,154 @@ func (st *SlimTrie) getLeafIndex(nodeid int32) (int32, int32) {
 	return nodeid - r, ith
 }
 
+// getLeaf returns the value of the leaf nodeid, or nil if it is not a leaf.
 func (st *SlimTrie) getLeaf(nodeid int32) interface{} {
+	v, _ := st.lookupLeaf(nodeid)
+	return v
+}
+
+// lookupLeaf returns the value of the leaf nodeid and true.
+//
+// A query never lands on an inner node unless data is corrupted.
+// Instead of crashing a long-running server, it returns nil and false as if
+// the key is not found, so that the query reports a miss.
+// Built with tag debug, it panics to expose such a bug.
+//
+// Since 0.5.12
+func (st *SlimTrie) lookupLeaf(nodeid int32) (interface{}, bool) {
 	leafI, nodeType := st.getLeafIndex(nodeid)
 	if nodeType == 1 {
-		panic("impossible!!")
+		must.Be.True(false, "node %d is not a leaf", nodeid)
+		return nil, false
+	}
+
+	if st.valueFunc != nil {
+		return st.valueFunc.fn(st.keyOrdinal(nodeid)), true
 	}
 
-	return st.getIthLeaf(leafI)
+	bs, ok := st.getIthLeafBytes(leafI)
+	if !ok {
+		return nil, false
+	}
+	return st.decodeLeaf(bs), true
 }
 
 func (st *SlimTrie) getIthLeaf(ith int32) interface{} {
+	bs, _ := st.getIthLeafBytes(ith)
+	return st.decodeLeaf(bs)
+}
 
-	ls := st.inner.Leaves
-	if ls == nil {
+// decodeLeaf decodes the bytes of a leaf.
+// An absent leaf, which has no byte, is decoded to nil.
+//
+// Since 0.5.12
+func (st *SlimTrie) decodeLeaf(bs []byte) interface{} {
+	if len(bs) == 0 {
 		return nil
 	}
 
-	bs := ls.get(ith)
-
 	_, v := st.encoder.Decode(bs)
 	return v
 }
 
-func (st *SlimTrie) getIthLeafBytes(ith int32) []byte {
+// getIthLeafBytes returns the encoded value of the ith leaf, in any layout of
+// leaves: fixed size, variable length located by the position bitmap, blocks
+// or exceptions.
+// An absent leaf returns an empty slice.
+//
+// Leaves kept in a reader by UnmarshalIndexOnly() are read one by one.
+// It returns false if leaves kept in a reader fail to be read.
+func (st *SlimTrie) getIthLeafBytes(ith int32) ([]byte, bool) {
+
+	if st.lazyLeaves != nil {
+		b, ok, err := st.lazyLeaves.getIth(ith)
+		if err != nil {
+			return nil, false
+		}
+		if ok {
+			return b, true
+		}
+	}
 
-	ls := st.inner.Leaves
+	ls, ok := st.loadLeaves()
+	if !ok {
+		return nil, false
+	}
 	if ls == nil {
-		return nil
+		return nil, true
 	}
 
-	// TODO use FixedSize or bitmap for var-len leaves
-	// TODO it is possible there is a absent leaf
-	size := st.encoder.GetEncodedSize(nil)
-	idx := ith * int32(size)
+	return ls.get(ith), true
+}
 
-	return ls.Bytes[idx : idx+int32(size)]
+// All labels a normal or big inner node can have, in label order, and the
+// index of every label in the label bitmap of a node.
+// They are computed once so that decoding the labels of a node does not
+// allocate.
+var (
+	innerLabels, innerLabelIdxs       = allLabels(innerSize)
+	bigInnerLabels, bigInnerLabelIdxs = allLabels(bigInnerSize)
+)
+
+func allLabels(bitmapSize int32) ([]uint64, []int32) {
+	paths := bmtree.AllPaths(bitmapSize, 0, 1<<63)
+	idxs := make([]int32, len(paths))
+	for i, p := range paths {
+		idxs[i] = bmtree.PathToIndex(bitmapSize, p)
+	}
+	return paths, idxs
 }
 
+// getLabels returns the labels of the inner node loaded into qr, in order.
 func (st *SlimTrie) getLabels(qr *querySession) []uint64 {
-	bm, _ := st.getInnerBM(qr)
-	return bmtree.Decode(qr.to-qr.from, bm)
+	return st.appendLabels(nil, qr)
 }
 
-// getInnerBM retrieves the inner node bitmap cached by a querySession, and the size of bitmap.
-func (st *SlimTrie) getInnerBM(qr *querySession) ([]uint64, int32) {
+// appendLabels is the same as getLabels except it appends the labels to dst,
+// thus a caller visiting many nodes reuses one buffer.
+//
+// Since 0.5.12
+func (st *SlimTrie) appendLabels(dst []uint64, qr *querySession) []uint64 {
 
 	ns := st.inner
 
-	storedBMSize := qr.to - qr.from
+	if qr.to-qr.from == ns.ShortSize {
+		// qr.bm is already expanded to a innerSize-bit bitmap by getNode()
+		for i, idx := range innerLabelIdxs {
+			if qr.bm&bitmap.Bit[idx] != 0 {
+				dst = append(dst, innerLabels[i])
+			}
+		}
+		return dst
+	}
+
+	labels, idxs := innerLabels, innerLabelIdxs
+	if qr.wordSize == bigWordSize {
+		labels, idxs = bigInnerLabels, bigInnerLabelIdxs
+	}
 
-	if storedBMSize == ns.ShortSize {
-		return bmtree.Decode(innerSize, []uint64{qr.bm}), innerSize
+	words := ns.Inners.Words
+	for i, idx := range idxs {
+		j := qr.from + idx
+		if words[j>>6]&bitmap.Bit[j&63] != 0 {
+			dst = append(dst, labels[i])
+		}
 	}
+	return dst
+}
 
-	// normal or big inner node
-	return bitmap.Slice(ns.Inners.Words, qr.from, qr.to), storedBMSize
+// strCmpUpto is the same as bitstr.StrCmpUpto() except it converts a to a
+// []byte with a valid capacity.
+// bitstr.StrCmpUpto() converts a string to a []byte without setting the
+// capacity, and slicing it may panic depending on what follows the string
+// header in memory.
+//
+// Since 0.5.12
+func strCmpUpto(a string, b []byte) int {
+	var bs []byte
+	sh := (*reflect.SliceHeader)(unsafe.Pointer(&bs))
+	sh.Data = (*reflect.StringHeader)(unsafe.Pointer(&a)).Data
+	sh.Len = len(a)
+	sh.Cap = len(a)
+	return bitstr.CmpUpto(bs, b)
 }
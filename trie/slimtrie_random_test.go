@@ -0,0 +1,104 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// edgeBytes are bytes that are more likely to expose bugs with bit operations.
+var edgeBytes = []byte{0x00, 0x01, 0x0f, 0x10, 0x7f, 0x80, 0xf0, 0xfe, 0xff}
+
+// randKey generates a binary key of length in [0, maxKeyLen].
+// About half of the bytes are chosen from edgeBytes.
+func randKey(rng *rand.Rand, maxKeyLen int) string {
+	l := rng.Intn(maxKeyLen + 1)
+	b := make([]byte, l)
+	for i := range b {
+		if rng.Intn(2) == 0 {
+			b[i] = edgeBytes[rng.Intn(len(edgeBytes))]
+		} else {
+			b[i] = byte(rng.Intn(256))
+		}
+	}
+	return string(b)
+}
+
+// RandomTrie generates a random sorted key set of at most numKeys keys and
+// random int32 values, builds a SlimTrie with them and returns the SlimTrie and
+// a map of the keys and values, for cross-checking.
+//
+// Keys are binary keys including edge bytes such as 0x00 and 0xff.
+// Some keys are prefixes of others.
+//
+// The same rng state produces the same trie.
+// By default it builds a complete SlimTrie without DedupValue, thus every key
+// in the map is found with the same value.
+func RandomTrie(rng *rand.Rand, numKeys, maxKeyLen int, opts ...Opt) (*SlimTrie, map[string]interface{}) {
+
+	kvs := make(map[string]interface{}, numKeys)
+	for i := 0; i < numKeys; i++ {
+		k := randKey(rng, maxKeyLen)
+		if len(k) > 0 && rng.Intn(4) == 0 {
+			// make a key that is a prefix of another
+			kvs[k[:rng.Intn(len(k))]] = rng.Int31()
+		}
+		kvs[k] = rng.Int31()
+	}
+
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]int32, len(keys))
+	for i, k := range keys {
+		values[i] = kvs[k].(int32)
+	}
+
+	opt := Opt{Complete: Bool(true), DedupValue: Bool(false)}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+	if err != nil {
+		panic(err)
+	}
+
+	return st, kvs
+}
+
+func TestRandomTrie(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 20; seed++ {
+
+		st, kvs := RandomTrie(rand.New(rand.NewSource(seed)), 500, 8)
+
+		// deterministic
+		st2, kvs2 := RandomTrie(rand.New(rand.NewSource(seed)), 500, 8)
+		ta.Equal(kvs, kvs2)
+		slimtrieEqual(st, st2, t)
+
+		for k, v := range kvs {
+			got, found := st.Get(k)
+			ta.True(found, "seed: %d, key: %q", seed, k)
+			ta.Equal(v, got, "seed: %d, key: %q", seed, k)
+		}
+
+		rng := rand.New(rand.NewSource(seed + 1000))
+		for i := 0; i < 500; i++ {
+			k := randKey(rng, 9)
+			want, has := kvs[k]
+			got, found := st.Get(k)
+			ta.Equal(has, found, "seed: %d, key: %q", seed, k)
+			ta.Equal(want, got, "seed: %d, key: %q", seed, k)
+		}
+	}
+}
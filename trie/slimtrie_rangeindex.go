@@ -0,0 +1,241 @@
+package trie
+
+// rangeIndex records the range of key ordinals of the leaves in the subtree of
+// every node, built by SlimTrie.BuildRangeIndex().
+//
+// Since 0.5.12
+type rangeIndex struct {
+	// first is the key ordinal of the first leaf in the subtree of every node.
+	first []int32
+
+	// last is the key ordinal of the last leaf in the subtree of every node.
+	last []int32
+
+	// leaves is the node id of every leaf, in key order.
+	leaves []int32
+}
+
+// BuildRangeIndex precomputes the first and the last key ordinal of the
+// leaves in the subtree of every node, so that LeafOrdinalRange() is a single
+// lookup, SubtreeValues(), PrefixCount() and RangeCount() locate a key ordinal
+// in O(depth), and a value of Opt.ValueFunc is computed without walking the
+// trie.
+//
+// It costs 8 bytes per node and 4 bytes per leaf, and is not marshaled.
+// Unmarshal() or any other method that replaces the content of the SlimTrie
+// drops it, and it has to be built again.
+//
+// It must not be called concurrently with a query.
+//
+// Since 0.5.12
+func (st *SlimTrie) BuildRangeIndex() {
+
+	st.rangeIndex = nil
+
+	if st.inner.GetNodeTypeBM() == nil {
+		st.rangeIndex = &rangeIndex{}
+		return
+	}
+
+	total := st.levels[len(st.levels)-1].total
+	leafCnt := st.leafCount()
+
+	idx := &rangeIndex{
+		first:  make([]int32, total),
+		last:   make([]int32, total),
+		leaves: make([]int32, leafCnt),
+	}
+
+	// Node ids are in breadth-first order, thus children always have greater
+	// ids than their parent.
+	// Walk backward to count leaves in every subtree.
+	cnt := idx.last
+	for id := total - 1; id >= 0; id-- {
+		_, isInner := st.getLeafIndex(id)
+		if isInner == 0 {
+			cnt[id] = 1
+			continue
+		}
+		first, last := st.childRange(id)
+		cnt[id] = 0
+		for c := first; c <= last; c++ {
+			cnt[id] += cnt[c]
+		}
+	}
+
+	// Walk forward to assign the first ordinal of every child.
+	for id := int32(0); id < total; id++ {
+		_, isInner := st.getLeafIndex(id)
+		if isInner == 0 {
+			continue
+		}
+		first, last := st.childRange(id)
+		ord := idx.first[id]
+		for c := first; c <= last; c++ {
+			idx.first[c] = ord
+			ord += cnt[c]
+		}
+	}
+
+	for id := int32(0); id < total; id++ {
+		idx.last[id] = idx.first[id] + cnt[id] - 1
+	}
+
+	for id := int32(0); id < total; id++ {
+		if _, isInner := st.getLeafIndex(id); isInner == 0 {
+			idx.leaves[idx.first[id]] = id
+		}
+	}
+
+	st.rangeIndex = idx
+}
+
+// LeafOrdinalRange returns the key ordinals of the first and the last leaf in
+// the subtree of a node.
+// A key ordinal is the index of a key among all keys in ascending order, the
+// same as the leafOrdinal passed to Opt.ValueFunc.
+// It returns -1, -1 if nodeID is out of range, or an invalid child id is found
+// in corrupted data.
+//
+// Node ids are the same as the ones returned by GetID() and PartialGetID().
+//
+// Since 0.5.12
+func (st *SlimTrie) LeafOrdinalRange(nodeID int32) (int32, int32) {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return -1, -1
+	}
+
+	if nodeID < 0 || nodeID >= st.levels[len(st.levels)-1].total {
+		return -1, -1
+	}
+
+	if st.rangeIndex != nil {
+		return st.rangeIndex.first[nodeID], st.rangeIndex.last[nodeID]
+	}
+
+	l, r := st.leftMost(nodeID, nil), st.rightMost(nodeID, nil)
+	if l == -1 || r == -1 {
+		return -1, -1
+	}
+	return st.keyOrdinal(l), st.keyOrdinal(r)
+}
+
+// SubtreeValues returns the values of all keys with the specified prefix, in
+// key order.
+// Keys removed by Opt.DedupValue when creating are not counted.
+//
+// If SlimTrie does not store values, the returned values are all nil.
+//
+// SubtreeValues requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (st *SlimTrie) SubtreeValues(prefix string) []interface{} {
+
+	rst := make([]interface{}, 0)
+
+	path, _ := st.getGEPath(prefix)
+	if len(path) == 0 {
+		return rst
+	}
+
+	from := st.keyOrdinal(path[len(path)-1])
+	n := st.ordinalOf(prefixEnd(prefix)) - from
+
+	if st.rangeIndex != nil {
+		for _, id := range st.rangeIndex.leaves[from : from+n] {
+			rst = append(rst, st.getLeaf(id))
+		}
+		return rst
+	}
+
+	c := &cursor{st: st, path: path}
+	for i := int32(0); i < n; i++ {
+		rst = append(rst, st.getLeaf(c.leaf()))
+		c.next()
+	}
+	return rst
+}
+
+// PrefixCount returns the number of keys with the specified prefix.
+// Keys removed by Opt.DedupValue when creating are not counted.
+//
+// PrefixCount requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (st *SlimTrie) PrefixCount(prefix string) int {
+	return int(st.ordinalOf(prefixEnd(prefix)) - st.ordinalOf(&prefix))
+}
+
+// RangeCount returns the number of keys in the range [from, to).
+// Keys removed by Opt.DedupValue when creating are not counted.
+//
+// RangeCount requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (st *SlimTrie) RangeCount(from, to string) int {
+	if to <= from {
+		return 0
+	}
+	return int(st.ordinalOf(&to) - st.ordinalOf(&from))
+}
+
+// CountRange returns the number of keys in the range [start, end], the keys
+// ScanRange(start, end) iterates, without iterating them.
+// Both start and end are inclusive.
+// An empty end counts to the greatest key.
+// Keys removed by Opt.DedupValue when creating are not counted.
+//
+// It costs two descents, or less with BuildRangeIndex().
+//
+// CountRange requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+//
+// Since 0.5.12
+func (st *SlimTrie) CountRange(start, end string) int {
+
+	if end == "" {
+		return int(st.leafCount() - st.ordinalOf(&start))
+	}
+
+	if end < start {
+		return 0
+	}
+
+	// the smallest string greater than end
+	after := end + "\x00"
+	return int(st.ordinalOf(&after) - st.ordinalOf(&start))
+}
+
+// ordinalOf returns the number of keys less than key.
+// A nil key is greater than any key.
+//
+// Since 0.5.12
+func (st *SlimTrie) ordinalOf(key *string) int32 {
+
+	if key == nil {
+		return st.leafCount()
+	}
+
+	path, _ := st.getGEPath(*key)
+	if len(path) == 0 {
+		return st.leafCount()
+	}
+	return st.keyOrdinal(path[len(path)-1])
+}
+
+// prefixEnd returns the smallest string greater than all strings with the
+// specified prefix, or nil if there is no such string, e.g., the prefix is
+// empty or consists of only 0xff.
+//
+// Since 0.5.12
+func prefixEnd(prefix string) *string {
+	bs := []byte(prefix)
+	for i := len(bs) - 1; i >= 0; i-- {
+		if bs[i] < 0xff {
+			bs[i]++
+			end := string(bs[:i+1])
+			return &end
+		}
+	}
+	return nil
+}
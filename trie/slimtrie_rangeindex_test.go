@@ -0,0 +1,192 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_RangeIndex(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"a",
+		"ab",
+		"abc",
+		"abcd",
+		"abd",
+		"ac",
+		"b",
+		"bc",
+		"\xff",
+		"\xff\xff",
+	}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	prefixCases := []struct {
+		prefix string
+		want   []interface{}
+	}{
+		{"", []interface{}{int32(0), int32(1), int32(2), int32(3), int32(4), int32(5), int32(6), int32(7), int32(8), int32(9)}},
+		{"a", []interface{}{int32(0), int32(1), int32(2), int32(3), int32(4), int32(5)}},
+		{"ab", []interface{}{int32(1), int32(2), int32(3), int32(4)}},
+		{"abc", []interface{}{int32(2), int32(3)}},
+		{"abcd", []interface{}{int32(3)}},
+		{"abcde", []interface{}{}},
+		{"aa", []interface{}{}},
+		{"b", []interface{}{int32(6), int32(7)}},
+		{"c", []interface{}{}},
+		{"\xff", []interface{}{int32(8), int32(9)}},
+		{"\xff\xff", []interface{}{int32(9)}},
+	}
+
+	rangeCases := []struct {
+		from, to string
+		want     int
+	}{
+		{"", "\xff\xff\xff", 10},
+		{"a", "b", 6},
+		{"a", "a", 0},
+		{"b", "a", 0},
+		{"ab", "abd", 3},
+		{"abcc", "ac", 2},
+		{"abcc", "acc", 3},
+		{"c", "\xff", 0},
+		{"c", "\xff\x00", 1},
+	}
+
+	countCases := []struct {
+		start, end string
+		want       int
+	}{
+		{"", "", 10},
+		{"a", "", 10},
+		{"b", "", 4},
+		{"a", "b", 7},
+		{"a", "a", 1},
+		{"aa", "aa", 0},
+		{"b", "a", 0},
+		{"ab", "abd", 4},
+		{"abcc", "ac", 3},
+		{"c", "\xff", 1},
+		{"\xff\xff", "\xff\xff\xff", 1},
+	}
+
+	test := func() {
+		for i, c := range prefixCases {
+			ta.Equal(c.want, st.SubtreeValues(c.prefix), "%d-th: case: %+v", i+1, c)
+			ta.Equal(len(c.want), st.PrefixCount(c.prefix), "%d-th: case: %+v", i+1, c)
+		}
+		for i, c := range rangeCases {
+			ta.Equal(c.want, st.RangeCount(c.from, c.to), "%d-th: case: %+v", i+1, c)
+		}
+		for i, c := range countCases {
+			ta.Equal(c.want, st.CountRange(c.start, c.end), "%d-th: case: %+v", i+1, c)
+		}
+
+		first, last := st.LeafOrdinalRange(0)
+		ta.Equal(int32(0), first)
+		ta.Equal(int32(len(keys)-1), last)
+
+		first, last = st.LeafOrdinalRange(-1)
+		ta.Equal(int32(-1), first)
+		ta.Equal(int32(-1), last)
+	}
+
+	t.Run("without-index", func(t *testing.T) {
+		test()
+	})
+
+	t.Run("with-index", func(t *testing.T) {
+		st.BuildRangeIndex()
+		ta.NotNil(st.rangeIndex)
+		test()
+	})
+
+	t.Run("dropped-by-unmarshal", func(t *testing.T) {
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		ta.NoError(st.Unmarshal(buf))
+		ta.Nil(st.rangeIndex)
+		test()
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		st.BuildRangeIndex()
+		ta.Equal([]interface{}{}, st.SubtreeValues(""))
+		ta.Equal(0, st.PrefixCount(""))
+		ta.Equal(0, st.RangeCount("", "a"))
+	})
+}
+
+func TestSlimTrie_RangeIndex_random(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 20; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		st, kvs := RandomTrie(rng, 200, 5)
+
+		keys := make([]string, 0, len(kvs))
+		for k := range kvs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		withIndex := st.ShallowClone()
+		withIndex.BuildRangeIndex()
+
+		for id := int32(0); id < st.levels[len(st.levels)-1].total; id++ {
+			f0, l0 := st.LeafOrdinalRange(id)
+			f1, l1 := withIndex.LeafOrdinalRange(id)
+			ta.Equal(f0, f1, "seed: %d, node: %d", seed, id)
+			ta.Equal(l0, l1, "seed: %d, node: %d", seed, id)
+		}
+
+		for i := 0; i < 50; i++ {
+			prefix := randKey(rng, 2)
+			to := randKey(rng, 3)
+
+			var want []interface{}
+			cnt := 0
+			inclusive := 0
+			for _, k := range keys {
+				if strings.HasPrefix(k, prefix) {
+					want = append(want, kvs[k])
+				}
+				if k >= prefix && k < to {
+					cnt++
+				}
+				if k >= prefix && k <= to {
+					inclusive++
+				}
+			}
+
+			for _, s := range []*SlimTrie{st, withIndex} {
+				got := s.SubtreeValues(prefix)
+				ta.Equal(len(want), len(got), "seed: %d, prefix: %q", seed, prefix)
+				if len(want) > 0 {
+					ta.Equal(want, got, "seed: %d, prefix: %q", seed, prefix)
+				}
+				ta.Equal(len(want), s.PrefixCount(prefix), "seed: %d, prefix: %q", seed, prefix)
+				ta.Equal(cnt, s.RangeCount(prefix, to), "seed: %d, range: %q %q", seed, prefix, to)
+				if to != "" {
+					ta.Equal(inclusive, s.CountRange(prefix, to), "seed: %d, range: %q %q", seed, prefix, to)
+				}
+				ta.Equal(len(keys)-sort.SearchStrings(keys, to), s.CountRange(to, ""), "seed: %d, range: %q", seed, to)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package trie
+
+// Resolution is the result of ResolveInto().
+// It is meant to be reused by a caller to resolve many keys without
+// allocation.
+//
+// Since 0.5.12
+type Resolution struct {
+	// NodeID is the id of the leaf node the key resolves to, the same as
+	// GetID() returns.
+	// It is -1 if the key is not found.
+	NodeID int32
+
+	// LeafOrdinal is the index of the leaf among all leaves, by which the
+	// value is stored.
+	// It is -1 if the key is not found.
+	LeafOrdinal int32
+
+	// Exact is true if the complete key is compared, i.e., the key is
+	// absolutely a stored key.
+	// It is false if only the stored prefixes of the key are compared, in
+	// which case the key might be a false positive.
+	Exact bool
+
+	// qr is reused by every ResolveInto().
+	qr querySession
+}
+
+// ResolveInto looks up key and fills in res with the leaf node id, the leaf
+// ordinal and the match kind, in one call and without allocation.
+//
+// The result is the same as GetID() and the leaf index of the id.
+// res must not be shared between goroutines.
+//
+// Since 0.5.12
+func (st *SlimTrie) ResolveInto(res *Resolution, key string) {
+
+	res.NodeID = -1
+	res.LeafOrdinal = -1
+	res.Exact = false
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return
+	}
+
+	id := st.getID(key, &res.qr)
+	if id == -1 {
+		return
+	}
+
+	res.NodeID = id
+	res.LeafOrdinal, _ = st.getLeafIndex(id)
+	res.Exact = st.hasCompleteKeys()
+}
@@ -0,0 +1,52 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_ResolveInto(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, complete := range []bool{true, false} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(complete)})
+		ta.NoError(err)
+
+		res := &Resolution{}
+		for i, k := range keys {
+			st.ResolveInto(res, k)
+
+			ta.Equal(st.GetID(k), res.NodeID)
+			ta.Equal(complete, res.Exact)
+			ta.Equal(values[i], st.getIthLeaf(res.LeafOrdinal))
+		}
+
+		st.ResolveInto(res, "\xff\xff")
+		ta.Equal(int32(-1), res.NodeID)
+		ta.Equal(int32(-1), res.LeafOrdinal)
+		ta.False(res.Exact)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			st.ResolveInto(res, keys[100])
+		})
+		ta.Equal(float64(0), allocs)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+
+		res := &Resolution{NodeID: 3, LeafOrdinal: 3, Exact: true}
+		st.ResolveInto(res, "a")
+		ta.Equal(int32(-1), res.NodeID)
+		ta.Equal(int32(-1), res.LeafOrdinal)
+		ta.False(res.Exact)
+	})
+}
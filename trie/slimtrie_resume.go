@@ -0,0 +1,105 @@
+package trie
+
+// PartialGetID descends along a prefix of a key as deep as possible and
+// returns the id of the deepest node reached, and the number of bytes of the
+// prefix consumed.
+// The returned node starts right at byte `consumed` of a key, thus a query of
+// any key with this prefix can be resumed with
+// ResumeGet(nodeID, key[consumed:]).
+//
+// It returns -1 if no key with this prefix is stored in SlimTrie.
+// Without Opt{Complete: Bool(true)}, like Get(), there could be false
+// positives.
+//
+// Since 0.5.12
+func (st *SlimTrie) PartialGetID(keyPrefix string) (nodeID int32, consumed int) {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return -1, 0
+	}
+
+	key := keyPrefix
+	l := int32(8 * len(key))
+
+	qr := &querySession{
+		keyBitLen: l,
+		key:       key,
+	}
+
+	id, i := int32(0), int32(0)
+
+	// the deepest node that starts at a byte boundary.
+	lastID, lastI := id, i
+
+	for {
+
+		if i&7 == 0 {
+			lastID, lastI = id, i
+		}
+
+		st.getNode(id, qr)
+		if qr.isInner == 0 {
+			break
+		}
+
+		if qr.hasInnerPrefix {
+			if i&(^7)+qr.innerPrefixLen > l {
+				// the prefix of this node can not be fully compared.
+				break
+			}
+			r := strCmpUpto(key[i>>3:], qr.innerPrefix)
+			if r != 0 {
+				return -1, 0
+			}
+			i = i&(^7) + qr.innerPrefixLen
+		} else {
+			if i+qr.innerPrefixLen > l {
+				break
+			}
+			i += qr.innerPrefixLen
+		}
+
+		if i+qr.wordSize > l {
+			// the label to choose a branch is not in keyPrefix.
+			break
+		}
+
+		lchID, has := st.getLeftChildID(qr, i)
+		if has == 0 {
+			return -1, 0
+		}
+		id = lchID + 1
+		i += qr.wordSize
+	}
+
+	return lastID, int(lastI >> 3)
+}
+
+// ResumeGet resumes a query from a node returned by PartialGetID(), with the
+// rest of the key.
+// For a key and `nodeID, consumed := PartialGetID(key[:n])`,
+// `ResumeGet(nodeID, key[consumed:])` returns the same as `Get(key)`.
+//
+// nodeID must be one returned by PartialGetID(), and remainingKey must start
+// at the byte where the node starts.
+// Otherwise the result is undefined.
+// An out of range nodeID returns false.
+//
+// Since 0.5.12
+func (st *SlimTrie) ResumeGet(nodeID int32, remainingKey string) (interface{}, bool) {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return nil, false
+	}
+
+	if nodeID < 0 || nodeID >= st.levels[len(st.levels)-1].total {
+		return nil, false
+	}
+
+	eqID := st.getIDFrom(nodeID, st.terminate(remainingKey), &querySession{})
+	if eqID == -1 {
+		return nil, false
+	}
+
+	return st.lookupLeaf(eqID)
+}
@@ -0,0 +1,67 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_ResumeGet(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 10; seed++ {
+
+		st, kvs := RandomTrie(rand.New(rand.NewSource(seed)), 300, 8)
+
+		rng := rand.New(rand.NewSource(seed + 1000))
+		qs := make([]string, 0, len(kvs)+300)
+		for k := range kvs {
+			qs = append(qs, k)
+		}
+		for i := 0; i < 300; i++ {
+			qs = append(qs, randKey(rng, 9))
+		}
+
+		for _, q := range qs {
+
+			want, wantFound := st.Get(q)
+
+			for n := 0; n <= len(q); n++ {
+
+				nodeID, consumed := st.PartialGetID(q[:n])
+				if nodeID == -1 {
+					ta.False(wantFound, "key: %q, prefix len: %d", q, n)
+					continue
+				}
+
+				ta.True(consumed <= n)
+
+				got, found := st.ResumeGet(nodeID, q[consumed:])
+				ta.Equal(wantFound, found, "key: %q, prefix len: %d", q, n)
+				ta.Equal(want, got, "key: %q, prefix len: %d", q, n)
+			}
+		}
+	}
+
+	t.Run("invalidNodeID", func(t *testing.T) {
+		st, _ := RandomTrie(rand.New(rand.NewSource(0)), 10, 8)
+
+		_, found := st.ResumeGet(-1, "a")
+		ta.False(found)
+		_, found = st.ResumeGet(1<<20, "a")
+		ta.False(found)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, _ := RandomTrie(rand.New(rand.NewSource(0)), 0, 8)
+
+		nodeID, consumed := st.PartialGetID("a")
+		ta.Equal(int32(-1), nodeID)
+		ta.Equal(0, consumed)
+
+		_, found := st.ResumeGet(0, "a")
+		ta.False(found)
+	})
+}
@@ -1,9 +1,11 @@
 package trie
 
 import (
+	"math/rand"
 	"sort"
 	"testing"
 
+	"github.com/openacid/errors"
 	"github.com/openacid/slim/encode"
 	"github.com/openacid/testutil"
 	"github.com/stretchr/testify/require"
@@ -397,3 +399,51 @@ func subTestScan(
 		}
 	})
 }
+
+func TestSlimTrie_IterKV(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 10; seed++ {
+
+		st, kvs := RandomTrie(rand.New(rand.NewSource(seed)), 300, 8)
+
+		keys := make([]string, 0, len(kvs))
+		for k := range kvs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		gotKeys := make([]string, 0, len(kvs))
+		err := st.IterKV(func(key string, val interface{}) bool {
+			ta.Equal(kvs[key], val, "key: %q", key)
+			gotKeys = append(gotKeys, key)
+			return true
+		})
+		ta.NoError(err)
+		ta.Equal(keys, gotKeys, "seed: %d", seed)
+
+		if len(keys) > 2 {
+			n := 0
+			err = st.IterKV(func(key string, val interface{}) bool {
+				n++
+				return n < 2
+			})
+			ta.NoError(err)
+			ta.Equal(2, n)
+		}
+	}
+
+	t.Run("incomplete", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.Int{}, marshalCase.keys, marshalCase.values)
+		ta.NoError(err)
+
+		called := false
+		err = st.IterKV(func(key string, val interface{}) bool {
+			called = true
+			return true
+		})
+		ta.Equal(ErrIncomplete, errors.Cause(err))
+		ta.False(called)
+	})
+}
@@ -0,0 +1,54 @@
+package trie
+
+import (
+	"bytes"
+
+	"github.com/openacid/errors"
+)
+
+// selfCheck queries every key that is stored in SlimTrie and ensures it is
+// found and points to the right value.
+// keys, bytesValues and opt are the same as those used to create it.
+//
+// A key removed by DedupValue is not stored and is not checked.
+//
+// Since 0.5.12
+func (st *SlimTrie) selfCheck(keys []string, bytesValues [][]byte, opt *Opt) error {
+
+	n := len(keys)
+	if n == 0 {
+		return nil
+	}
+
+	tokeep := newToKeep(n, bytesValues, opt)
+	ls := st.inner.Leaves
+
+	qr := &querySession{}
+	for i, k := range keys {
+		if !tokeep[i] {
+			continue
+		}
+
+		id := st.getID(k, qr)
+		if id == -1 {
+			return errors.Wrapf(ErrSelfCheck, "keys[%d] %q not found", i, k)
+		}
+
+		if ls == nil {
+			continue
+		}
+
+		leafI, nodeType := st.getLeafIndex(id)
+		if nodeType == 1 {
+			return errors.Wrapf(ErrSelfCheck, "keys[%d] %q found inner node %d", i, k, id)
+		}
+
+		got := ls.get(leafI)
+		if !bytes.Equal(got, bytesValues[i]) {
+			return errors.Wrapf(ErrSelfCheck, "keys[%d] %q value mismatch: expect %v got %v",
+				i, k, bytesValues[i], got)
+		}
+	}
+
+	return nil
+}
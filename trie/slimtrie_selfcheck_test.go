@@ -0,0 +1,67 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_SelfCheck(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	opts := []Opt{
+		{SelfCheck: Bool(true)},
+		{SelfCheck: Bool(true), Complete: Bool(true)},
+		{SelfCheck: Bool(true), InnerPrefix: Bool(true)},
+		{SelfCheck: Bool(true), DedupValue: Bool(false)},
+	}
+
+	for _, opt := range opts {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err, "opt: %+v", opt)
+		testPresentKeysGet(t, st, keys, values)
+
+		// filter mode
+		_, err = NewSlimTrie(nil, keys, nil, opt)
+		ta.NoError(err, "opt: %+v", opt)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{SelfCheck: Bool(true)})
+		ta.NoError(err)
+	})
+
+	t.Run("dedup", func(t *testing.T) {
+		vs := []int32{1, 1, 2, 2, 2, 3, 3, 4}
+		_, err := NewSlimTrie(encode.I32{}, marshalCase.keys, vs, Opt{SelfCheck: Bool(true)})
+		ta.NoError(err)
+	})
+
+	t.Run("absentKey", func(t *testing.T) {
+		opt := Opt{Complete: Bool(true)}
+		st, err := NewSlimTrie(encode.Int{}, marshalCase.keys, marshalCase.values, opt)
+		ta.NoError(err)
+
+		ks := []string{"abc", "abcx"}
+		vs := encodeValues(2, []int{0, 1}, encode.Int{})
+		err = st.selfCheck(ks, vs, normalizeOpt(&opt))
+		ta.Equal(ErrSelfCheck, errors.Cause(err))
+	})
+
+	t.Run("wrongValue", func(t *testing.T) {
+		opt := Opt{}
+		st, err := NewSlimTrie(encode.Int{}, marshalCase.keys, marshalCase.values, opt)
+		ta.NoError(err)
+
+		ks := []string{"abc", "abcd"}
+		vs := encodeValues(2, []int{0, 5}, encode.Int{})
+		err = st.selfCheck(ks, vs, normalizeOpt(&opt))
+		ta.Equal(ErrSelfCheck, errors.Cause(err))
+	})
+}
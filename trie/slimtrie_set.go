@@ -0,0 +1,54 @@
+package trie
+
+// Set overwrites the value of an existing key in place and returns true.
+//
+// It returns false if key is not found or is deleted by Delete(), if values
+// are not stored or are computed by Opt.ValueFunc, if leaves are not stored
+// as fixed size elements, e.g., with Opt.LeafBlockSize or Opt.LeafExceptions,
+// if the leaf of key has no value, or if the encoded value is not of the same
+// size as the stored one.
+// E.g., with encode.I32, a counter could be updated without rebuilding.
+// value must be of a type the encoder of SlimTrie accepts.
+//
+// The structure of the trie is not changed and the new value is marshaled
+// along with the trie.
+//
+// Set requires a full slimtrie to work, i.e., created with NewSlimTrie(... Opt{Complete: Bool(true)}).
+// Otherwise a key not stored could be a false positive of a stored key, whose
+// value is then overwritten.
+//
+// Set modifies the storage shared with a shallow clone, and must not be called
+// concurrently with any other method.
+//
+// Since 0.5.12
+func (st *SlimTrie) Set(key string, value interface{}) bool {
+
+	if st.valueFunc != nil || st.encoder == nil {
+		return false
+	}
+
+	ls := st.getLeaves()
+	if ls == nil || ls.PositionBM != nil || ls.BlockSize > 0 || ls.ExceptionBM != nil {
+		return false
+	}
+
+	id := st.GetID(key)
+	if id == -1 {
+		return false
+	}
+
+	bs := st.encoder.Encode(value)
+	if int32(len(bs)) != ls.FixedSize {
+		return false
+	}
+
+	ith, _ := st.getLeafIndex(id)
+	dst := ls.get(ith)
+	if len(dst) != len(bs) {
+		// an absent leaf has no room
+		return false
+	}
+
+	copy(dst, bs)
+	return true
+}
@@ -0,0 +1,115 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Set(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "ab", "abc", "abd", "b", "bc"}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	ta.True(st.Set("ab", int32(100)))
+	ta.True(st.Set("bc", int32(-1)))
+	ta.True(st.Set("bc", int32(200)))
+
+	ta.False(st.Set("x", int32(1)))
+	ta.False(st.Set("abcd", int32(1)))
+
+	want := []int32{0, 100, 2, 3, 4, 200}
+
+	test := func(st *SlimTrie) {
+		for i, k := range keys {
+			v, found := st.Get(k)
+			ta.True(found, "%d-th key %q", i, k)
+			ta.Equal(want[i], v, "%d-th key %q", i, k)
+		}
+
+		i := 0
+		st.ScanFrom("", true, true, func(k, v []byte) bool {
+			_, got := encode.I32{}.Decode(v)
+			ta.Equal(want[i], got, "%d-th key %q", i, k)
+			i++
+			return true
+		})
+		ta.Equal(len(keys), i)
+	}
+
+	test(st)
+
+	t.Run("marshal", func(t *testing.T) {
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+		test(st2)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		st := st.Clone()
+		ta.True(st.Delete("abc"))
+		ta.False(st.Set("abc", int32(1)))
+	})
+
+	t.Run("notFixedSize", func(t *testing.T) {
+		ta := require.New(t)
+
+		vals := []string{"1", "22", "333", "4444", "55555", "666666"}
+		st, err := NewSlimTrie(encode.String16{}, keys, vals, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.False(st.Set("a", "x"))
+
+		for _, opt := range []Opt{
+			{Complete: Bool(true), LeafBlockSize: 4},
+			{Complete: Bool(true), LeafExceptions: Bool(true)},
+		} {
+			st, err = NewSlimTrie(encode.String16{}, keys, vals, opt)
+			ta.NoError(err)
+			ta.False(st.Set("a", "x"), "opt: %+v", opt)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		ta := require.New(t)
+
+		vals := []interface{}{"12", nil, "34", "56", "78", "90"}
+		st, err := NewSlimTrie(encode.String16{}, keys, vals,
+			Opt{Complete: Bool(true), DedupValue: Bool(false)})
+		ta.NoError(err)
+
+		ta.False(st.Set("ab", "xy"))
+		ta.False(st.Set("abc", "xyz"), "size differs")
+		ta.True(st.Set("abc", "xy"))
+
+		v, _ := st.Get("abc")
+		ta.Equal("xy", v)
+		v, found := st.Get("ab")
+		ta.True(found)
+		ta.Nil(v)
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, keys, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.False(st.Set("a", int32(1)))
+
+		st, err = NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		st.SetValueFunc(func(ord int32) interface{} { return ord })
+		ta.False(st.Set("a", int32(1)))
+
+		ta.False((&SlimTrie{}).Set("a", int32(1)))
+	})
+}
@@ -0,0 +1,482 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// SectionID identifies a section in the split layout written by
+// MarshalSplit().
+//
+// Since 0.5.12
+type SectionID int32
+
+const (
+	// SectionMeta contains everything in a SlimTrie except the other sections.
+	SectionMeta SectionID = iota
+	// SectionNodeTypeBM is the bitmap of node types.
+	SectionNodeTypeBM
+	// SectionInners is the bitmap of labels of inner nodes.
+	SectionInners
+	// SectionInnerPrefixes is the array of inner node prefixes.
+	SectionInnerPrefixes
+	// SectionLeafPrefixes is the array of leaf prefixes.
+	SectionLeafPrefixes
+	// SectionLeaves is the array of leaf values.
+	SectionLeaves
+
+	sectionCnt
+)
+
+const (
+	splitMagic      = "slimsplt"
+	splitVersionLen = 16
+
+	// splitHeaderSize is the size of magic, version and section directory.
+	splitHeaderSize = len(splitMagic) + splitVersionLen + 16*int(sectionCnt)
+)
+
+// splitDir is the section directory: the offset and size of every section.
+type splitDir [sectionCnt][2]uint64
+
+// MarshalSplit writes SlimTrie in a split layout, in which every section is
+// independently addressable: a small header with a section directory is
+// written first, followed by every section in the order of SectionID.
+//
+// The header is:
+//
+//     8 bytes magic "slimsplt"
+//     16 bytes version, padded with 0
+//     offset and size of every section, in little endian uint64
+//
+// An offset is from the start of the header.
+// A section of size 0 is absent.
+// Every section is a protobuf message: SectionMeta is a Slim with the other
+// sections removed, SectionLeaves, SectionInnerPrefixes and
+// SectionLeafPrefixes are VLenArray and the others are Bitmap.
+//
+// Since 0.5.12
+func (st *SlimTrie) MarshalSplit(w io.Writer) error {
+
+	ns, err := st.fullInner()
+	if err != nil {
+		return err
+	}
+
+	sections, err := splitSections(ns)
+	if err != nil {
+		return err
+	}
+
+	var dir splitDir
+	offset := uint64(splitHeaderSize)
+	for i, sec := range sections {
+		dir[i] = [2]uint64{offset, uint64(len(sec))}
+		offset += uint64(len(sec))
+	}
+
+	var ver [splitVersionLen]byte
+	copy(ver[:], slimtrieVersion)
+
+	buf := bytes.NewBuffer(make([]byte, 0, splitHeaderSize))
+	buf.WriteString(splitMagic)
+	buf.Write(ver[:])
+	err = binary.Write(buf, binary.LittleEndian, dir)
+	if err != nil {
+		return errors.WithMessage(err, "failed to write section directory")
+	}
+
+	_, err = w.Write(buf.Bytes())
+	if err != nil {
+		return errors.WithMessage(err, "failed to write header")
+	}
+
+	for i, sec := range sections {
+		_, err = w.Write(sec)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to write section %d", i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalSection writes only one section of the split layout to w, the same
+// bytes MarshalSplit() writes for it, e.g., to rewrite SectionLeaves of an
+// existing file after values are updated while the structure is unchanged.
+// An absent section writes nothing.
+//
+// The offset and size of a section in an existing file are in its section
+// directory, see MarshalSplit().
+// Replacing a section in place is safe only if:
+//
+//     the file is written by MarshalSplit() of the same version;
+//     all other sections are unchanged;
+//     the new section is of exactly the size in the section directory.
+//
+// Since the offsets of the other sections are not updated, a section of
+// another size corrupts the file, except for the last present section, which
+// could be rewritten at its offset with its size updated in the directory at
+// byte 24+16*section+8, and the file truncated to the new end.
+// Otherwise rewrite the whole file with MarshalSplit().
+//
+// The size of SectionLeaves is kept if every value is replaced by one of the
+// same encoded size and no value becomes absent or present.
+//
+// Since 0.5.12
+func (st *SlimTrie) MarshalSection(section SectionID, w io.Writer) error {
+
+	if section < 0 || section >= sectionCnt {
+		return errors.Wrapf(ErrInvalidSection, "section: %d", section)
+	}
+
+	ns, err := st.fullInner()
+	if err != nil {
+		return err
+	}
+
+	b, err := marshalSection(ns, section)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to write section %d", section)
+	}
+
+	return nil
+}
+
+// splitSections marshals every section of ns.
+func splitSections(ns *Slim) ([sectionCnt][]byte, error) {
+
+	var sections [sectionCnt][]byte
+
+	for i := range sections {
+		b, err := marshalSection(ns, SectionID(i))
+		if err != nil {
+			return sections, err
+		}
+		sections[i] = b
+	}
+
+	return sections, nil
+}
+
+// marshalSection marshals a section of ns.
+// It returns nil if the section is absent.
+func marshalSection(ns *Slim, id SectionID) ([]byte, error) {
+
+	var m proto.Message
+
+	// A nil pointer in an interface is not nil, add only present sections.
+	switch id {
+	case SectionMeta:
+		meta := *ns
+		meta.NodeTypeBM = nil
+		meta.Inners = nil
+		meta.InnerPrefixes = nil
+		meta.LeafPrefixes = nil
+		meta.Leaves = nil
+		m = &meta
+	case SectionNodeTypeBM:
+		if ns.NodeTypeBM != nil {
+			m = ns.NodeTypeBM
+		}
+	case SectionInners:
+		if ns.Inners != nil {
+			m = ns.Inners
+		}
+	case SectionInnerPrefixes:
+		if ns.InnerPrefixes != nil {
+			m = ns.InnerPrefixes
+		}
+	case SectionLeafPrefixes:
+		if ns.LeafPrefixes != nil {
+			m = ns.LeafPrefixes
+		}
+	case SectionLeaves:
+		if ns.Leaves != nil {
+			m = ns.Leaves
+		}
+	}
+
+	if m == nil {
+		return nil, nil
+	}
+
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to marshal section %d", id)
+	}
+	return b, nil
+}
+
+// OpenSplit loads a SlimTrie written by MarshalSplit() from r.
+//
+// Only the structural sections are loaded when opening.
+// SectionLeaves is loaded from r when a value is accessed for the first time,
+// thus r must be kept readable until then.
+// If loading leaves fails, a query method reports not found.
+// Call LoadLeaves() to load them in advance and check the error.
+//
+// r could be a memory-mapped file, so that only the accessed sections are
+// read into memory.
+//
+// e is the encoder to decode values.
+// If e is nil, the encoder registered with the ValueType the SlimTrie is
+// created with is used.
+//
+// Since 0.5.12
+func OpenSplit(r io.ReaderAt, e encode.Encoder) (*SlimTrie, error) {
+
+	dir, err := readSplitHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := &Slim{}
+	err = readSection(r, dir, SectionMeta, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	bms := []struct {
+		id  SectionID
+		dst **Bitmap
+	}{
+		{SectionNodeTypeBM, &ns.NodeTypeBM},
+		{SectionInners, &ns.Inners},
+	}
+	for _, b := range bms {
+		if dir[b.id][1] == 0 {
+			continue
+		}
+		*b.dst = &Bitmap{}
+		err = readSection(r, dir, b.id, *b.dst)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	arrs := []struct {
+		id  SectionID
+		dst **VLenArray
+	}{
+		{SectionInnerPrefixes, &ns.InnerPrefixes},
+		{SectionLeafPrefixes, &ns.LeafPrefixes},
+	}
+	for _, a := range arrs {
+		if dir[a.id][1] == 0 {
+			continue
+		}
+		*a.dst = &VLenArray{}
+		err = readSection(r, dir, a.id, *a.dst)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rebuildIndexes(ns)
+
+	st := &SlimTrie{
+		inner:   ns,
+		encoder: e,
+	}
+
+	if dir[SectionLeaves][1] > 0 {
+		st.lazyLeaves = &lazyLeaves{
+			load: func() (*VLenArray, error) {
+				ls := &VLenArray{}
+				err := readSection(r, dir, SectionLeaves, ls)
+				if err != nil {
+					return nil, err
+				}
+				rebuildIndexes(&Slim{Leaves: ls})
+				return ls, nil
+			},
+			size: int(dir[SectionLeaves][1]),
+		}
+	}
+
+	if st.encoder == nil && ns.ValueType != "" {
+		st.encoder, _ = encode.Lookup(ns.ValueType)
+	}
+
+	err = st.loadCollation()
+	if err != nil {
+		return nil, err
+	}
+
+	err = st.init()
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// LoadLeaves loads leaves of a SlimTrie opened with OpenSplit() or
+// UnmarshalIndexOnly(), if they are not yet loaded.
+// It does nothing for a SlimTrie created in other ways.
+//
+// A query method does not return an error: if leaves fail to be read, it
+// reports not found. Call LoadLeaves() to find out the error.
+//
+// Since 0.5.12
+func (st *SlimTrie) LoadLeaves() error {
+	if st.lazyLeaves == nil {
+		return nil
+	}
+	_, err := st.lazyLeaves.get()
+	return err
+}
+
+func readSplitHeader(r io.ReaderAt) (*splitDir, error) {
+
+	buf := make([]byte, splitHeaderSize)
+	_, err := r.ReadAt(buf, 0)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read split header")
+	}
+
+	if string(buf[:len(splitMagic)]) != splitMagic {
+		return nil, errors.Wrapf(ErrIncompatible, "not a split layout")
+	}
+
+	ver := buf[len(splitMagic) : len(splitMagic)+splitVersionLen]
+	ver = bytes.TrimRight(ver, "\x00")
+	if string(ver) != slimtrieVersion {
+		return nil, errors.Wrapf(ErrIncompatible,
+			`split layout version: "%s", compatible versions: "==%s"`, ver, slimtrieVersion)
+	}
+
+	dir := &splitDir{}
+	err = binary.Read(bytes.NewReader(buf[len(splitMagic)+splitVersionLen:]), binary.LittleEndian, dir)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read section directory")
+	}
+
+	return dir, nil
+}
+
+func readSection(r io.ReaderAt, dir *splitDir, id SectionID, dst proto.Message) error {
+
+	offset, size := dir[id][0], dir[id][1]
+
+	buf := make([]byte, size)
+	if size > 0 {
+		_, err := r.ReadAt(buf, int64(offset))
+		if err != nil {
+			return errors.WithMessagef(err, "failed to read section %d", id)
+		}
+	}
+
+	err := proto.Unmarshal(buf, dst)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to unmarshal section %d", id)
+	}
+
+	return nil
+}
+
+// lazyLeaves loads Leaves once on demand.
+//
+// Since 0.5.12
+type lazyLeaves struct {
+	once   sync.Once
+	load   func() (*VLenArray, error)
+	leaves *VLenArray
+	err    error
+
+	// at reads only the ith leaf if it is not nil, before all leaves are
+	// loaded.
+	at func(ith int32) ([]byte, error)
+
+	// loaded is set to 1 after load() is called.
+	loaded int32
+
+	// size is the size in byte of the leaves, known without loading them.
+	size int
+}
+
+func (l *lazyLeaves) get() (*VLenArray, error) {
+	l.once.Do(func() {
+		l.leaves, l.err = l.load()
+		atomic.StoreInt32(&l.loaded, 1)
+	})
+	return l.leaves, l.err
+}
+
+// getIth returns the ith leaf and true if it is read without loading all
+// leaves.
+// It returns a non-nil error if reading fails.
+func (l *lazyLeaves) getIth(ith int32) ([]byte, bool, error) {
+	if l.at == nil || atomic.LoadInt32(&l.loaded) == 1 {
+		return nil, false, nil
+	}
+
+	b, err := l.at(ith)
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+// getLeaves returns Leaves, and loads them if they are not yet loaded.
+// It returns nil if loading fails. The error is returned by LoadLeaves().
+//
+// Since 0.5.12
+func (st *SlimTrie) getLeaves() *VLenArray {
+	ls, _ := st.loadLeaves()
+	return ls
+}
+
+// loadLeaves is the same as getLeaves() except that it returns false if
+// loading fails, so that a query reports not found instead of a key without
+// value.
+//
+// Since 0.5.12
+func (st *SlimTrie) loadLeaves() (*VLenArray, bool) {
+	if st.lazyLeaves == nil {
+		return st.inner.GetLeaves(), true
+	}
+
+	ls, err := st.lazyLeaves.get()
+	if err != nil {
+		return nil, false
+	}
+	return ls, true
+}
+
+// hasLeaves returns true if SlimTrie stores leaves, without loading them.
+//
+// Since 0.5.12
+func (st *SlimTrie) hasLeaves() bool {
+	return st.lazyLeaves != nil || st.inner.GetLeaves() != nil
+}
+
+// fullInner returns inner with all sections loaded.
+//
+// Since 0.5.12
+func (st *SlimTrie) fullInner() (*Slim, error) {
+	if st.lazyLeaves == nil {
+		return st.inner, nil
+	}
+
+	ls, err := st.lazyLeaves.get()
+	if err != nil {
+		return nil, err
+	}
+
+	ns := *st.inner
+	ns.Leaves = ls
+	return &ns, nil
+}
@@ -0,0 +1,188 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+// recordReaderAt records the offset of every read.
+type recordReaderAt struct {
+	r       *bytes.Reader
+	offsets []int64
+}
+
+func (r *recordReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.offsets = append(r.offsets, off)
+	return r.r.ReadAt(p, off)
+}
+
+func TestSlimTrie_MarshalSplit(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	encode.Register("test.split.i32", encode.I32{})
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values,
+		Opt{Complete: Bool(true), ValueType: "test.split.i32"})
+	ta.NoError(err)
+
+	buf := &bytes.Buffer{}
+	err = st.MarshalSplit(buf)
+	ta.NoError(err)
+
+	t.Run("lazyLeaves", func(t *testing.T) {
+		r := &recordReaderAt{r: bytes.NewReader(buf.Bytes())}
+
+		st2, err := OpenSplit(r, nil)
+		ta.NoError(err)
+
+		dir, err := readSplitHeader(r)
+		ta.NoError(err)
+		leavesOffset := int64(dir[SectionLeaves][0])
+
+		ta.NotContains(r.offsets, leavesOffset, "leaves are not loaded")
+
+		ta.NotEqual(int32(-1), st2.GetID(keys[0]))
+		ta.NotContains(r.offsets, leavesOffset, "GetID does not load leaves")
+
+		testPresentKeysGet(t, st2, keys, values)
+		ta.Contains(r.offsets, leavesOffset)
+
+		testPresentKeysGet(t, st2.Clone(), keys, values)
+		testPresentKeysGet(t, st2.ShallowClone(), keys, values)
+	})
+
+	t.Run("LoadLeaves", func(t *testing.T) {
+		st2, err := OpenSplit(bytes.NewReader(buf.Bytes()), encode.I32{})
+		ta.NoError(err)
+		ta.NoError(st2.LoadLeaves())
+
+		b1, err := st.Marshal()
+		ta.NoError(err)
+		b2, err := st2.Marshal()
+		ta.NoError(err)
+		ta.Equal(b1, b2)
+	})
+
+	t.Run("brokenLeaves", func(t *testing.T) {
+		b := buf.Bytes()
+		st2, err := OpenSplit(bytes.NewReader(b[:len(b)-1]), encode.I32{})
+		ta.NoError(err)
+
+		ta.Error(st2.LoadLeaves())
+		ta.NotPanics(func() {
+			_, found := st2.Get(keys[0])
+			ta.False(found)
+			_, found = st2.GetI32(keys[0])
+			ta.False(found)
+			var v int32
+			ta.False(st2.GetInto(keys[0], &v))
+		})
+		ta.Error(st2.IterKV(func(string, interface{}) bool { return true }))
+
+		_, err = st2.Marshal()
+		ta.Error(err)
+	})
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, keys, nil)
+		ta.NoError(err)
+
+		b := &bytes.Buffer{}
+		ta.NoError(st.MarshalSplit(b))
+
+		st2, err := OpenSplit(bytes.NewReader(b.Bytes()), encode.I32{})
+		ta.NoError(err)
+		ta.Nil(st2.lazyLeaves)
+		for _, k := range keys {
+			ta.NotEqual(int32(-1), st2.GetID(k))
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+
+		b := &bytes.Buffer{}
+		ta.NoError(st.MarshalSplit(b))
+
+		st2, err := OpenSplit(bytes.NewReader(b.Bytes()), encode.I32{})
+		ta.NoError(err)
+		_, found := st2.Get("a")
+		ta.False(found)
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		b := append([]byte{}, buf.Bytes()...)
+		b[len(splitMagic)] = '9'
+		_, err := OpenSplit(bytes.NewReader(b), encode.I32{})
+		ta.Equal(ErrIncompatible, errors.Cause(err))
+
+		b, err = st.Marshal()
+		ta.NoError(err)
+		_, err = OpenSplit(bytes.NewReader(b), encode.I32{})
+		ta.Equal(ErrIncompatible, errors.Cause(err))
+	})
+}
+
+func TestSlimTrie_MarshalSection(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{DedupValue: Bool(false)})
+	ta.NoError(err)
+
+	buf := &bytes.Buffer{}
+	ta.NoError(st.MarshalSplit(buf))
+
+	dir, err := readSplitHeader(bytes.NewReader(buf.Bytes()))
+	ta.NoError(err)
+
+	// every section is the same as the one in the split layout.
+	for i := SectionMeta; i < sectionCnt; i++ {
+		sec := &bytes.Buffer{}
+		ta.NoError(st.MarshalSection(i, sec))
+
+		offset, size := dir[i][0], dir[i][1]
+		ta.Equal(string(buf.Bytes()[offset:offset+size]), sec.String(), "section: %d", i)
+	}
+
+	t.Run("inPlace", func(t *testing.T) {
+		newValues := make([]int32, len(values))
+		for i := range newValues {
+			newValues[i] = values[i] * 3
+		}
+		st2, err := NewSlimTrie(encode.I32{}, keys, newValues, Opt{DedupValue: Bool(false)})
+		ta.NoError(err)
+
+		sec := &bytes.Buffer{}
+		ta.NoError(st2.MarshalSection(SectionLeaves, sec))
+
+		offset, size := dir[SectionLeaves][0], dir[SectionLeaves][1]
+		ta.Equal(int(size), sec.Len())
+
+		b := append([]byte{}, buf.Bytes()...)
+		copy(b[offset:], sec.Bytes())
+
+		st3, err := OpenSplit(bytes.NewReader(b), encode.I32{})
+		ta.NoError(err)
+		testPresentKeysGet(t, st3, keys, newValues)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, id := range []SectionID{-1, sectionCnt} {
+			err := st.MarshalSection(id, &bytes.Buffer{})
+			ta.Equal(ErrInvalidSection, errors.Cause(err))
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package trie
+
+import (
+	"github.com/openacid/errors"
+)
+
+// Split returns two new SlimTries: left with keys < pivot and right with keys
+// >= pivot, both built with the options and the encoder of st, e.g., to
+// shard an index by key range.
+// st is not changed.
+// Merge(left, right) has the same keys and values as st.
+// With Opt.KeyNormalizer, pivot is normalized the same way as keys.
+//
+// Keys are rebuilt from the trie, which requires a SlimTrie created with
+// Opt{Complete: Bool(true)}, otherwise it returns an ErrIncomplete error.
+// Keys removed by Opt.DedupValue when creating and keys deleted by Delete()
+// are lost, as WithBatch() does.
+//
+// Since 0.5.12
+func (st *SlimTrie) Split(pivot string) (left, right *SlimTrie, err error) {
+
+	n, c := splitNormalizer(st.collation)
+	if c != nil {
+		return nil, nil, errors.Wrapf(ErrCollation, "original keys can not be rebuilt from sort keys")
+	}
+
+	if n != nil {
+		// keys rebuilt from st are normalized.
+		pivot = n.Normalize(pivot)
+	}
+
+	hasValue := st.getLeaves() != nil
+
+	var keys [2][]string
+	var values [2][]interface{}
+
+	err = st.IterKV(func(key string, val interface{}) bool {
+
+		if st.inner.DeletedBM != nil && st.GetID(key) == -1 {
+			// deleted by Delete()
+			return true
+		}
+
+		side := 0
+		if key >= pivot {
+			side = 1
+		}
+
+		keys[side] = append(keys[side], key)
+		values[side] = append(values[side], val)
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var halves [2]*SlimTrie
+	for i := range halves {
+
+		var vals interface{} = values[i]
+		if !hasValue {
+			vals = nil
+		}
+
+		halves[i], err = st.rebuild(keys[i], vals)
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err, "failed to build split SlimTrie %d", i)
+		}
+	}
+
+	return halves[0], halves[1], nil
+}
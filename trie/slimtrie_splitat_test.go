@@ -0,0 +1,99 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Split(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values,
+		Opt{Complete: Bool(true), DedupValue: Bool(false)})
+	ta.NoError(err)
+
+	for _, pivot := range []string{
+		"",
+		keys[1],
+		keys[len(keys)/2],
+		keys[len(keys)/2] + "\x00",
+		keys[len(keys)-1],
+		"\xff",
+	} {
+		left, right, err := st.Split(pivot)
+		ta.NoError(err)
+		ta.Equal(st.BuildOptions(), left.BuildOptions())
+		ta.Equal(st.BuildOptions(), right.BuildOptions())
+
+		lkeys, lvals := collectIter(left.Iterate())
+		rkeys, rvals := collectIter(right.Iterate())
+		ta.Equal(len(keys), len(lkeys)+len(rkeys), "pivot: %q", pivot)
+
+		for i, k := range lkeys {
+			ta.True(k < pivot, "pivot: %q key: %q", pivot, k)
+			v, _ := st.Get(k)
+			ta.Equal(v, lvals[i])
+		}
+		for i, k := range rkeys {
+			ta.True(k >= pivot, "pivot: %q key: %q", pivot, k)
+			v, _ := st.Get(k)
+			ta.Equal(v, rvals[i])
+		}
+
+		m, err := Merge(left, right)
+		ta.NoError(err)
+		ta.True(st.Equal(m), "pivot: %q", pivot)
+	}
+
+	t.Run("noValue", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(nil, []string{"a", "b", "c"}, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		left, right, err := st.Split("b")
+		ta.NoError(err)
+		ta.Equal(1, left.Len())
+		ta.Equal(2, right.Len())
+		ta.True(right.Has("c"))
+		ta.False(right.Has("a"))
+	})
+
+	t.Run("normalizer", func(t *testing.T) {
+		ta := require.New(t)
+
+		norm := lowerNormalizer{"test.lower.v1"}
+		st, err := NewSlimTrie(encode.I32{}, []string{"A", "b", "C"}, []int32{1, 2, 3},
+			Opt{Complete: Bool(true), KeyNormalizer: norm})
+		ta.NoError(err)
+
+		left, right, err := st.Split("B")
+		ta.NoError(err)
+		ta.Equal(1, left.Len())
+		ta.Equal(2, right.Len())
+		ta.True(left.Has("A"))
+		ta.True(right.Has("B"))
+		ta.True(right.Has("c"))
+
+		m, err := Merge(left, right)
+		ta.NoError(err)
+		ta.True(st.Equal(m))
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, []string{"a", "b"}, []int32{1, 2})
+		ta.NoError(err)
+
+		_, _, err = st.Split("b")
+		ta.Equal(ErrIncomplete, errors.Cause(err))
+	})
+}
@@ -1,5 +1,12 @@
 package trie
 
+import (
+	"math/bits"
+	"sync/atomic"
+
+	"github.com/openacid/low/size"
+)
+
 type Stat struct {
 	LevelCnt int32
 	Levels   []struct {
@@ -51,3 +58,210 @@ func (st *SlimTrie) Stat() *Stat {
 
 	return rst
 }
+
+// Stats describes the shape of a SlimTrie and how many bits of a key it checks.
+// It is returned by SlimTrie.Stats().
+//
+// Field names and json tags are stable, so that a Stats could be recorded to
+// track the quality of an index over time.
+//
+// Since 0.5.12
+type Stats struct {
+	// LeafCnt is the number of leaves, the same as Len().
+	LeafCnt int32 `json:"leaf_cnt"`
+
+	// InnerCnt is the number of inner nodes.
+	InnerCnt int32 `json:"inner_cnt"`
+
+	// ShortCnt is the number of inner nodes stored as a short bitmap.
+	ShortCnt int32 `json:"short_cnt"`
+
+	// BigInnerCnt is the number of inner nodes with 8-bit labels.
+	BigInnerCnt int32 `json:"big_inner_cnt"`
+
+	// InnerPrefixBytes is the size of stored inner node prefixes.
+	// It is 0 if only the lengths of prefixes are stored, i.e., without
+	// Opt.InnerPrefix.
+	InnerPrefixBytes int32 `json:"inner_prefix_bytes"`
+
+	// LeafPrefixBytes is the size of stored leaf prefixes.
+	LeafPrefixBytes int32 `json:"leaf_prefix_bytes"`
+
+	// BitsPerKey is the average number of key bits compared with stored data
+	// by a query reaching a leaf, i.e., the bits of labels and the bits of
+	// stored prefixes along the path to a leaf.
+	BitsPerKey float64 `json:"bits_per_key"`
+}
+
+// Stats returns a Stats describing the shape of SlimTrie and an estimate of how
+// well it discriminates keys.
+//
+// A key not stored is found by Get() only if it matches all the bits checked
+// on the path to a leaf, i.e., the more bits are checked, the less likely a
+// false positive is.
+// With Opt{Complete: Bool(true)} every bit of a key is checked and there is no
+// false positive.
+//
+// It walks all nodes once and costs O(n) time and space.
+//
+// Since 0.5.12
+func (st *SlimTrie) Stats() Stats {
+
+	ns := st.inner
+
+	rst := Stats{
+		LeafCnt:     st.leafCount(),
+		BigInnerCnt: ns.GetBigInnerCnt(),
+	}
+
+	if ns.GetNodeTypeBM() == nil {
+		return rst
+	}
+
+	rst.InnerCnt = st.levels[len(st.levels)-1].inner
+
+	for _, w := range ns.ShortBM.GetWords() {
+		rst.ShortCnt += int32(bits.OnesCount64(w))
+	}
+
+	if ns.InnerPrefixes.GetPositionBM() != nil {
+		rst.InnerPrefixBytes = int32(len(ns.InnerPrefixes.Bytes))
+	}
+	if ns.LeafPrefixes != nil {
+		rst.LeafPrefixBytes = int32(len(ns.LeafPrefixes.Bytes))
+	}
+
+	total := st.levels[len(st.levels)-1].total
+
+	// keyBit is the position in a key where a node starts.
+	// checked is the number of bits checked before reaching a node.
+	// Node ids are in breadth-first order, thus a parent is always visited
+	// before its children.
+	keyBit := make([]int32, total)
+	checked := make([]int64, total)
+
+	var leafBits int64
+	qr := &querySession{}
+
+	for id := int32(0); id < total; id++ {
+
+		st.getNode(id, qr)
+
+		if qr.isInner == 0 {
+			leafBits += checked[id]
+			if qr.hasLeafPrefix {
+				leafBits += int64(len(qr.leafPrefix)) * 8
+			}
+			continue
+		}
+
+		i := keyBit[id]
+		c := checked[id]
+
+		if qr.hasInnerPrefix {
+			end := i&(^7) + qr.innerPrefixLen
+			c += int64(end - i)
+			i = end
+		} else {
+			i += qr.innerPrefixLen
+		}
+
+		c += int64(qr.wordSize)
+		i += qr.wordSize
+
+		first, last := st.childRange(id)
+		for ch := first; ch <= last; ch++ {
+			keyBit[ch] = i
+			checked[ch] = c
+		}
+	}
+
+	if rst.LeafCnt > 0 {
+		rst.BitsPerKey = float64(leafBits) / float64(rst.LeafCnt)
+	}
+
+	return rst
+}
+
+// MappedBytes returns the size in byte of the data loaded from marshaled bytes,
+// i.e., the bitmaps, their indexes and the arrays of prefixes and leaves,
+// including the ones of the folded index built with Opt.WithFoldedIndex.
+//
+// These data are read-only after loading.
+// When the marshaled data is provided by a shared memory mapping, this is the
+// part that can reside in the page cache and be shared among processes.
+//
+// Leaves not yet loaded by a SlimTrie opened with OpenSplit() are loaded.
+//
+// It is also about the size of the marshaled data without the header, to
+// compare SlimTrie with other indexes or Opt with each other.
+// The marshaled data is a little smaller, because the rank and select indexes
+// are encoded as varints.
+//
+// Since 0.5.12
+func (st *SlimTrie) MappedBytes() int {
+	return slimBytes(st.inner, st.getLeaves())
+}
+
+// summaryBytes is the same as MappedBytes() except that it does not load
+// leaves not yet loaded, and counts the size of them known when opening.
+//
+// Since 0.5.12
+func (st *SlimTrie) summaryBytes() int {
+	l := st.lazyLeaves
+	if l == nil || atomic.LoadInt32(&l.loaded) == 1 {
+		return st.MappedBytes()
+	}
+	return slimBytes(st.inner, nil) + l.size
+}
+
+// slimBytes returns the size in byte of the arrays of ns with leaves as its
+// leaves, and of the arrays of the folded index in it.
+//
+// Since 0.5.12
+func slimBytes(ns *Slim, leaves *VLenArray) int {
+	n := bitmapBytes(ns.GetNodeTypeBM()) +
+		bitmapBytes(ns.GetInners()) +
+		bitmapBytes(ns.GetShortBM()) +
+		len(ns.GetShortTable())*4 +
+		vlenArrayBytes(ns.GetInnerPrefixes()) +
+		vlenArrayBytes(ns.GetLeafPrefixes()) +
+		vlenArrayBytes(leaves) +
+		len(ns.GetValueType()) +
+		bitmapBytes(ns.GetDeletedBM())
+
+	if f := ns.GetFolded(); f != nil {
+		n += slimBytes(f, f.GetLeaves())
+	}
+	return n
+}
+
+// HeapBytes returns the size in byte of the data built by a SlimTrie itself
+// after loading, such as the node count of every level.
+//
+// These data are not part of the marshaled bytes and are always allocated on
+// heap by every process.
+//
+// MappedBytes() + HeapBytes() is about the total memory a SlimTrie costs,
+// except a few small fixed size struct headers.
+//
+// Since 0.5.12
+func (st *SlimTrie) HeapBytes() int {
+	return size.Of(st.vars) + size.Of(st.levels)
+}
+
+func bitmapBytes(bm *Bitmap) int {
+	if bm == nil {
+		return 0
+	}
+	return len(bm.Words)*8 + len(bm.RankIndex)*4 + len(bm.SelectIndex)*4
+}
+
+func vlenArrayBytes(va *VLenArray) int {
+	if va == nil {
+		return 0
+	}
+	return bitmapBytes(va.PresenceBM) + bitmapBytes(va.PositionBM) + len(va.Bytes) +
+		len(va.BlockOffsets)*4 +
+		bitmapBytes(va.ExceptionBM) + len(va.ExceptionOffsets)*4
+}
@@ -1,9 +1,13 @@
 package trie
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/kr/pretty"
+	"github.com/openacid/low/pbcmpl"
+	"github.com/openacid/low/size"
 	"github.com/openacid/slim/encode"
 	"github.com/stretchr/testify/require"
 )
@@ -119,3 +123,133 @@ func TestSlimTrie_Stat(t *testing.T) {
 		})
 	}
 }
+
+func TestSlimTrie_Stats(t *testing.T) {
+
+	t.Run("empty", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.Equal(Stats{}, st.Stats())
+	})
+
+	t.Run("twoKeys", func(t *testing.T) {
+		ta := require.New(t)
+
+		// "a" and "b" are 0x61 and 0x62.
+		// The root has a 4-bit prefix and a 4-bit label.
+		keys := []string{"a", "b"}
+		values := makeI32s(len(keys))
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+		ta.Equal(Stats{LeafCnt: 2, InnerCnt: 1, BitsPerKey: 4}, st.Stats())
+
+		st, err = NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		ta.Equal(Stats{LeafCnt: 2, InnerCnt: 1, InnerPrefixBytes: 2, BitsPerKey: 8}, st.Stats())
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		ta := require.New(t)
+
+		keys := getKeys("20kvl10")
+		values := makeI32s(len(keys))
+
+		minimal, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+		complete, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		s0 := minimal.Stats()
+		s1 := complete.Stats()
+
+		ta.Equal(int32(len(keys)), s0.LeafCnt)
+		ta.Equal(int32(0), s0.InnerPrefixBytes)
+		ta.Equal(int32(0), s0.LeafPrefixBytes)
+		ta.True(s1.InnerPrefixBytes > 0)
+		ta.True(s1.LeafPrefixBytes > 0)
+		ta.True(s0.BitsPerKey < s1.BitsPerKey, "minimal: %v, complete: %v", s0, s1)
+
+		// a complete trie checks every bit of a key.
+		bitCnt := 0
+		for _, k := range keys {
+			bitCnt += len(k) * 8
+		}
+		ta.True(s1.BitsPerKey >= float64(bitCnt)/float64(len(keys)))
+
+		buf, err := json.Marshal(s1)
+		ta.NoError(err)
+		var s2 Stats
+		ta.NoError(json.Unmarshal(buf, &s2))
+		ta.Equal(s1, s2)
+	})
+}
+
+func TestSlimTrie_MappedBytes_HeapBytes(t *testing.T) {
+
+	ta := require.New(t)
+
+	st, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.Equal(0, st.MappedBytes())
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err = NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	mapped := st.MappedBytes()
+	heap := st.HeapBytes()
+	ta.True(mapped > 0)
+	ta.True(heap > 0)
+
+	// the rest are struct headers.
+	total := size.Of(st)
+	ta.True(mapped+heap <= total)
+	ta.True(total-mapped-heap < 4096, "total: %d, mapped: %d, heap: %d", total, mapped, heap)
+
+	filter, err := NewSlimTrie(nil, keys, nil, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+	ta.True(mapped-filter.MappedBytes() >= 4*len(keys))
+
+	buf, err := st.Marshal()
+	ta.NoError(err)
+
+	st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	err = st2.Unmarshal(buf)
+	ta.NoError(err)
+
+	ta.Equal(mapped, st2.MappedBytes())
+	ta.Equal(heap, st2.HeapBytes())
+
+	// MappedBytes is about the marshaled size without header.
+	hdrSize, _, err := pbcmpl.ReadHeader(bytes.NewReader(buf))
+	ta.NoError(err)
+	body := len(buf) - int(hdrSize)
+	ta.True(body <= mapped, "marshaled: %d, mapped: %d", body, mapped)
+	ta.True(mapped-body < mapped/50, "marshaled: %d, mapped: %d", body, mapped)
+
+	t.Run("folded", func(t *testing.T) {
+		ta := require.New(t)
+
+		folded, err := NewSlimTrie(encode.I32{}, keys, values,
+			Opt{Complete: Bool(true), WithFoldedIndex: Bool(true)})
+		ta.NoError(err)
+
+		fmapped := folded.MappedBytes()
+		ta.True(fmapped > mapped, "folded: %d, not folded: %d", fmapped, mapped)
+
+		buf, err := folded.Marshal()
+		ta.NoError(err)
+
+		hdrSize, _, err := pbcmpl.ReadHeader(bytes.NewReader(buf))
+		ta.NoError(err)
+		body := len(buf) - int(hdrSize)
+		ta.True(body <= fmapped, "marshaled: %d, mapped: %d", body, fmapped)
+		ta.True(fmapped-body < fmapped/50, "marshaled: %d, mapped: %d", body, fmapped)
+	})
+}
@@ -9,6 +9,28 @@ import (
 	"github.com/openacid/low/tree"
 )
 
+// Summary returns a one line human readable summary of SlimTrie, for logs
+// and test failure messages, e.g.:
+//
+//	SlimTrie v0.5.12: 11 leaves, 8 inner nodes, 138 bytes
+//
+// String() is kept as it is, since it is required by proto.Message and prints
+// the whole structure.
+// Summary() costs O(1) and does not load leaves not yet loaded by a SlimTrie
+// opened with OpenSplit() or UnmarshalIndexOnly(): the size of them is the
+// one known when opening, which is about the same as MappedBytes() counts
+// after loading.
+//
+// Since 0.5.12
+func (st *SlimTrie) Summary() string {
+	var inner int32
+	if len(st.levels) > 0 {
+		inner = st.levels[len(st.levels)-1].inner
+	}
+	return fmt.Sprintf("SlimTrie v%s: %d leaves, %d inner nodes, %d bytes",
+		st.GetVersion(), st.leafCount(), inner, st.summaryBytes())
+}
+
 // String implements proto.Message and output human readable multiline
 // representation.
 //
@@ -30,7 +52,7 @@ import (
 func (st *SlimTrie) String() string {
 
 	// empty SlimTrie
-	if st.inner.NodeTypeBM == nil {
+	if st.inner.GetNodeTypeBM() == nil {
 		return ""
 	}
 
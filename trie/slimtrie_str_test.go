@@ -1,6 +1,8 @@
 package trie
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/openacid/slim/encode"
@@ -76,3 +78,69 @@ func TestSlimTrie_String(t *testing.T) {
 
 	testPresentKeysGet(t, st, keys, values)
 }
+
+func TestSlimTrie_Summary(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"abc",
+		"abcd",
+		"abcdx",
+		"abcdy",
+		"abcdz",
+		"abd",
+		"abde",
+		"bc",
+		"bcd",
+		"bcde",
+		"cde",
+	}
+	values := makeI32s(len(keys))
+	st, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	ta.Equal(int32(8), st.Stats().InnerCnt)
+	want := fmt.Sprintf("SlimTrie v%s: 11 leaves, 8 inner nodes, %d bytes", slimtrieVersion, st.MappedBytes())
+	ta.Equal(want, st.Summary())
+
+	e, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+	ta.NoError(err)
+	ta.Equal("SlimTrie v"+slimtrieVersion+": 0 leaves, 0 inner nodes, 0 bytes", e.Summary())
+	ta.Equal("SlimTrie v"+slimtrieVersion+": 0 leaves, 0 inner nodes, 0 bytes", (&SlimTrie{}).Summary())
+
+	t.Run("lazyLeaves", func(t *testing.T) {
+		ta := require.New(t)
+
+		keys := getKeys("20kvl10")
+		st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)))
+		ta.NoError(err)
+		mapped := st.MappedBytes()
+
+		split := &bytes.Buffer{}
+		ta.NoError(st.MarshalSplit(split))
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		r := &recordReaderAt{r: bytes.NewReader(split.Bytes())}
+		dir, err := readSplitHeader(r)
+		ta.NoError(err)
+		leavesOffset := int64(dir[SectionLeaves][0])
+
+		st2, err := OpenSplit(r, encode.I32{})
+		ta.NoError(err)
+
+		st3, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st3.UnmarshalIndexOnly(bytes.NewReader(buf), int64(len(buf))))
+
+		for _, lazy := range []*SlimTrie{st2, st3} {
+			ta.Contains(lazy.Summary(), "20000 leaves")
+			ta.Equal(int32(0), lazy.lazyLeaves.loaded, "Summary does not load leaves")
+
+			n := lazy.summaryBytes()
+			ta.InDelta(mapped, n, float64(mapped)/50, "summary: %d, mapped: %d", n, mapped)
+		}
+		ta.NotContains(r.offsets, leavesOffset)
+	})
+}
@@ -0,0 +1,248 @@
+package trie
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// NewFromStreams creates a SlimTrie from two streams: one of ascending sorted
+// keys and one of the corresponding values, read in lockstep until keys is
+// closed.
+//
+// Values are encoded as soon as they are received, thus values are never
+// buffered. Keys have to be held until all of them are received, because the
+// creator needs the entire key set to build a trie.
+//
+// If values is nil, the SlimTrie is created without values, like
+// NewSlimTrie() with nil values does.
+//
+// It returns an ErrKeyOutOfOrder error as soon as a key is not greater than
+// the previous one, and an ErrLengthMismatch error if one of the streams is
+// closed before the other.
+// Once an error is returned, nothing more is read from the streams, it is the
+// responsibility of the producers not to block forever.
+//
+// If enc is nil and opt.ValueType is specified, the encoder registered with
+// the tag is used.
+// A nil opt is the same as &Opt{}.
+//
+// Since 0.5.12
+func NewFromStreams(keys <-chan string, values <-chan interface{}, enc encode.Encoder, opt *Opt) (*SlimTrie, error) {
+
+	o := Opt{}
+	if opt != nil {
+		o = *opt
+	}
+	normalizeOpt(&o)
+
+	if enc == nil && o.ValueType != "" {
+		enc, _ = encode.Lookup(o.ValueType)
+	}
+
+	if values == nil {
+		enc = nil
+	}
+
+	src := &streamSource{keys: keys, values: values}
+	src.collation = o.Collation
+
+	return newFromSource(src, &src.keyOrder, enc, &o)
+}
+
+// NewFromSortedLines creates a SlimTrie from newline delimited ascending
+// sorted keys read from r, such as a file of sorted keys.
+// A trailing "\r" of a line is removed, and a line must not be longer than
+// bufio.MaxScanTokenSize.
+//
+// values returns the value of the i-th key, for i starting from 0.
+// It is called once for every key as soon as the key is read, and the value
+// is encoded right away.
+// If values is nil, the SlimTrie is created without values.
+//
+// Keys are read one by one but have to be held until all of them are read,
+// because the creator needs the entire key set to build a trie.
+//
+// It returns an ErrKeyOutOfOrder error as soon as a key is not greater than
+// the previous one.
+// An error reading from r is returned as is, with a message.
+//
+// If enc is nil and opt.ValueType is specified, the encoder registered with
+// the tag is used.
+// A nil opt is the same as &Opt{}.
+//
+// Since 0.5.12
+func NewFromSortedLines(r io.Reader, values func(i int) interface{}, enc encode.Encoder, opt *Opt) (*SlimTrie, error) {
+
+	o := Opt{}
+	if opt != nil {
+		o = *opt
+	}
+	normalizeOpt(&o)
+
+	if enc == nil && o.ValueType != "" {
+		enc, _ = encode.Lookup(o.ValueType)
+	}
+
+	if values == nil {
+		enc = nil
+	}
+
+	src := &lineSource{scanner: bufio.NewScanner(r), values: values}
+	src.collation = o.Collation
+
+	return newFromSource(src, &src.keyOrder, enc, &o)
+}
+
+// newFromSource creates a SlimTrie from src with a normalized opt.
+// An error found by order when reading src is returned in preference to an
+// error of building.
+//
+// Since 0.5.12
+func newFromSource(src KeySource, order *keyOrder, enc encode.Encoder, o *Opt) (*SlimTrie, error) {
+
+	ns, err := buildFromSource(src, enc, o)
+	if order.err != nil {
+		return nil, order.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	st := &SlimTrie{
+		inner:     ns,
+		encoder:   enc,
+		collation: o.Collation,
+		valueFunc: newValueFunc(o.ValueFunc),
+	}
+	err = st.init()
+	if err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// keyOrder checks keys read from a source are strictly ascending.
+// It stores the first error of a source in err.
+//
+// Since 0.5.12
+type keyOrder struct {
+	// collation orders keys instead of byte order, if it is not nil.
+	collation Collation
+
+	n    int
+	prev string
+	err  error
+}
+
+// check returns true if k is greater than the previous key.
+// Otherwise it sets err and returns false.
+func (s *keyOrder) check(k string) bool {
+
+	if s.n > 0 && s.compare(s.prev, k) >= 0 {
+		s.err = errors.Wrapf(ErrKeyOutOfOrder,
+			"keys[%d] >= keys[%d] %q %q", s.n-1, s.n, s.prev, k)
+		return false
+	}
+
+	s.prev = k
+	s.n++
+
+	return true
+}
+
+func (s *keyOrder) compare(a, b string) int {
+	if s.collation != nil {
+		return s.collation.Compare(a, b)
+	}
+	return strings.Compare(a, b)
+}
+
+// streamSource is a KeySource that zips a key stream and a value stream.
+// It stops at the first error and stores it in err.
+type streamSource struct {
+	keyOrder
+
+	keys   <-chan string
+	values <-chan interface{}
+}
+
+func (s *streamSource) Next() (string, interface{}, bool) {
+
+	if s.err != nil {
+		return "", nil, false
+	}
+
+	k, kok := <-s.keys
+
+	if s.values == nil {
+		return s.checkOrder(k, nil, kok)
+	}
+
+	if !kok {
+		if _, vok := <-s.values; vok {
+			s.err = errors.Wrapf(ErrLengthMismatch, "more values than %d keys", s.n)
+		}
+		return "", nil, false
+	}
+
+	v, vok := <-s.values
+	if !vok {
+		s.err = errors.Wrapf(ErrLengthMismatch, "only %d values for more keys", s.n)
+		return "", nil, false
+	}
+
+	return s.checkOrder(k, v, true)
+}
+
+func (s *streamSource) checkOrder(k string, v interface{}, ok bool) (string, interface{}, bool) {
+
+	if !ok || !s.check(k) {
+		return "", nil, false
+	}
+
+	return k, v, true
+}
+
+// lineSource is a KeySource that reads a key from every line and calls values
+// for its value.
+// It stops at the first error and stores it in err.
+//
+// Since 0.5.12
+type lineSource struct {
+	keyOrder
+
+	scanner *bufio.Scanner
+	values  func(i int) interface{}
+}
+
+func (s *lineSource) Next() (string, interface{}, bool) {
+
+	if s.err != nil {
+		return "", nil, false
+	}
+
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			s.err = errors.WithMessage(err, "failed to read keys")
+		}
+		return "", nil, false
+	}
+
+	k := s.scanner.Text()
+	i := s.n
+	if !s.check(k) {
+		return "", nil, false
+	}
+
+	var v interface{}
+	if s.values != nil {
+		v = s.values(i)
+	}
+
+	return k, v, true
+}
@@ -0,0 +1,145 @@
+package trie
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func sendStreams(keys []string, values []int32) (<-chan string, <-chan interface{}) {
+
+	kch := make(chan string)
+	vch := make(chan interface{})
+
+	go func() {
+		defer close(kch)
+		for _, k := range keys {
+			kch <- k
+		}
+	}()
+
+	go func() {
+		defer close(vch)
+		for _, v := range values {
+			vch <- v
+		}
+	}()
+
+	return kch, vch
+}
+
+func TestNewFromStreams(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	t.Run("ok", func(t *testing.T) {
+		kch, vch := sendStreams(keys, values)
+
+		st, err := NewFromStreams(kch, vch, encode.I32{}, &Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		testPresentKeysGet(t, st, keys, values)
+
+		want, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		slimtrieEqual(want, st, t)
+	})
+
+	t.Run("noValues", func(t *testing.T) {
+		kch, _ := sendStreams(keys[:100], nil)
+
+		st, err := NewFromStreams(kch, nil, encode.I32{}, nil)
+		ta.NoError(err)
+		for _, k := range keys[:100] {
+			ta.NotEqual(int32(-1), st.GetID(k))
+		}
+	})
+
+	t.Run("outOfOrder", func(t *testing.T) {
+		kch, vch := sendStreams([]string{"a", "c", "b"}, []int32{1, 2, 3})
+
+		_, err := NewFromStreams(kch, vch, encode.I32{}, nil)
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+	})
+
+	t.Run("moreKeys", func(t *testing.T) {
+		kch, vch := sendStreams([]string{"a", "b", "c"}, []int32{1, 2})
+
+		_, err := NewFromStreams(kch, vch, encode.I32{}, nil)
+		ta.Equal(ErrLengthMismatch, errors.Cause(err))
+	})
+
+	t.Run("moreValues", func(t *testing.T) {
+		kch, vch := sendStreams([]string{"a", "b"}, []int32{1, 2, 3})
+
+		_, err := NewFromStreams(kch, vch, encode.I32{}, nil)
+		ta.Equal(ErrLengthMismatch, errors.Cause(err))
+	})
+}
+
+func TestNewFromSortedLines(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	valueOf := func(i int) interface{} { return values[i] }
+
+	t.Run("ok", func(t *testing.T) {
+		r := strings.NewReader(strings.Join(keys, "\n") + "\n")
+
+		st, err := NewFromSortedLines(r, valueOf, encode.I32{}, &Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		testPresentKeysGet(t, st, keys, values)
+
+		want, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		slimtrieEqual(want, st, t)
+	})
+
+	t.Run("crlf", func(t *testing.T) {
+		r := strings.NewReader("a\r\nb\r\nc")
+
+		st, err := NewFromSortedLines(r, valueOf, encode.I32{}, &Opt{Complete: Bool(true)})
+		ta.NoError(err)
+		testPresentKeysGet(t, st, []string{"a", "b", "c"}, values[:3])
+	})
+
+	t.Run("noValues", func(t *testing.T) {
+		r := strings.NewReader(strings.Join(keys[:100], "\n"))
+
+		st, err := NewFromSortedLines(r, nil, encode.I32{}, nil)
+		ta.NoError(err)
+		ta.Equal(100, st.Len())
+		for _, k := range keys[:100] {
+			ta.NotEqual(int32(-1), st.GetID(k))
+		}
+	})
+
+	t.Run("outOfOrder", func(t *testing.T) {
+		called := 0
+		r := strings.NewReader("a\nc\nb\nd\n")
+
+		_, err := NewFromSortedLines(r, func(i int) interface{} {
+			called++
+			return values[i]
+		}, encode.I32{}, nil)
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+		ta.Contains(err.Error(), `keys[1] >= keys[2] "c" "b"`)
+		ta.Equal(2, called)
+	})
+
+	t.Run("readError", func(t *testing.T) {
+		r := io.MultiReader(strings.NewReader("a\nb\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+
+		_, err := NewFromSortedLines(r, valueOf, encode.I32{}, nil)
+		ta.Equal(io.ErrUnexpectedEOF, errors.Cause(err))
+	})
+}
@@ -1,6 +1,7 @@
 package trie
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/openacid/slim/encode"
@@ -40,3 +41,46 @@ func TestSlimTrie_stringValue(t *testing.T) {
 	}
 
 }
+
+func TestSlimTrie_stringEncoder(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "ab", "abc", "b", "bc", "c"}
+	values := []string{
+		"",
+		"x",
+		strings.Repeat("y", 3000),
+		"",
+		strings.Repeat("z", 70000),
+		"short",
+	}
+
+	for _, opt := range []Opt{
+		{Complete: Bool(true)},
+		{Complete: Bool(true), LeafBlockSize: 2},
+		{Complete: Bool(true), LeafExceptions: Bool(true)},
+	} {
+		st, err := NewSlimTrie(encode.String{}, keys, values, opt)
+		ta.NoError(err)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.String{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+
+		for _, s := range []*SlimTrie{st, st2} {
+			for i, k := range keys {
+				v, found := s.Get(k)
+				ta.True(found, "opt: %+v, %d-th key %q", opt, i, k)
+				ta.Equal(values[i], v, "opt: %+v, %d-th key %q", opt, i, k)
+
+				var dst string
+				ta.True(s.GetInto(k, &dst))
+				ta.Equal(values[i], dst, "opt: %+v, %d-th key %q", opt, i, k)
+			}
+		}
+	}
+}
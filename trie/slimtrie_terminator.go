@@ -0,0 +1,41 @@
+package trie
+
+import (
+	"strings"
+
+	"github.com/openacid/errors"
+)
+
+// KeyTerminator is appended to every key of a SlimTrie built with
+// Opt.WithTerminator.
+//
+// Since 0.5.12
+const KeyTerminator = "\x00"
+
+// terminateKeys checks keys do not contain KeyTerminator and returns them with
+// KeyTerminator appended.
+// Appending the same smallest byte to every key keeps them in the same order.
+//
+// Since 0.5.12
+func terminateKeys(keys []string) ([]string, error) {
+
+	rst := make([]string, len(keys))
+	for i, k := range keys {
+		if strings.Contains(k, KeyTerminator) {
+			return nil, errors.Wrapf(ErrTerminatorInKey, "keys[%d] %q", i, k)
+		}
+		rst[i] = k + KeyTerminator
+	}
+	return rst, nil
+}
+
+// terminate converts a query key to the form stored in a SlimTrie built with
+// Opt.WithTerminator.
+//
+// Since 0.5.12
+func (st *SlimTrie) terminate(key string) string {
+	if !st.inner.OptWithTerminator {
+		return key
+	}
+	return key + KeyTerminator
+}
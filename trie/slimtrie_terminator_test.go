@@ -0,0 +1,121 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_WithTerminator(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "bc"}
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{
+		{WithTerminator: Bool(true)},
+		{WithTerminator: Bool(true), InnerPrefix: Bool(true)},
+		{WithTerminator: Bool(true), Complete: Bool(true)},
+		{WithTerminator: Bool(true), Complete: Bool(true), WithFoldedIndex: Bool(true)},
+	} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+		ta.True(st.IsPrefixFree())
+		ta.True(*st.BuildOptions().WithTerminator)
+
+		buf, err := st.Marshal()
+		ta.NoError(err)
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+
+		for _, s := range []*SlimTrie{st, st2} {
+			for i, k := range keys {
+				v, found := s.Get(k)
+				ta.True(found, "%q", k)
+				ta.Equal(int32(i), v, "%q", k)
+
+				l, eq, r := s.Search(k)
+				ta.Equal(int32(i), eq, "%q", k)
+				if i > 0 {
+					ta.Equal(int32(i-1), l, "%q", k)
+				}
+				if i < len(keys)-1 {
+					ta.Equal(int32(i+1), r, "%q", k)
+				}
+
+				exact, exactOK, prefix, prefixOK := s.Route(k)
+				ta.Equal(int32(i), exact)
+				ta.True(exactOK)
+				ta.Equal(int32(i), prefix)
+				ta.True(prefixOK)
+
+				nodeID, consumed := s.PartialGetID(k)
+				v, found = s.ResumeGet(nodeID, k[consumed:])
+				ta.True(found, "%q", k)
+				ta.Equal(int32(i), v, "%q", k)
+			}
+		}
+
+		if opt.WithFoldedIndex != nil {
+			v, found := st2.GetFolded("AB")
+			ta.True(found)
+			ta.Equal(int32(2), v)
+		}
+
+		if opt.Complete == nil {
+			continue
+		}
+
+		for _, k := range []string{"aa", "abcd", "c", "\x00"} {
+			_, found := st.Get(k)
+			ta.False(found, "%q", k)
+		}
+
+		got := []string{}
+		ta.NoError(st2.IterKV(func(key string, val interface{}) bool {
+			got = append(got, key)
+			return true
+		}))
+		ta.Equal(keys, got)
+
+		got = []string{}
+		st2.ScanFrom("ab", false, false, func(key, val []byte) bool {
+			got = append(got, string(key))
+			return true
+		})
+		ta.Equal([]string{"abc", "abd", "b", "bc"}, got)
+
+		got = []string{}
+		st2.ScanFrom("ab", true, false, func(key, val []byte) bool {
+			got = append(got, string(key))
+			return true
+		})
+		ta.Equal([]string{"ab", "abc", "abd", "b", "bc"}, got)
+	}
+
+	t.Run("terminatorInKey", func(t *testing.T) {
+		_, err := NewSlimTrie(encode.I32{}, []string{"a", "a\x00b"}, makeI32s(2),
+			Opt{WithTerminator: Bool(true)})
+		ta.Equal(ErrTerminatorInKey, errors.Cause(err))
+
+		_, err = NewSlimTrie(encode.I32{}, []string{"a", "a\x00b"}, makeI32s(2))
+		ta.NoError(err)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		kch, vch := sendStreams(keys, values)
+		st, err := NewFromStreams(kch, vch, encode.I32{},
+			&Opt{WithTerminator: Bool(true), Complete: Bool(true)})
+		ta.NoError(err)
+		for i, k := range keys {
+			v, found := st.Get(k)
+			ta.True(found, "%q", k)
+			ta.Equal(int32(i), v, "%q", k)
+		}
+	})
+}
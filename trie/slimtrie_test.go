@@ -63,26 +63,37 @@ func TestNewSlimTrie_Error(t *testing.T) {
 		keys    []string
 		values  []int
 		wanterr error
+		wantmsg string
 	}{
 		{
 			[]string{"a", "a"},
 			[]int{1, 2},
 			ErrKeyOutOfOrder,
+			`keys[0] >= keys[1] "a" "a"`,
 		},
 		{
 			[]string{"ab", "a"},
 			[]int{1, 2},
 			ErrKeyOutOfOrder,
+			`keys[0] >= keys[1] "ab" "a"`,
 		},
 		{
 			[]string{"ab", "aa"},
 			[]int{1, 2},
 			ErrKeyOutOfOrder,
+			`keys[0] >= keys[1] "ab" "aa"`,
 		},
 		{
 			[]string{"ab", "aaa"},
 			[]int{1, 2},
 			ErrKeyOutOfOrder,
+			`keys[0] >= keys[1] "ab" "aaa"`,
+		},
+		{
+			[]string{"a", "b", "c\xff", "c\x00", "d"},
+			[]int{1, 2, 3, 4, 5},
+			ErrKeyOutOfOrder,
+			`keys[2] >= keys[3] "c\xff" "c\x00"`,
 		},
 	}
 
@@ -90,6 +101,9 @@ func TestNewSlimTrie_Error(t *testing.T) {
 		st, err := NewSlimTrie(encode.Int{}, c.keys, c.values)
 		ta.Equal(c.wanterr, errors.Cause(err), "%d-th: input: keys: %v; vals: %v; wanterr: %v; actual: %v",
 			i+1, c.keys, c.values, c.wanterr, err)
+		if c.wantmsg != "" {
+			ta.Contains(err.Error(), c.wantmsg, "%d-th", i+1)
+		}
 
 		if err == nil && len(c.keys) > 0 {
 			v, found := st.Get(c.keys[0])
@@ -468,6 +482,54 @@ func TestSlimTrie_RangeGet_1_leafNotToKeep(t *testing.T) {
 	}
 }
 
+func TestSlimTrie_RangeGetWithBounds(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	ids := make([]int32, len(keys)+1)
+	for i, k := range keys {
+		ids[i] = st.GetID(k)
+	}
+	ids[len(keys)] = -1
+
+	for i, k := range keys {
+		// the key itself and a key between it and the next one
+		for _, q := range []string{k, k + "\x00"} {
+			start, end, v, found := st.RangeGetWithBounds(q)
+			ta.True(found, "%q", q)
+			ta.Equal(values[i], v, "%q", q)
+			ta.Equal(ids[i], start, "%q", q)
+			ta.Equal(ids[i+1], end, "%q", q)
+
+			rv, _ := st.RangeGet(q)
+			ta.Equal(rv, v, "%q", q)
+		}
+	}
+
+	t.Run("notFound", func(t *testing.T) {
+		ta := require.New(t)
+
+		small, err := NewSlimTrie(encode.I32{}, []string{"b", "c"}, []int32{1, 2})
+		ta.NoError(err)
+		empty, err := NewSlimTrie(encode.I32{}, nil, []int32{})
+		ta.NoError(err)
+
+		for _, s := range []*SlimTrie{small, empty} {
+			start, end, v, found := s.RangeGetWithBounds("a")
+			ta.False(found)
+			ta.Nil(v)
+			ta.Equal(int32(-1), start)
+			ta.Equal(int32(-1), end)
+		}
+	})
+}
+
 func TestSlimTrie_RangeGet_1_rangeindex_bug_2019_05_21(t *testing.T) {
 
 	// RangeGet has bug found by Liu Baohai:
@@ -634,6 +696,87 @@ func TestSlimTrie_GRS_3_bigInner_300(t *testing.T) {
 	testPresentKeysGRS(t, st, keys, values)
 }
 
+func TestSlimTrie_GRS_3_bigInner_BigThreshold(t *testing.T) {
+
+	ta := require.New(t)
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	none, err := NewSlimTrie(encode.I32{}, keys, values, Opt{BigThreshold: -1})
+	ta.NoError(err)
+	ta.Equal(int32(0), none.inner.BigInnerCnt)
+
+	many, err := NewSlimTrie(encode.I32{}, keys, values, Opt{BigThreshold: 1})
+	ta.NoError(err)
+	ta.True(many.inner.BigInnerCnt > st.inner.BigInnerCnt,
+		"BigThreshold=1: %d, default: %d", many.inner.BigInnerCnt, st.inner.BigInnerCnt)
+
+	// More big nodes, fewer levels.
+	ta.True(len(many.levels) <= len(st.levels))
+	ta.True(len(st.levels) <= len(none.levels))
+
+	for _, s := range []*SlimTrie{none, many} {
+		testUnknownKeysGRS(t, s, testutil.RandStrSlice(len(keys)*5, 0, 10))
+		testPresentKeysGRS(t, s, keys, values)
+
+		buf, err := s.Marshal()
+		ta.NoError(err)
+
+		s2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(s2.Unmarshal(buf))
+		testPresentKeysGRS(t, s2, keys, values)
+	}
+}
+
+func TestSlimTrie_GRS_3_NoInnerPrefix(t *testing.T) {
+
+	ta := require.New(t)
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{
+		{},
+		{Complete: Bool(true)},
+		{BigThreshold: 1},
+	} {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+		ta.True(st.inner.InnerPrefixes.EltCnt > 0)
+
+		opt.NoInnerPrefix = Bool(true)
+		s, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		ta.Equal(int32(0), s.inner.InnerPrefixes.EltCnt, "opt: %+v", opt)
+		ta.Equal(0, len(s.inner.InnerPrefixes.Bytes), "opt: %+v", opt)
+		ta.True(len(s.levels) > len(st.levels), "opt: %+v", opt)
+		ta.True(s.Stats().InnerCnt > st.Stats().InnerCnt, "opt: %+v", opt)
+
+		testUnknownKeysGRS(t, s, testutil.RandStrSlice(len(keys)*5, 0, 10))
+		testPresentKeysGRS(t, s, keys, values)
+		if opt.Complete != nil && *opt.Complete {
+			testAbsentKeysGRS(t, s, keys)
+		}
+
+		buf, err := s.Marshal()
+		ta.NoError(err)
+
+		s2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(s2.Unmarshal(buf))
+		ta.True(s.Equal(s2))
+	}
+
+	// every shared word is a node with a single label
+	s, err := NewSlimTrie(encode.I32{}, []string{"abcdef1", "abcdef2"}, []int32{1, 2}, Opt{NoInnerPrefix: Bool(true)})
+	ta.NoError(err)
+	ta.Equal(int32(14), s.Stats().InnerCnt)
+}
+
 func TestSlimTrie_GRS_9_allkeyset(t *testing.T) {
 
 	testBigKeySet(t, func(t *testing.T, typ string, keys []string) {
@@ -648,6 +791,29 @@ func TestSlimTrie_GRS_9_allkeyset(t *testing.T) {
 	})
 }
 
+func TestSlimTrie_GRS_9_allkeyset_NoShortTable(t *testing.T) {
+
+	testBigKeySet(t, func(t *testing.T, typ string, keys []string) {
+		ta := require.New(t)
+
+		values := makeI32s(len(keys))
+
+		for _, opt := range []Opt{
+			{NoShortTable: Bool(true)},
+			{NoShortTable: Bool(true), Complete: Bool(true)},
+		} {
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			ta.Equal(int32(0), st.inner.ShortSize)
+			ta.Nil(st.inner.ShortTable)
+
+			testUnknownKeysGRS(t, st, testutil.RandStrSlice(len(keys)*5, 0, 20))
+			testPresentKeysGRS(t, st, keys, values)
+		}
+	})
+}
+
 func TestSlimTrie_GRS_1_empty_string_branch(t *testing.T) {
 
 	// In Get() the loop must end after i reaches lenWords
@@ -688,6 +854,177 @@ func TestSlimTrie_GRS_1_empty_string_branch(t *testing.T) {
 	testPresentKeysGRS(t, st, keys, values)
 }
 
+func TestSlimTrie_Get_longerThanStoredKey(t *testing.T) {
+
+	// A query that has a stored key as prefix but has extra trailing bytes is
+	// not the stored key.
+	// It is not found if SlimTrie is created with Opt{Complete: Bool(true)}.
+	// Without complete key information it could be a false positive, e.g.,
+	// with only Opt{LeafPrefix: Bool(true)}, "aa" is found in {"a", "ab", "abc"}.
+
+	ta := require.New(t)
+
+	cases := []struct {
+		keys    []string
+		queries []string
+	}{
+		{[]string{"ab"}, []string{"abc", "ab\x00", "ab\xff", "abcdefgh"}},
+		{[]string{"ab", "abd"}, []string{"abc", "abde", "ab\x00"}},
+		{[]string{"a", "ab", "abc"}, []string{"abcd", "abc\x00", "aa", "abd"}},
+		{[]string{"ab", "b"}, []string{"abc", "ba", "b\x00"}},
+		{[]string{"abc", "abd", "b"}, []string{"abca", "abdd", "bb"}},
+	}
+
+	for _, opt := range []Opt{
+		{Complete: Bool(true)},
+		{Complete: Bool(true), NoShortTable: Bool(true)},
+	} {
+		for i, c := range cases {
+			st, err := NewSlimTrie(encode.I32{}, c.keys, makeI32s(len(c.keys)), opt)
+			ta.NoError(err)
+
+			for j, k := range c.keys {
+				v, found := st.Get(k)
+				ta.True(found, "%d-th: key: %q", i+1, k)
+				ta.Equal(int32(j), v)
+			}
+
+			for _, q := range c.queries {
+				v, found := st.Get(q)
+				ta.False(found, "%d-th: opt: %+v, query: %q", i+1, opt, q)
+				ta.Nil(v)
+				ta.Equal(int32(-1), st.GetID(q))
+			}
+		}
+	}
+}
+
+func TestSlimTrie_ExactGet(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "ab", "abc", "b"}
+	values := makeI32s(len(keys))
+	absent := []string{"", "aa", "abcd", "abd", "ba", "c"}
+
+	for _, opt := range []Opt{
+		{Complete: Bool(true)},
+		{InnerPrefix: Bool(true), LeafPrefix: Bool(true)},
+	} {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		for i, k := range keys {
+			v, found := st.ExactGet(k)
+			ta.True(found, "opt: %+v, key: %q", opt, k)
+			ta.Equal(values[i], v)
+		}
+
+		for _, k := range absent {
+			v, found := st.ExactGet(k)
+			ta.False(found, "opt: %+v, key: %q", opt, k)
+			ta.Nil(v)
+		}
+	}
+
+	// Without complete keys, Get() returns a false positive and ExactGet() can
+	// not verify any key.
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{LeafPrefix: Bool(true)})
+	ta.NoError(err)
+
+	_, found := st.Get("aa")
+	ta.True(found)
+
+	for _, k := range append(keys, absent...) {
+		v, found := st.ExactGet(k)
+		ta.False(found, "key: %q", k)
+		ta.Nil(v)
+	}
+
+	// empty
+	st, err = NewSlimTrie(encode.I32{}, nil, nil, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	v, found := st.ExactGet("a")
+	ta.False(found)
+	ta.Nil(v)
+}
+
+func TestSlimTrie_GetWithDepth(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"abc", "abd", "b"}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	cases := []struct {
+		key   string
+		want  interface{}
+		nbits int
+		found bool
+	}{
+		{"abc", int32(0), 24, true},
+		{"abd", int32(1), 24, true},
+		{"b", int32(2), 8, true},
+		// false positives: the bits after 24 or 8 are not looked at.
+		{"abcde", int32(0), 24, true},
+		{"bcd", int32(2), 8, true},
+		{"c", nil, 0, false},
+	}
+
+	for i, c := range cases {
+		v, nbits, found := st.GetWithDepth(c.key)
+		ta.Equal(c.want, v, "%d-th: key: %q", i+1, c.key)
+		ta.Equal(c.nbits, nbits, "%d-th: key: %q", i+1, c.key)
+		ta.Equal(c.found, found, "%d-th: key: %q", i+1, c.key)
+	}
+
+	// With leaf prefixes, all bits of a found key are compared.
+	st, err = NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for i, k := range keys {
+		v, nbits, found := st.GetWithDepth(k)
+		ta.True(found)
+		ta.Equal(values[i], v)
+		ta.Equal(8*len(k), nbits, "key: %q", k)
+	}
+
+	_, nbits, found := st.GetWithDepth("abcde")
+	ta.False(found)
+	ta.Equal(0, nbits)
+
+	// the same as where KeyProbe() stops.
+	keys = getKeys("20kvl10")
+	values = makeI32s(len(keys))
+	st, err = NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	queries := append([]string{}, keys[:1000]...)
+	queries = append(queries, testutil.RandStrSlice(1000, 0, 20)...)
+	for _, k := range queries {
+		_, nbits, found := st.GetWithDepth(k)
+		steps := st.KeyProbe(k)
+		last := steps[len(steps)-1]
+		ta.Equal(last.Matched, found, "key: %q", k)
+		if found {
+			ta.Equal(int(last.To), nbits, "key: %q", k)
+		}
+	}
+
+	// empty
+	st, err = NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+
+	v, nbits, found := st.GetWithDepth("a")
+	ta.Nil(v)
+	ta.Equal(0, nbits)
+	ta.False(found)
+}
+
 func TestSlimTrie_Search_0_tiny(t *testing.T) {
 
 	ta := require.New(t)
@@ -1187,3 +1524,44 @@ func getKeys(fn string) []string {
 	cache[fn] = ks
 	return ks
 }
+
+func TestSlimTrie_KeyTooLong(t *testing.T) {
+
+	// it allocates keys of MaxKeyLen bytes.
+	iambig(t)
+
+	long := strings.Repeat("a", MaxKeyLen+1)
+
+	t.Run("build", func(t *testing.T) {
+		ta := require.New(t)
+
+		_, err := NewSlimTrie(encode.I32{}, []string{"a", long}, []int32{1, 2})
+		ta.Equal(ErrKeyTooLong, errors.Cause(err))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		ta := require.New(t)
+
+		st, err := NewSlimTrie(encode.I32{}, []string{"a", "b"}, []int32{1, 2}, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		ta.Equal(int32(-1), st.GetID(long))
+
+		_, found := st.Get(long)
+		ta.False(found)
+
+		l, eq, r := st.Search(long)
+		ta.Equal(int32(1), l)
+		ta.Nil(eq)
+		ta.Equal(int32(2), r)
+
+		// the first MaxKeyLen bytes of it is a stored key.
+		st, err = NewSlimTrie(encode.I32{}, []string{"a", long[:MaxKeyLen], "b"}, []int32{1, 2, 3})
+		ta.NoError(err)
+
+		l, eq, r = st.Search(long)
+		ta.Equal(int32(2), l)
+		ta.Nil(eq)
+		ta.Equal(int32(3), r)
+	})
+}
@@ -0,0 +1,88 @@
+package trie
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+
+	"github.com/openacid/errors"
+)
+
+// TSVFormatter converts the encoded bytes of a value to a TSV field.
+//
+// Since 0.5.12
+type TSVFormatter func(value []byte) string
+
+var (
+	// TSVBase64 formats a value in standard base64 encoding.
+	//
+	// Since 0.5.12
+	TSVBase64 TSVFormatter = base64.StdEncoding.EncodeToString
+
+	// TSVHex formats a value in lower case hex.
+	//
+	// Since 0.5.12
+	TSVHex TSVFormatter = hex.EncodeToString
+
+	// TSVRaw writes a value as is.
+	// The output is ambiguous if a value contains tab or newline.
+	//
+	// Since 0.5.12
+	TSVRaw TSVFormatter = func(value []byte) string { return string(value) }
+)
+
+// WriteTSV writes all keys and values in ascending key order to w, one line
+// per key:
+//
+//     <key>\t<value>\n
+//
+// A value is the bytes the encoder encodes it to, formatted by format.
+// The default format is TSVBase64.
+// The value field is empty if a key has no value or SlimTrie does not store
+// values.
+//
+// A key is written as is.
+// The output is ambiguous if a key contains tab or newline.
+//
+// Rebuilding keys requires complete key information, i.e., a SlimTrie created
+// with Opt{Complete: Bool(true)}.
+// Otherwise nothing is written and it returns an ErrIncomplete error.
+//
+// Since 0.5.12
+func (st *SlimTrie) WriteTSV(w io.Writer, format ...TSVFormatter) error {
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return nil
+	}
+
+	if !st.hasCompleteKeys() {
+		return errors.Wrapf(ErrIncomplete, "inner prefix and leaf prefix are required to rebuild keys")
+	}
+
+	fmtValue := TSVBase64
+	if len(format) > 0 && format[0] != nil {
+		fmtValue = format[0]
+	}
+
+	bw := bufio.NewWriter(w)
+	nxt := st.NewIter("", true, st.getLeaves() != nil)
+
+	for {
+		key, val := nxt()
+		if key == nil {
+			break
+		}
+
+		bw.Write(key)
+		bw.WriteByte('\t')
+		bw.WriteString(fmtValue(val))
+		bw.WriteByte('\n')
+	}
+
+	err := bw.Flush()
+	if err != nil {
+		return errors.WithMessage(err, "failed to write TSV")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_WriteTSV(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"", "ab", "abc", "b"}
+	values := []interface{}{"x", nil, "yz", "\t"}
+
+	st, err := NewSlimTrie(encode.String16{}, keys, values,
+		Opt{Complete: Bool(true), DedupValue: Bool(false)})
+	ta.NoError(err)
+
+	cases := []struct {
+		format []TSVFormatter
+		want   string
+	}{
+		{nil, "\tAAF4\nab\t\nabc\tAAJ5eg==\nb\tAAEJ\n"},
+		{[]TSVFormatter{TSVHex}, "\t000178\nab\t\nabc\t0002797a\nb\t000109\n"},
+		{[]TSVFormatter{TSVRaw}, "\t\x00\x01x\nab\t\nabc\t\x00\x02yz\nb\t\x00\x01\t\n"},
+	}
+
+	for i, c := range cases {
+		var buf bytes.Buffer
+		ta.NoError(st.WriteTSV(&buf, c.format...))
+		ta.Equal(c.want, buf.String(), "%d-th", i+1)
+	}
+
+	t.Run("noValue", func(t *testing.T) {
+		st, err := NewSlimTrie(nil, []string{"a", "b"}, nil, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		var buf bytes.Buffer
+		ta.NoError(st.WriteTSV(&buf))
+		ta.Equal("a\t\nb\t\n", buf.String())
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.String16{}, keys, values, Opt{DedupValue: Bool(false)})
+		ta.NoError(err)
+
+		var buf bytes.Buffer
+		err = st.WriteTSV(&buf)
+		ta.Equal(ErrIncomplete, errors.Cause(err))
+		ta.Equal(0, buf.Len())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		st, err := NewSlimTrie(encode.String16{}, nil, nil)
+		ta.NoError(err)
+
+		var buf bytes.Buffer
+		ta.NoError(st.WriteTSV(&buf))
+		ta.Equal(0, buf.Len())
+	})
+}
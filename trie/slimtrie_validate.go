@@ -0,0 +1,581 @@
+package trie
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/low/bitmap"
+)
+
+// validateSlim checks the structure of ns loaded from untrusted bytes, so that
+// a query never reads out of range or loops forever.
+// It returns an ErrCorrupt error describing the first problem found.
+//
+// It checks that bitmap indexes match their bitmaps, node bitmaps and the
+// arrays of prefixes and leaves are large enough for every node, and a child
+// always has a greater node id than its parent.
+// It does not check that keys are in order or values are decodable.
+//
+// It costs O(n) time and O(1) extra space except the indexes rebuilt to
+// compare with.
+//
+// Since 0.5.12
+func validateSlim(ns *Slim) error {
+
+	err := validateNodes(ns)
+	if err != nil {
+		return err
+	}
+
+	if ns.Folded != nil {
+		err = validateFolded(ns)
+		if err != nil {
+			return errors.WithMessage(err, "invalid folded index")
+		}
+	}
+
+	return nil
+}
+
+// validateNodes checks node bitmaps, inner prefixes, leaf prefixes and leaves.
+func validateNodes(ns *Slim) error {
+
+	if ns.NodeTypeBM == nil {
+		// empty SlimTrie
+		if ns.Leaves != nil && ns.Leaves.N > 0 {
+			return errors.Wrapf(ErrCorrupt, "%d leaves without node", ns.Leaves.N)
+		}
+		return nil
+	}
+
+	err := validateBitmap(ns.NodeTypeBM, "r64", "NodeTypeBM")
+	if err != nil {
+		return err
+	}
+
+	totalInner := onesOf(ns.NodeTypeBM.Words)
+	if totalInner > 0 {
+		if ns.Inners == nil {
+			return errors.Wrapf(ErrCorrupt, "%d inner nodes without Inners", totalInner)
+		}
+		err = validateBitmap(ns.Inners, "r128", "Inners")
+		if err != nil {
+			return err
+		}
+	}
+
+	total, _ := nodeCounts(ns)
+
+	if total > int64(len(ns.NodeTypeBM.Words))*64 {
+		return errors.Wrapf(ErrCorrupt, "NodeTypeBM of %d words for %d nodes", len(ns.NodeTypeBM.Words), total)
+	}
+
+	// no inner node beyond the last node
+	if onesBefore(ns.NodeTypeBM.Words, total) != totalInner {
+		return errors.Wrapf(ErrCorrupt, "inner node beyond %d nodes", total)
+	}
+
+	if totalInner > 0 {
+		err = validateInners(ns, int32(totalInner))
+		if err != nil {
+			return err
+		}
+	}
+
+	leafCnt := int32(total - totalInner)
+
+	err = validateInnerPrefixes(ns.InnerPrefixes, int32(totalInner))
+	if err != nil {
+		return errors.WithMessage(err, "invalid InnerPrefixes")
+	}
+
+	if ns.LeafPrefixes != nil {
+		err = validateLeafPrefixes(ns.LeafPrefixes, leafCnt)
+		if err != nil {
+			return errors.WithMessage(err, "invalid LeafPrefixes")
+		}
+	}
+
+	if ns.Leaves != nil {
+		err = validateVLenArray(ns.Leaves, leafCnt)
+		if err != nil {
+			return errors.WithMessage(err, "invalid Leaves")
+		}
+	}
+
+	return nil
+}
+
+// validateInners checks the label bitmap of every inner node is in Inners,
+// has at least one child, and every child has a greater node id than its
+// parent.
+func validateInners(ns *Slim, totalInner int32) error {
+
+	if ns.BigInnerCnt < 0 || ns.BigInnerCnt > totalInner {
+		return errors.Wrapf(ErrCorrupt, "BigInnerCnt %d out of [0, %d]", ns.BigInnerCnt, totalInner)
+	}
+
+	if ns.ShortSize < 0 || ns.ShortSize > maxShortSize {
+		return errors.Wrapf(ErrCorrupt, "ShortSize %d out of [0, %d]", ns.ShortSize, maxShortSize)
+	}
+
+	if ns.BigInnerCnt < totalInner {
+		if ns.ShortBM == nil {
+			return errors.Wrapf(ErrCorrupt, "no ShortBM")
+		}
+		err := validateBitmap(ns.ShortBM, "r64", "ShortBM")
+		if err != nil {
+			return err
+		}
+		if int64(totalInner) > int64(len(ns.ShortBM.Words))*64 {
+			return errors.Wrapf(ErrCorrupt, "ShortBM of %d words for %d inner nodes", len(ns.ShortBM.Words), totalInner)
+		}
+		if onesOf(ns.ShortBM.Words) > 0 {
+			err = validateShortTable(ns)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	ws := ns.Inners.Words
+	size := int64(len(ws)) * 64
+
+	// the id of an inner node
+	nodeID := int64(-1)
+
+	// Label bitmaps are contiguous in Inners: big ones, then normal or short
+	// ones.
+	// rank is the number of "1" before the current label bitmap.
+	prevTo, rank := int64(0), int64(0)
+
+	for ithInner := int32(0); ithInner < totalInner; ithInner++ {
+
+		nodeID = nextOne(ns.NodeTypeBM.Words, nodeID+1)
+
+		var from, to int64
+		isShort := int32(0)
+
+		if ithInner < ns.BigInnerCnt {
+			from = int64(ithInner) * int64(bigInnerSize)
+			to = from + int64(bigInnerSize)
+		} else {
+			var ithShort int32
+			ithShort, isShort = bitmap.Rank64(ns.ShortBM.Words, ns.ShortBM.RankIndex, ithInner)
+			from = int64(bigInnerSize-innerSize)*int64(ns.BigInnerCnt) +
+				int64(innerSize)*int64(ithInner) +
+				int64(ns.ShortSize-innerSize)*int64(ithShort)
+			to = from + int64(innerSize)
+			if isShort != 0 {
+				to = from + int64(ns.ShortSize)
+			}
+		}
+
+		if from != prevTo || to > size {
+			return errors.Wrapf(ErrCorrupt, "label bitmap [%d, %d) of inner node %d, expected from %d in %d bits", from, to, nodeID, prevTo, size)
+		}
+
+		children := onesIn(ws, from, to)
+
+		// A short bitmap is expanded by ShortTable, which must have as many
+		// "1" as the short one.
+		if isShort != 0 {
+			short := bitsIn(ws, from, to)
+			if int64(bits.OnesCount32(ns.ShortTable[short])) != children {
+				return errors.Wrapf(ErrCorrupt, "ShortTable[%d] of inner node %d is %x", short, nodeID, ns.ShortTable[short])
+			}
+		}
+
+		if children == 0 {
+			return errors.Wrapf(ErrCorrupt, "inner node %d has no child", nodeID)
+		}
+
+		firstChild := rank + 1
+		if firstChild <= nodeID {
+			return errors.Wrapf(ErrCorrupt, "node %d has child %d", nodeID, firstChild)
+		}
+
+		prevTo = to
+		rank += children
+	}
+
+	return nil
+}
+
+// validateShortTable checks ShortTable has an entry for every short bitmap
+// and every entry is a label bitmap.
+func validateShortTable(ns *Slim) error {
+
+	n := 1 << uint(ns.ShortSize)
+	if len(ns.ShortTable) < n {
+		return errors.Wrapf(ErrCorrupt, "ShortTable of %d entries for ShortSize %d", len(ns.ShortTable), ns.ShortSize)
+	}
+
+	for i := 0; i < n; i++ {
+		if ns.ShortTable[i]>>uint(innerSize) != 0 {
+			return errors.Wrapf(ErrCorrupt, "ShortTable[%d] is %x", i, ns.ShortTable[i])
+		}
+	}
+	return nil
+}
+
+// validateInnerPrefixes checks the prefixes or the steps of inner nodes.
+func validateInnerPrefixes(ips *VLenArray, totalInner int32) error {
+
+	if ips == nil {
+		if totalInner > 0 {
+			return errors.Wrapf(ErrCorrupt, "nil for %d inner nodes", totalInner)
+		}
+		return nil
+	}
+
+	// the presence is not checked without a prefix.
+	if ips.EltCnt == 0 {
+		return nil
+	}
+
+	cnt, err := validatePresence(ips.PresenceBM, totalInner, "r128")
+	if err != nil {
+		return err
+	}
+
+	if ips.PositionBM != nil {
+		return validatePositions(ips.PositionBM, cnt, int64(len(ips.Bytes)))
+	}
+
+	// steps of 2 bytes
+	if int64(len(ips.Bytes)) < cnt*2 {
+		return errors.Wrapf(ErrCorrupt, "%d bytes for %d steps", len(ips.Bytes), cnt)
+	}
+	return nil
+}
+
+// validateLeafPrefixes checks the prefixes of leaves.
+func validateLeafPrefixes(lp *VLenArray, leafCnt int32) error {
+
+	cnt, err := validatePresence(lp.PresenceBM, leafCnt, "r64")
+	if err != nil {
+		return err
+	}
+
+	if lp.PositionBM == nil {
+		return errors.Wrapf(ErrCorrupt, "no PositionBM")
+	}
+	return validatePositions(lp.PositionBM, cnt, int64(len(lp.Bytes)))
+}
+
+// validatePresence checks a presence bitmap has at least n bits, and returns
+// the number of present elements.
+// presenceIndex is the kind of index of it.
+func validatePresence(bm *Bitmap, n int32, presenceIndex string) (int64, error) {
+
+	if bm == nil {
+		return 0, errors.Wrapf(ErrCorrupt, "no PresenceBM")
+	}
+
+	err := validateBitmap(bm, presenceIndex, "PresenceBM")
+	if err != nil {
+		return 0, err
+	}
+
+	if int64(n) > int64(len(bm.Words))*64 {
+		return 0, errors.Wrapf(ErrCorrupt, "PresenceBM of %d words for %d elements", len(bm.Words), n)
+	}
+
+	return onesOf(bm.Words), nil
+}
+
+// validatePositions checks a position bitmap has the start of cnt elements
+// and the end of the last one, in size bytes.
+func validatePositions(pbm *Bitmap, cnt, size int64) error {
+
+	err := validateBitmap(pbm, "s32", "PositionBM")
+	if err != nil {
+		return err
+	}
+
+	if onesOf(pbm.Words) < cnt+1 {
+		return errors.Wrapf(ErrCorrupt, "PositionBM has less than %d positions", cnt+1)
+	}
+
+	if cnt > 0 {
+		_, end := bitmap.Select32R64(pbm.Words, pbm.SelectIndex, pbm.RankIndex, int32(cnt-1))
+		if int64(end) > size {
+			return errors.Wrapf(ErrCorrupt, "elements end at %d beyond %d bytes", end, size)
+		}
+	}
+	return nil
+}
+
+// validateVLenArray checks va has at least n elements and every present
+// element is in Bytes, in any layout.
+func validateVLenArray(va *VLenArray, n int32) error {
+
+	if va.N < n {
+		return errors.Wrapf(ErrCorrupt, "N %d less than %d", va.N, n)
+	}
+
+	if va.EltCnt == 0 && va.N == 0 {
+		return nil
+	}
+
+	cnt, err := validatePresence(va.PresenceBM, va.N, "r64")
+	if err != nil {
+		return err
+	}
+
+	if cnt != int64(va.EltCnt) {
+		return errors.Wrapf(ErrCorrupt, "EltCnt %d differs from PresenceBM", va.EltCnt)
+	}
+
+	size := int64(len(va.Bytes))
+
+	if va.FixedSize < 0 {
+		return errors.Wrapf(ErrCorrupt, "FixedSize %d", va.FixedSize)
+	}
+
+	switch {
+	case va.BlockSize > 0:
+		return validateBlocks(va)
+
+	case va.ExceptionBM != nil:
+		ebm := va.ExceptionBM
+		err = validateBitmap(ebm, "r64", "ExceptionBM")
+		if err != nil {
+			return err
+		}
+		if cnt > int64(len(ebm.Words))*64 {
+			return errors.Wrapf(ErrCorrupt, "ExceptionBM of %d words for %d elements", len(ebm.Words), cnt)
+		}
+
+		excCnt := onesBefore(ebm.Words, cnt)
+		offs := va.ExceptionOffsets
+		if int64(len(offs)) < excCnt+1 {
+			return errors.Wrapf(ErrCorrupt, "%d ExceptionOffsets for %d exceptions", len(offs), excCnt)
+		}
+		for i := int64(1); i <= excCnt; i++ {
+			if offs[i] < offs[i-1] {
+				return errors.Wrapf(ErrCorrupt, "ExceptionOffsets not ascending at %d", i)
+			}
+		}
+		if (cnt-excCnt)*int64(va.FixedSize)+int64(offs[excCnt]) > size {
+			return errors.Wrapf(ErrCorrupt, "elements exceed %d bytes", size)
+		}
+
+	case va.PositionBM != nil:
+		return validatePositions(va.PositionBM, cnt, size)
+
+	default:
+		if cnt*int64(va.FixedSize) > size {
+			return errors.Wrapf(ErrCorrupt, "%d elements of size %d exceed %d bytes", cnt, va.FixedSize, size)
+		}
+	}
+
+	return nil
+}
+
+// validateBlocks checks the header and the elements of every block are in
+// the block.
+func validateBlocks(va *VLenArray) error {
+
+	cnt := int64(va.EltCnt)
+	bs := int64(va.BlockSize)
+	nBlock := (cnt + bs - 1) / bs
+
+	if int64(len(va.BlockOffsets)) < nBlock {
+		return errors.Wrapf(ErrCorrupt, "%d BlockOffsets for %d blocks", len(va.BlockOffsets), nBlock)
+	}
+
+	for i := int64(0); i < nBlock; i++ {
+
+		from := uint64(va.BlockOffsets[i])
+		to := uint64(len(va.Bytes))
+		if i+1 < int64(len(va.BlockOffsets)) {
+			to = uint64(va.BlockOffsets[i+1])
+		}
+		if from > to || to > uint64(len(va.Bytes)) {
+			return errors.Wrapf(ErrCorrupt, "block %d [%d, %d) exceeds %d bytes", i, from, to, len(va.Bytes))
+		}
+
+		b := va.Bytes[from:to]
+
+		n := bs
+		if cnt-i*bs < n {
+			n = cnt - i*bs
+		}
+
+		minSize, l := binary.Uvarint(b)
+		if l <= 0 || l >= len(b) {
+			return errors.Wrapf(ErrCorrupt, "invalid header of block %d", i)
+		}
+		width := uint64(b[l])
+		if width > 32 {
+			return errors.Wrapf(ErrCorrupt, "invalid width %d of block %d", width, i)
+		}
+
+		blockSize := uint64(len(b))
+		headerSize := uint64(l) + 1 + (uint64(n)*width+7)>>3
+		if headerSize > blockSize || minSize > blockSize {
+			return errors.Wrapf(ErrCorrupt, "invalid header of block %d", i)
+		}
+
+		sizes := b[l+1:]
+		end := headerSize
+		for k := uint64(0); k < uint64(n); k++ {
+			end += minSize + getBlockSize(sizes, uint(k*width), uint(width))
+			if end > blockSize {
+				return errors.Wrapf(ErrCorrupt, "elements of block %d exceed %d bytes", i, blockSize)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFolded checks the folded index, whose leaves are the leaf indexes
+// of ns.
+func validateFolded(ns *Slim) error {
+
+	fd := ns.Folded
+
+	if fd.Folded != nil {
+		return errors.Wrapf(ErrCorrupt, "nested folded index")
+	}
+
+	err := validateNodes(fd)
+	if err != nil {
+		return err
+	}
+
+	if fd.NodeTypeBM == nil {
+		return nil
+	}
+
+	total, totalInner := nodeCounts(ns)
+	leafCnt := total - totalInner
+
+	ls := fd.Leaves
+	if ls == nil || ls.EltCnt != ls.N || ls.PositionBM != nil || ls.ExceptionBM != nil || ls.BlockSize > 0 || ls.FixedSize != 4 {
+		return errors.Wrapf(ErrCorrupt, "leaves are not 4-byte leaf indexes")
+	}
+
+	for i := int32(0); i < ls.EltCnt; i++ {
+		v := int64(int32(binary.LittleEndian.Uint32(ls.Bytes[i*4:])))
+		if v < 0 || v >= leafCnt {
+			return errors.Wrapf(ErrCorrupt, "leaf index %d out of [0, %d)", v, leafCnt)
+		}
+	}
+
+	return nil
+}
+
+// nodeCounts returns the number of all nodes and the number of inner nodes.
+func nodeCounts(ns *Slim) (int64, int64) {
+
+	if ns.NodeTypeBM == nil {
+		return 0, 0
+	}
+
+	totalInner := onesOf(ns.NodeTypeBM.Words)
+	if totalInner == 0 || ns.Inners == nil {
+		// single leaf
+		return 1, totalInner
+	}
+
+	// every node except the root has a "1" pointing to it.
+	return onesOf(ns.Inners.Words) + 1, totalInner
+}
+
+// validateBitmap checks the index of b is the same as the one built from its
+// words.
+func validateBitmap(b *Bitmap, opt string, name string) error {
+
+	c := &Bitmap{Words: b.Words}
+	c.indexit(opt)
+
+	if !int32sEqual(c.RankIndex, b.RankIndex) || len(c.SelectIndex) != len(b.SelectIndex) {
+		return errors.Wrapf(ErrCorrupt, "index of %s mismatch", name)
+	}
+
+	// A select index is where to start scanning for a "1".
+	// Data before 0.5.12 stores a smaller one, which works too.
+	for i, pos := range b.SelectIndex {
+		if pos < 0 || pos>>6 > c.SelectIndex[i]>>6 {
+			return errors.Wrapf(ErrCorrupt, "select index of %s mismatch", name)
+		}
+	}
+	return nil
+}
+
+func int32sEqual(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// onesOf returns the number of "1" in words.
+func onesOf(words []uint64) int64 {
+	return onesBefore(words, int64(len(words))*64)
+}
+
+// bitsIn returns the bits in the range [from, to) of words, at most 64 bits.
+func bitsIn(words []uint64, from, to int64) uint64 {
+	if from == to {
+		return 0
+	}
+	j := uint(from & 63)
+	v := words[from>>6] >> j
+	if (to-1)>>6 != from>>6 {
+		v |= words[to>>6] << (64 - j)
+	}
+	return v & bitmap.Mask[to-from]
+}
+
+// onesIn returns the number of "1" in the range [from, to) of words.
+func onesIn(words []uint64, from, to int64) int64 {
+	n := int64(0)
+	for i := from; i < to; {
+		end := (i | 63) + 1
+		if end > to {
+			end = to
+		}
+		w := words[i>>6] >> uint(i&63)
+		if end-i < 64 {
+			w &= bitmap.Mask[end-i]
+		}
+		n += int64(bits.OnesCount64(w))
+		i = end
+	}
+	return n
+}
+
+// onesBefore returns the number of "1" before the bit at position i.
+func onesBefore(words []uint64, i int64) int64 {
+	n := int64(0)
+	for _, w := range words[:i>>6] {
+		n += int64(bits.OnesCount64(w))
+	}
+	if i&63 != 0 {
+		n += int64(bits.OnesCount64(words[i>>6] & bitmap.Mask[i&63]))
+	}
+	return n
+}
+
+// nextOne returns the position of the first "1" at or after i, or -1 if
+// there is none.
+func nextOne(words []uint64, i int64) int64 {
+	for ; i>>6 < int64(len(words)); i = (i | 63) + 1 {
+		w := words[i>>6] >> uint(i&63)
+		if w != 0 {
+			return i + int64(bits.TrailingZeros64(w))
+		}
+	}
+	return -1
+}
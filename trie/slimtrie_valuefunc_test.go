@@ -0,0 +1,84 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_keyOrdinal(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 50; seed++ {
+
+		rng := rand.New(rand.NewSource(seed))
+		opts := []Opt{
+			{Complete: Bool(true), DedupValue: Bool(false)},
+			{DedupValue: Bool(false)},
+		}
+		st, kvs := RandomTrie(rng, 300, 8, opts[seed%2])
+		keys, _ := sortedKVs(kvs)
+
+		for i, k := range keys {
+			ta.Equal(int32(i), st.keyOrdinal(st.GetID(k)), "seed %d key %q", seed, k)
+		}
+	}
+}
+
+func TestSlimTrie_ValueFunc(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	fn := func(leafOrdinal int32) interface{} {
+		return leafOrdinal * 3
+	}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)),
+		Opt{ValueFunc: fn})
+	ta.NoError(err)
+	ta.Nil(st.inner.Leaves, "values are not stored")
+
+	for i, k := range keys {
+		v, found := st.Get(k)
+		ta.True(found)
+		ta.Equal(int32(i*3), v)
+	}
+
+	l, eq, r := st.Search(keys[10])
+	ta.Equal(int32(9*3), l)
+	ta.Equal(int32(10*3), eq)
+	ta.Equal(int32(11*3), r)
+
+	t.Run("marshal", func(t *testing.T) {
+		buf, err := st.Marshal()
+		ta.NoError(err)
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		ta.NoError(st2.Unmarshal(buf))
+
+		v, found := st2.Get(keys[5])
+		ta.True(found)
+		ta.Nil(v)
+
+		st2.SetValueFunc(fn)
+		v, found = st2.Get(keys[5])
+		ta.True(found)
+		ta.Equal(int32(5*3), v)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		kch, vch := sendStreams(keys[:100], makeI32s(100))
+		st, err := NewFromStreams(kch, vch, encode.I32{}, &Opt{ValueFunc: fn})
+		ta.NoError(err)
+		ta.Nil(st.inner.Leaves)
+
+		v, found := st.Get(keys[7])
+		ta.True(found)
+		ta.Equal(int32(7*3), v)
+	})
+}
@@ -40,6 +40,24 @@ type slimVars struct {
 	//
 	// Since 0.5.12
 	ShortMask uint64
+
+	// RootBytes is a 256-bit bitmap of the first byte of a key.
+	// A 0 bit means a non-empty key starting with this byte is never found,
+	// thus a query could be rejected without a traversal.
+	//
+	// All bits are 1 if the root node is a leaf or has a prefix.
+	//
+	// Since 0.5.12
+	RootBytes [4]uint64
+
+	// KeyLen is the length of every sort key, i.e., a key normalized,
+	// collated and terminated, if it is built with Opt.CheckKeyLen and all
+	// keys are of the same length.
+	// A query key of another length is rejected without a traversal.
+	// It is -1 if query keys are not checked.
+	//
+	// Since 0.5.12
+	KeyLen int32
 }
 
 // initVars initialize internal st.vars
@@ -53,5 +71,44 @@ func (st *SlimTrie) initVars() {
 		BigInnerOffset:  (bigInnerSize - innerSize) * ns.BigInnerCnt,
 		ShortMinusInner: ns.ShortSize - innerSize,
 		ShortMask:       bitmap.Mask[ns.ShortSize],
+		KeyLen:          -1,
+	}
+
+	if ns.OptCheckKeyLen && ns.HasKeyLen {
+		st.vars.KeyLen = ns.KeyLen + int32(len(st.terminate("")))
+	}
+
+	st.initRootBytes()
+}
+
+// initRootBytes initializes st.vars.RootBytes by finding out the branches of
+// the root node every possible first byte leads to.
+//
+// Since 0.5.12
+func (st *SlimTrie) initRootBytes() {
+
+	vars := st.vars
+
+	for i := range vars.RootBytes {
+		vars.RootBytes[i] = ^uint64(0)
+	}
+
+	if st.inner.GetNodeTypeBM() == nil {
+		return
+	}
+
+	qr := &querySession{}
+	st.getNode(0, qr)
+	if qr.isInner == 0 || qr.hasInnerPrefix || qr.innerPrefixLen != 0 {
+		return
+	}
+
+	for b := 0; b < 256; b++ {
+		qr.key = string([]byte{byte(b)})
+		qr.keyBitLen = 8
+		_, has := st.getLeftChildID(qr, 0)
+		if has == 0 {
+			vars.RootBytes[b>>6] &^= 1 << uint(b&63)
+		}
 	}
 }
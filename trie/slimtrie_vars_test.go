@@ -0,0 +1,42 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_RootBytes(t *testing.T) {
+
+	ta := require.New(t)
+
+	for seed := int64(0); seed < 20; seed++ {
+
+		rng := rand.New(rand.NewSource(seed))
+		st, kvs := RandomTrie(rng, rng.Intn(500), 6)
+
+		rejected := 0
+		for b := 0; b < 256; b++ {
+
+			if st.vars.RootBytes[b>>6]&(1<<uint(b&63)) != 0 {
+				continue
+			}
+			rejected++
+
+			// A rejected byte must not lead to any key.
+			for j := 0; j < 10; j++ {
+				k := string([]byte{byte(b)}) + randKey(rng, 5)
+				ta.Equal(int32(-1), st.getIDFrom(0, k, &querySession{}), "key: %q", k)
+			}
+		}
+
+		for k := range kvs {
+			ta.NotEqual(int32(-1), st.GetID(k), "key: %q", k)
+		}
+
+		if len(kvs) > 1 {
+			ta.True(rejected > 0, "seed: %d", seed)
+		}
+	}
+}
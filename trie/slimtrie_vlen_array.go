@@ -1,6 +1,7 @@
 package trie
 
 import (
+	"encoding/binary"
 	"math/bits"
 
 	"github.com/openacid/low/bitmap"
@@ -59,8 +60,180 @@ func newVLenArray(elts [][]byte) *VLenArray {
 	return vlenArray
 }
 
+// newBlockVLenArray builds a VLenArray in which every blockSize present
+// elements are packed into a block, if elements are not of the same size.
+//
+// A block starts with a header of the minimal element size in uvarint, a byte
+// of bit width w, and the element sizes minus the minimal size in w bits each.
+// The content of elements follows the header.
+//
+// Blocks replace the position bitmap with one offset per block and a few bits
+// per element, which costs less space if elements are small and of similar
+// sizes.
+//
+// A blockSize <= 0 disables packing and it is the same as newVLenArray().
+//
+// Since 0.5.12
+func newBlockVLenArray(elts [][]byte, blockSize int32) *VLenArray {
+
+	va := newVLenArray(elts)
+	if va == nil || va.PositionBM == nil || blockSize <= 0 {
+		return va
+	}
+
+	present := make([][]byte, 0, va.EltCnt)
+	for _, elt := range elts {
+		if len(elt) > 0 {
+			present = append(present, elt)
+		}
+	}
+
+	va.PositionBM = nil
+	va.BlockSize = blockSize
+
+	buf := make([]byte, 0, len(va.Bytes)+len(present))
+
+	for s := 0; s < len(present); s += int(blockSize) {
+		e := s + int(blockSize)
+		if e > len(present) {
+			e = len(present)
+		}
+		va.BlockOffsets = append(va.BlockOffsets, uint32(len(buf)))
+		buf = appendBlock(buf, present[s:e])
+	}
+	va.Bytes = buf
+
+	return va
+}
+
+// newExceptionVLenArray builds a VLenArray in which present elements of the
+// most common size are stored in fixed width, and only the elements of other
+// sizes, the exceptions, have their sizes recorded, if elements are not of the
+// same size.
+//
+// Locating an element costs a rank in the exception bitmap instead of a select
+// in the position bitmap, and it costs much less space than a position bitmap
+// if exceptions are rare.
+//
+// Since 0.5.12
+func newExceptionVLenArray(elts [][]byte) *VLenArray {
+
+	va := newVLenArray(elts)
+	if va == nil || va.PositionBM == nil {
+		return va
+	}
+
+	sizeCnt := map[int]int32{}
+	for _, elt := range elts {
+		if len(elt) > 0 {
+			sizeCnt[len(elt)]++
+		}
+	}
+
+	// sizeCnt[0] is 0, thus the first size is always chosen.
+	common := 0
+	for size, n := range sizeCnt {
+		if n > sizeCnt[common] || (n == sizeCnt[common] && size < common) {
+			common = size
+		}
+	}
+
+	excIndexes := make([]int32, 0)
+	offsets := []uint32{0}
+	total := uint32(0)
+
+	ithElt := int32(0)
+	for _, elt := range elts {
+		if len(elt) == 0 {
+			continue
+		}
+		if len(elt) != common {
+			excIndexes = append(excIndexes, ithElt)
+			total += uint32(len(elt))
+			offsets = append(offsets, total)
+		}
+		ithElt++
+	}
+
+	va.PositionBM = nil
+	va.FixedSize = int32(common)
+	va.ExceptionBM = newBM(excIndexes, va.EltCnt, "r64")
+	va.ExceptionOffsets = offsets
+
+	return va
+}
+
+// appendBlock appends header and content of a block of elements to buf.
+func appendBlock(buf []byte, elts [][]byte) []byte {
+
+	minSize, maxSize := len(elts[0]), len(elts[0])
+	for _, elt := range elts {
+		if len(elt) < minSize {
+			minSize = len(elt)
+		}
+		if len(elt) > maxSize {
+			maxSize = len(elt)
+		}
+	}
+
+	width := uint(bits.Len(uint(maxSize - minSize)))
+
+	var sizeBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(sizeBuf[:], uint64(minSize))
+	buf = append(buf, sizeBuf[:n]...)
+	buf = append(buf, byte(width))
+
+	// sizes packed in width bits, from lower bits to higher bits.
+	var w uint64
+	var wbits uint
+	for _, elt := range elts {
+		w |= uint64(len(elt)-minSize) << wbits
+		wbits += width
+		for wbits >= 8 {
+			buf = append(buf, byte(w))
+			w >>= 8
+			wbits -= 8
+		}
+	}
+	if wbits > 0 {
+		buf = append(buf, byte(w))
+	}
+
+	for _, elt := range elts {
+		buf = append(buf, elt...)
+	}
+	return buf
+}
+
 // get returns the `index`-th element.
 func (va *VLenArray) get(index int32) []byte {
+
+	ithElt, present := va.ithElt(index)
+	if !present {
+		return []byte{}
+	}
+
+	return va.getElt(ithElt)
+}
+
+// getElt returns the ith present element, in any layout.
+//
+// Since 0.5.12
+func (va *VLenArray) getElt(ithElt int32) []byte {
+
+	if va.BlockSize > 0 {
+		return va.getInBlock(ithElt)
+	}
+
+	from, to := va.eltRange(ithElt)
+	return va.Bytes[from:to]
+}
+
+// ithElt returns the index of the `index`-th element among present elements
+// and true, or false if it is absent.
+//
+// Since 0.5.12
+func (va *VLenArray) ithElt(index int32) (int32, bool) {
 	if index >= va.N {
 		panic("out of bound")
 	}
@@ -70,22 +243,119 @@ func (va *VLenArray) get(index int32) []byte {
 	presence := va.PresenceBM
 
 	if presence.Words[wordI]&bitmap.Bit[bitI] == 0 {
-		return []byte{}
+		return 0, false
 	}
 
-	ithElt := presence.RankIndex[wordI] + int32(bits.OnesCount64(presence.Words[wordI]&bitmap.Mask[bitI]))
+	return presence.RankIndex[wordI] + int32(bits.OnesCount64(presence.Words[wordI]&bitmap.Mask[bitI])), true
+}
+
+// eltRange returns the range in Bytes of the ith present element, in any
+// layout except blocks.
+//
+// Since 0.5.12
+func (va *VLenArray) eltRange(ithElt int32) (int32, int32) {
+
+	if va.ExceptionBM != nil {
+		return va.eltRangeWithExceptions(ithElt)
+	}
 
 	positions := va.PositionBM
 
 	if positions == nil {
 		// Fixed size elements
 		from := ithElt * va.FixedSize
-		return va.Bytes[from : from+va.FixedSize]
+		return from, from + va.FixedSize
 	}
 
 	// Var-len element
 
-	from, to := bitmap.Select32R64(positions.Words, positions.SelectIndex, positions.RankIndex, ithElt)
-	return va.Bytes[from:to]
+	return bitmap.Select32R64(positions.Words, positions.SelectIndex, positions.RankIndex, ithElt)
+}
 
+// getFixed returns the `index`-th element of a fixed size array of elements
+// of `size` bytes, or nil if the element is absent.
+//
+// Since 0.5.12
+func (va *VLenArray) getFixed(index, size int32) []byte {
+	if va.EltCnt == va.N && va.ExceptionBM == nil {
+		// Every element is present.
+		from := index * size
+		return va.Bytes[from : from+size]
+	}
+
+	b := va.get(index)
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// getInBlock returns the ith present element in a block packed VLenArray.
+//
+// Since 0.5.12
+func (va *VLenArray) getInBlock(ithElt int32) []byte {
+
+	blockI := ithElt / va.BlockSize
+	blockFrom := va.BlockOffsets[blockI]
+
+	return va.eltInBlock(va.Bytes[blockFrom:], ithElt)
+}
+
+// eltInBlock returns the ith present element in b, which starts with the
+// block containing it.
+//
+// Since 0.5.12
+func (va *VLenArray) eltInBlock(b []byte, ithElt int32) []byte {
+
+	blockI := ithElt / va.BlockSize
+
+	// the last block may have less elements
+	cnt := va.EltCnt - blockI*va.BlockSize
+	if cnt > va.BlockSize {
+		cnt = va.BlockSize
+	}
+
+	minSize, n := binary.Uvarint(b)
+	width := uint(b[n])
+	sizes := b[n+1:]
+
+	headerSize := uint(n) + 1 + (uint(cnt)*width+7)>>3
+
+	j := uint(ithElt % va.BlockSize)
+
+	// sum of sizes of elements before the j-th one in this block.
+	from := headerSize + uint(minSize)*j
+	for k := uint(0); k < j; k++ {
+		from += uint(getBlockSize(sizes, k*width, width))
+	}
+
+	size := uint(minSize) + uint(getBlockSize(sizes, j*width, width))
+	return b[from : from+size]
+}
+
+// eltRangeWithExceptions returns the range in Bytes of the ith present
+// element in a VLenArray with exceptions.
+//
+// Since 0.5.12
+func (va *VLenArray) eltRangeWithExceptions(ithElt int32) (int32, int32) {
+
+	ebm := va.ExceptionBM
+	excCnt, isExc := bitmap.Rank64(ebm.Words, ebm.RankIndex, ithElt)
+
+	from := uint32((ithElt-excCnt)*va.FixedSize) + va.ExceptionOffsets[excCnt]
+	size := uint32(va.FixedSize)
+	if isExc == 1 {
+		size = va.ExceptionOffsets[excCnt+1] - va.ExceptionOffsets[excCnt]
+	}
+	return int32(from), int32(from + size)
+}
+
+// getBlockSize reads width bits from bit position bitI in buf.
+func getBlockSize(buf []byte, bitI, width uint) uint64 {
+	var v uint64
+	for k := uint(0); k < width; k++ {
+		i := bitI + k
+		v |= uint64(buf[i>>3]>>(i&7)&1) << k
+	}
+	return v
 }
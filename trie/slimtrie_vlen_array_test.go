@@ -60,5 +60,48 @@ func TestVLenArray_get(t *testing.T) {
 		ta.Equal([]byte{}, va.get(5))
 	}
 
+	// Var-len size packed in blocks
+	{
+		elts := [][]byte{
+			{'a', 'b', 'c'},
+			{}, // empty
+			{},
+			{'c', 'd'},
+			{'e', 'f'},
+			{},
+			{'g'},
+			{'h', 'i', 'j', 'k'},
+		}
+
+		va := newBlockVLenArray(elts, 2)
+
+		ta.Equal(int32(8), va.N)
+		ta.Equal(int32(5), va.EltCnt)
+		ta.Equal(int32(0), va.FixedSize)
+		ta.Equal(int32(2), va.BlockSize)
+		ta.Nil(va.PositionBM)
+		ta.Equal([]uint32{0, 8, 14}, va.BlockOffsets)
+
+		for i, elt := range elts {
+			ta.Equal(elt, va.get(int32(i)), "%d-th", i)
+		}
+	}
+
+	// Fixed size is not packed
+	{
+		elts := [][]byte{
+			{'a', 'b'},
+			{},
+			{'c', 'd'},
+		}
+
+		va := newBlockVLenArray(elts, 2)
+
+		ta.Equal(int32(2), va.FixedSize)
+		ta.Equal(int32(0), va.BlockSize)
+		ta.Nil(va.BlockOffsets)
+		ta.Equal([]byte{'c', 'd'}, va.get(2))
+	}
+
 	// dd(st)
 }
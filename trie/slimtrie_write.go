@@ -0,0 +1,125 @@
+package trie
+
+import (
+	"io"
+
+	"github.com/openacid/slim/encode"
+)
+
+// KeySource provides ascending sorted keys and their values one by one, for
+// building a SlimTrie.
+//
+// Since 0.5.12
+type KeySource interface {
+	// Next returns the next key and value.
+	// It returns false as the last value when there are no more keys.
+	Next() (key string, value interface{}, ok bool)
+}
+
+// BuildAndWrite builds a SlimTrie from src and writes it to w, in the same
+// format as Marshal() does.
+//
+// It is meant for building a SlimTrie offline: values are encoded as soon as
+// they are read from src, and the built structure is written directly to w,
+// without creating a queryable SlimTrie or holding the marshaled bytes.
+//
+// If enc is nil and opt.ValueType is specified, the encoder registered with
+// the tag is used.
+// If there is still no encoder, values are not stored.
+//
+// A nil opt is the same as &Opt{}.
+//
+// Since 0.5.12
+func BuildAndWrite(src KeySource, enc encode.Encoder, w io.Writer, opt *Opt) error {
+
+	o := Opt{}
+	if opt != nil {
+		o = *opt
+	}
+	normalizeOpt(&o)
+
+	if enc == nil && o.ValueType != "" {
+		enc, _ = encode.Lookup(o.ValueType)
+	}
+
+	ns, err := buildFromSource(src, enc, &o)
+	if err != nil {
+		return err
+	}
+
+	_, err = writeSlim(w, ns)
+	return err
+}
+
+// buildFromSource reads all keys and values from src and builds the internal
+// structure of a SlimTrie, with a normalized opt.
+// Values are encoded as soon as they are read.
+func buildFromSource(src KeySource, enc encode.Encoder, opt *Opt) (*Slim, error) {
+
+	if opt.ValueFunc != nil {
+		enc = nil
+	}
+
+	var keys []string
+	var vals [][]byte
+	if enc != nil {
+		vals = make([][]byte, 0)
+	}
+
+	for {
+		k, v, ok := src.Next()
+		if !ok {
+			break
+		}
+		keys = append(keys, k)
+		if enc != nil {
+			vals = append(vals, encodeValue(v, enc))
+		}
+	}
+
+	sortKeys := keys
+	if opt.Collation != nil {
+		var err error
+		sortKeys, err = collateKeys(opt.Collation, keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// key length is checked in the normalized or collated form, without the
+	// terminator.
+	collated := sortKeys
+
+	if *opt.WithTerminator {
+		var err error
+		sortKeys, err = terminateKeys(sortKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ns, err := newSlim(sortKeys, vals, opt, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = buildFolded(ns, sortKeys, opt)
+	if err != nil {
+		return nil, err
+	}
+	recordOpt(ns, opt)
+	recordKeyLen(ns, collated)
+
+	if *opt.SelfCheck {
+		st := &SlimTrie{inner: ns, encoder: enc, collation: opt.Collation}
+		err := st.init()
+		if err != nil {
+			return nil, err
+		}
+		err = st.selfCheck(keys, vals, opt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ns, nil
+}
@@ -0,0 +1,114 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+type sliceKeySource struct {
+	keys   []string
+	values []int32
+	i      int
+}
+
+func (s *sliceKeySource) Next() (string, interface{}, bool) {
+	if s.i == len(s.keys) {
+		return "", nil, false
+	}
+	s.i++
+	return s.keys[s.i-1], s.values[s.i-1], true
+}
+
+func TestBuildAndWrite(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []*Opt{
+		nil,
+		{Complete: Bool(true)},
+		{SelfCheck: Bool(true)},
+		{WithChecksum: Bool(true)},
+	} {
+
+		var o Opt
+		if opt != nil {
+			o = *opt
+		}
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, o)
+		ta.NoError(err)
+		want, err := st.Marshal()
+		ta.NoError(err)
+
+		w := &bytes.Buffer{}
+		err = BuildAndWrite(&sliceKeySource{keys: keys, values: values}, encode.I32{}, w, opt)
+		ta.NoError(err)
+		ta.Equal(want, w.Bytes())
+
+		st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		err = st2.Unmarshal(w.Bytes())
+		ta.NoError(err)
+		testPresentKeysGet(t, st2, keys, values)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		err := BuildAndWrite(&sliceKeySource{}, encode.I32{}, w, nil)
+		ta.NoError(err)
+
+		st, err := NewSlimTrie(encode.I32{}, nil, nil)
+		ta.NoError(err)
+		err = st.Unmarshal(w.Bytes())
+		ta.NoError(err)
+
+		_, found := st.Get("a")
+		ta.False(found)
+	})
+
+	t.Run("noEncoder", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		err := BuildAndWrite(&sliceKeySource{keys: keys, values: values}, nil, w, nil)
+		ta.NoError(err)
+
+		st, err := NewSlimTrie(nil, nil, nil)
+		ta.NoError(err)
+		err = st.Unmarshal(w.Bytes())
+		ta.NoError(err)
+		ta.Nil(st.inner.Leaves)
+
+		v, found := st.Get(keys[5])
+		ta.True(found)
+		ta.Nil(v)
+	})
+
+	t.Run("valueType", func(t *testing.T) {
+		encode.Register("github.com/openacid/slim/trie.testBuildAndWrite", encode.I32{})
+
+		w := &bytes.Buffer{}
+		err := BuildAndWrite(&sliceKeySource{keys: keys, values: values}, nil, w,
+			&Opt{ValueType: "github.com/openacid/slim/trie.testBuildAndWrite"})
+		ta.NoError(err)
+
+		st, err := NewSlimTrie(nil, nil, nil)
+		ta.NoError(err)
+		err = st.Unmarshal(w.Bytes())
+		ta.NoError(err)
+		testPresentKeysGet(t, st, keys, values)
+	})
+
+	t.Run("outOfOrder", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		src := &sliceKeySource{keys: []string{"b", "a"}, values: []int32{1, 2}}
+		err := BuildAndWrite(src, encode.I32{}, w, nil)
+		ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+		ta.Equal(0, w.Len())
+	})
+}
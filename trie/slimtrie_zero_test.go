@@ -0,0 +1,116 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_zeroValue(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, key := range []string{"", "a", "abc"} {
+
+		st := &SlimTrie{}
+
+		v, found := st.Get(key)
+		ta.Nil(v)
+		ta.False(found)
+
+		ta.Equal(int32(-1), st.GetID(key))
+		ta.False(st.Has(key))
+		ta.Equal([]bool{false}, st.HasMany([]string{key}))
+
+		l, eq, r := st.Search(key)
+		ta.Nil(l)
+		ta.Nil(eq)
+		ta.Nil(r)
+
+		v, found = st.RangeGet(key)
+		ta.Nil(v)
+		ta.False(found)
+
+		_, found = st.GetI8(key)
+		ta.False(found)
+		_, found = st.GetI16(key)
+		ta.False(found)
+		_, found = st.GetI32(key)
+		ta.False(found)
+		_, found = st.GetI64(key)
+		ta.False(found)
+
+		var dst int32
+		ta.False(st.GetInto(key, &dst))
+
+		_, found = st.GetFolded(key)
+		ta.False(found)
+
+		_, found = st.GetComposite(key)
+		ta.False(found)
+
+		_, exactOK, _, prefixOK := st.Route(key)
+		ta.False(exactOK)
+		ta.False(prefixOK)
+
+		ta.Equal([]interface{}{}, st.PathValues(key))
+		ta.Equal([]interface{}{}, st.NearestN(key, 3))
+
+		nodeID, _ := st.PartialGetID(key)
+		ta.Equal(int32(-1), nodeID)
+
+		_, found = st.ResumeGet(0, key)
+		ta.False(found)
+
+		res := &Resolution{}
+		st.ResolveInto(res, key)
+		ta.Equal(int32(-1), res.NodeID)
+
+		st.KeyProbe(key)
+
+		vals, _, done := st.PrefixPage(key, "", 0)
+		ta.Equal([]interface{}{}, vals)
+		ta.True(done)
+
+		ta.Equal([]interface{}{}, st.SubtreeValues(key))
+		ta.Equal(0, st.PrefixCount(key))
+		ta.Equal(0, st.RangeCount(key, key+"x"))
+		ta.Equal(0, st.CountRange(key, ""))
+
+		k, _ := st.NewIter(key, true, true)()
+		ta.Nil(k)
+
+		st.ScanFrom(key, true, true, func(k, v []byte) bool {
+			ta.Fail("no key")
+			return true
+		})
+	}
+
+	st := &SlimTrie{}
+
+	ta.Nil(st.Children(0))
+
+	first, last := st.LeafOrdinalRange(0)
+	ta.Equal(int32(-1), first)
+	ta.Equal(int32(-1), last)
+
+	ta.True(st.IsPrefixFree())
+	ta.Equal(0, st.Len())
+
+	ta.NoError(st.IterKV(func(key string, val interface{}) bool {
+		ta.Fail("no key")
+		return true
+	}))
+
+	m, err := st.ToMap()
+	ta.NoError(err)
+	ta.Equal(map[string]interface{}{}, m)
+
+	st.IterNonDefault(func(interface{}) bool { return false }, func(ith int32, val interface{}) bool {
+		ta.Fail("no value")
+		return true
+	})
+
+	ta.Equal(map[int]int{}, st.LeafSizeHistogram())
+	ta.Equal("", st.String())
+}